@@ -0,0 +1,96 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SlurmDeploymentReference points at a SlurmDeployment
+type SlurmDeploymentReference struct {
+	Name string `json:"name"`
+	// Namespace of the SlurmDeployment, defaults to the namespace of the referring object. A SlurmDeployment in
+	// another namespace has to list the namespace of the referring object in allowedReferenceNamespaces.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// SlurmAssociationLimitsSpec holds the association limits applied through sacctmgr
+type SlurmAssociationLimitsSpec struct {
+	MaxJobs       *int32 `json:"maxJobs,omitempty"`
+	MaxSubmitJobs *int32 `json:"maxSubmitJobs,omitempty"`
+	// MaxWall in slurm time format, e.g. "2-00:00:00"
+	MaxWall string `json:"maxWall,omitempty"`
+	// MaxTRES per job, e.g. "cpu=64,gres/gpu=4"
+	MaxTRES string `json:"maxTRES,omitempty"`
+	// GrpTRES for the whole association, e.g. "cpu=512"
+	GrpTRES string `json:"grpTRES,omitempty"`
+}
+
+// SlurmAccountSpec defines the desired state of SlurmAccount.
+type SlurmAccountSpec struct {
+	DeploymentRef SlurmDeploymentReference `json:"deploymentRef"`
+	// AccountName defaults to metadata.name
+	AccountName  string `json:"accountName,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Organization string `json:"organization,omitempty"`
+	// +kubebuilder:default="root"
+	ParentAccount string `json:"parentAccount,omitempty"`
+	// +kubebuilder:default=1
+	Fairshare  int32                      `json:"fairshare,omitempty"`
+	Limits     SlurmAssociationLimitsSpec `json:"limits,omitempty"`
+	DefaultQOS string                     `json:"defaultQOS,omitempty"`
+	QOS        []string                   `json:"qos,omitempty"`
+}
+
+// SlurmAccountStatus defines the observed state of SlurmAccount.
+type SlurmAccountStatus struct {
+	Phase              string       `json:"phase,omitempty"`
+	Message            string       `json:"message,omitempty"`
+	Drift              []string     `json:"drift,omitempty"`
+	ObservedGeneration int64        `json:"observedGeneration,omitempty"`
+	LastSyncTime       *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=sacct
+// +kubebuilder:printcolumn:name="Deployment",type="string",JSONPath=".spec.deploymentRef.name",description="Target SlurmDeployment"
+// +kubebuilder:printcolumn:name="Parent",type="string",JSONPath=".spec.parentAccount",description="Parent account"
+// +kubebuilder:printcolumn:name="Fairshare",type="integer",JSONPath=".spec.fairshare",description="Fairshare"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Sync phase"
+
+// SlurmAccount is the Schema for the slurmaccounts API.
+type SlurmAccount struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SlurmAccountSpec   `json:"spec,omitempty"`
+	Status SlurmAccountStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SlurmAccountList contains a list of SlurmAccount.
+type SlurmAccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SlurmAccount `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SlurmAccount{}, &SlurmAccountList{})
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupTargetSpec is where backups are written to and restored from, exactly one of PVC or S3 must be set.
+// Referenced claims and secrets live in the chart namespace of the SlurmDeployment.
+type BackupTargetSpec struct {
+	PVC *BackupPVCTargetSpec `json:"pvc,omitempty"`
+	S3  *BackupS3TargetSpec  `json:"s3,omitempty"`
+}
+
+type BackupPVCTargetSpec struct {
+	ClaimName string `json:"claimName"`
+	// SubPath inside the claim, defaults to the backup name
+	SubPath string `json:"subPath,omitempty"`
+}
+
+// BackupS3TargetSpec points at an S3 compatible bucket such as MinIO
+type BackupS3TargetSpec struct {
+	// Endpoint e.g. "http://minio.minio.svc:9000"
+	Endpoint string `json:"endpoint"`
+	Bucket   string `json:"bucket"`
+	// Prefix inside the bucket, defaults to the backup name
+	Prefix string `json:"prefix,omitempty"`
+	// CredentialsSecret holds the keys "accessKey" and "secretKey"
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// BackupImagesSpec are the images used by backup and restore jobs
+type BackupImagesSpec struct {
+	// Database has mysqldump, mysql, tar and gzip, defaults to docker.io/bitnami/mariadb:11.4
+	Database string `json:"database,omitempty"`
+	// Upload has the MinIO client, defaults to docker.io/minio/mc:latest
+	Upload string `json:"upload,omitempty"`
+}
+
+// SlurmBackupSpec defines the desired state of SlurmBackup.
+type SlurmBackupSpec struct {
+	DeploymentRef SlurmDeploymentReference `json:"deploymentRef"`
+	// Schedule in cron format, e.g. "0 3 * * *". Without a schedule a single backup is taken.
+	Schedule string `json:"schedule,omitempty"`
+	// Retention is the number of backups kept in the target
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=7
+	Retention int32            `json:"retention,omitempty"`
+	Target    BackupTargetSpec `json:"target"`
+	Images    BackupImagesSpec `json:"images,omitempty"`
+}
+
+const (
+	BackupPhasePending   = "Pending"
+	BackupPhaseRunning   = "Running"
+	BackupPhaseSucceeded = "Succeeded"
+	BackupPhaseFailed    = "Failed"
+)
+
+// BackupRecord is a single backup run
+type BackupRecord struct {
+	// ID is the timestamp of the run and the directory it is stored under, e.g. "20250102-030000"
+	ID             string       `json:"id"`
+	JobName        string       `json:"jobName"`
+	Phase          string       `json:"phase"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// ClusterName of the backed up cluster, slurmctld only accepts the restored state under the same ClusterName
+	ClusterName string `json:"clusterName,omitempty"`
+}
+
+// SlurmBackupStatus defines the observed state of SlurmBackup.
+type SlurmBackupStatus struct {
+	Phase            string       `json:"phase,omitempty"`
+	Message          string       `json:"message,omitempty"`
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// LastSuccessfulBackup is the ID of the newest backup that completed
+	LastSuccessfulBackup string `json:"lastSuccessfulBackup,omitempty"`
+	// Backups lists the runs kept by the retention, newest last
+	Backups []BackupRecord `json:"backups,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=sbak
+// +kubebuilder:printcolumn:name="Deployment",type="string",JSONPath=".spec.deploymentRef.name",description="Target SlurmDeployment"
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule",description="Cron schedule"
+// +kubebuilder:printcolumn:name="Last Backup",type="string",JSONPath=".status.lastSuccessfulBackup",description="Newest completed backup"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Phase of the newest run"
+
+// SlurmBackup is the Schema for the slurmbackups API.
+type SlurmBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SlurmBackupSpec   `json:"spec,omitempty"`
+	Status SlurmBackupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SlurmBackupList contains a list of SlurmBackup.
+type SlurmBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SlurmBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SlurmBackup{}, &SlurmBackupList{})
+}
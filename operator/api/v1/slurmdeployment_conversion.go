@@ -0,0 +1,260 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	slurmv2 "github.com/AaronYang0628/slurm-on-k8s/api/v2"
+)
+
+// SlurmdCommonLabelsAnnotation keeps the commonLabels lists of the slurmd groups on a v2 object when they cannot be
+// rebuilt from the v2 maps, e.g. because of their order or of duplicate keys, so that v1 clients read them back as
+// they were written
+const SlurmdCommonLabelsAnnotation = "slurm.ay.dev/v1-slurmd-common-labels"
+
+// SlurmctldExtraVolumesAnnotation keeps the v1 slurmctld extraVolumes on a v2 object when they do not convert to
+// volumes and back unchanged, e.g. because a volume source is not valid JSON
+const SlurmctldExtraVolumesAnnotation = "slurm.ay.dev/v1-slurmctld-extra-volumes"
+
+type slurmdCommonLabels struct {
+	SlurmdCPU []string `json:"slurmdCPU,omitempty"`
+	SlurmdGPU []string `json:"slurmdGPU,omitempty"`
+}
+
+// ConvertTo converts this SlurmDeployment to the v2 hub version. Apart from the commonLabels of the slurmd groups,
+// a list of "key=value" entries in v1 and a map in v2, and the slurmctld extraVolumes, string maps in v1 and
+// volumes in v2, both versions serialize the same.
+func (src *SlurmDeployment) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*slurmv2.SlurmDeployment)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	spec := src.Spec.DeepCopy()
+	spec.Values.SlurmdCPU.CommonLabels = nil
+	spec.Values.SlurmdGPU.CommonLabels = nil
+	spec.Values.Slurmctld.ExtraVolumes = nil
+	dst.Spec = slurmv2.SlurmDeploymentSpec{}
+	if err := convertJSON(spec, &dst.Spec); err != nil {
+		return fmt.Errorf("failed to convert the spec of SlurmDeployment %s to v2: %v", src.Name, err)
+	}
+	dst.Status = slurmv2.SlurmDeploymentStatus{}
+	if err := convertJSON(&src.Status, &dst.Status); err != nil {
+		return fmt.Errorf("failed to convert the status of SlurmDeployment %s to v2: %v", src.Name, err)
+	}
+
+	kept := slurmdCommonLabels{}
+	dst.Spec.Values.SlurmdCPU.CommonLabels = commonLabelsMap(src.Spec.Values.SlurmdCPU.CommonLabels)
+	if !slices.Equal(commonLabelsList(dst.Spec.Values.SlurmdCPU.CommonLabels, nil), src.Spec.Values.SlurmdCPU.CommonLabels) {
+		kept.SlurmdCPU = src.Spec.Values.SlurmdCPU.CommonLabels
+	}
+	dst.Spec.Values.SlurmdGPU.CommonLabels = commonLabelsMap(src.Spec.Values.SlurmdGPU.CommonLabels)
+	if !slices.Equal(commonLabelsList(dst.Spec.Values.SlurmdGPU.CommonLabels, nil), src.Spec.Values.SlurmdGPU.CommonLabels) {
+		kept.SlurmdGPU = src.Spec.Values.SlurmdGPU.CommonLabels
+	}
+	delete(dst.Annotations, SlurmdCommonLabelsAnnotation)
+	if kept.SlurmdCPU != nil || kept.SlurmdGPU != nil {
+		data, err := json.Marshal(kept)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[SlurmdCommonLabelsAnnotation] = string(data)
+	}
+
+	volumes, converted := ExtraVolumes(src.Spec.Values.Slurmctld.ExtraVolumes)
+	dst.Spec.Values.Slurmctld.ExtraVolumes = volumes
+	delete(dst.Annotations, SlurmctldExtraVolumesAnnotation)
+	if rendered, err := extraVolumeMaps(volumes); err != nil || !converted ||
+		!slices.EqualFunc(rendered, src.Spec.Values.Slurmctld.ExtraVolumes, maps.Equal) {
+		data, err := json.Marshal(src.Spec.Values.Slurmctld.ExtraVolumes)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[SlurmctldExtraVolumesAnnotation] = string(data)
+	}
+	return nil
+}
+
+// ConvertFrom converts the v2 hub version to this SlurmDeployment
+func (dst *SlurmDeployment) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*slurmv2.SlurmDeployment)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	spec := src.Spec.DeepCopy()
+	spec.Values.SlurmdCPU.CommonLabels = nil
+	spec.Values.SlurmdGPU.CommonLabels = nil
+	spec.Values.Slurmctld.ExtraVolumes = nil
+	dst.Spec = SlurmDeploymentSpec{}
+	if err := convertJSON(spec, &dst.Spec); err != nil {
+		return fmt.Errorf("failed to convert the spec of SlurmDeployment %s from v2: %v", src.Name, err)
+	}
+	dst.Status = SlurmDeploymentStatus{}
+	if err := convertJSON(&src.Status, &dst.Status); err != nil {
+		return fmt.Errorf("failed to convert the status of SlurmDeployment %s from v2: %v", src.Name, err)
+	}
+
+	// a kept list that no longer matches the labels was written before the labels were changed through v2
+	kept := slurmdCommonLabels{}
+	if value, found := dst.Annotations[SlurmdCommonLabelsAnnotation]; found {
+		if err := json.Unmarshal([]byte(value), &kept); err != nil {
+			kept = slurmdCommonLabels{}
+		}
+		delete(dst.Annotations, SlurmdCommonLabelsAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+	dst.Spec.Values.SlurmdCPU.CommonLabels = commonLabelsList(src.Spec.Values.SlurmdCPU.CommonLabels, kept.SlurmdCPU)
+	dst.Spec.Values.SlurmdGPU.CommonLabels = commonLabelsList(src.Spec.Values.SlurmdGPU.CommonLabels, kept.SlurmdGPU)
+
+	// like the commonLabels, kept extraVolumes are only used while they still match the v2 volumes
+	var keptVolumes []map[string]string
+	if value, found := dst.Annotations[SlurmctldExtraVolumesAnnotation]; found {
+		if err := json.Unmarshal([]byte(value), &keptVolumes); err != nil {
+			keptVolumes = nil
+		}
+		delete(dst.Annotations, SlurmctldExtraVolumesAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+	if volumes, _ := ExtraVolumes(keptVolumes); keptVolumes != nil && equality.Semantic.DeepEqual(volumes, src.Spec.Values.Slurmctld.ExtraVolumes) {
+		dst.Spec.Values.Slurmctld.ExtraVolumes = keptVolumes
+		return nil
+	}
+	rendered, err := extraVolumeMaps(src.Spec.Values.Slurmctld.ExtraVolumes)
+	if err != nil {
+		return fmt.Errorf("failed to convert the slurmctld extraVolumes of SlurmDeployment %s from v2: %v", src.Name, err)
+	}
+	dst.Spec.Values.Slurmctld.ExtraVolumes = rendered
+	return nil
+}
+
+// convertJSON copies a value to the other version of its type, both have to serialize the same
+func convertJSON(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// ExtraVolumes decodes v1 slurmctld extraVolumes into volumes, a value holding a JSON object or list is the
+// nested volume source, e.g. "emptyDir": "{}". Entries that are no valid volume are skipped and reported by ok.
+func ExtraVolumes(entries []map[string]string) (volumes []corev1.Volume, ok bool) {
+	ok = true
+	for _, entry := range entries {
+		volume := corev1.Volume{}
+		if err := convertJSON(ExtraVolumeValues(entry), &volume); err != nil || volume.Name == "" {
+			ok = false
+			continue
+		}
+		volumes = append(volumes, volume)
+	}
+	return volumes, ok
+}
+
+// ExtraVolumeValues decodes the JSON values of a v1 slurmctld extraVolume, the other values are kept as strings
+func ExtraVolumeValues(entry map[string]string) map[string]interface{} {
+	values := make(map[string]interface{}, len(entry))
+	for key, value := range entry {
+		values[key] = value
+		trimmed := strings.TrimSpace(value)
+		if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+			continue
+		}
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(trimmed), &decoded); err == nil {
+			values[key] = decoded
+		}
+	}
+	return values
+}
+
+// extraVolumeMaps renders volumes as v1 slurmctld extraVolumes, nested volume sources become JSON strings
+func extraVolumeMaps(volumes []corev1.Volume) ([]map[string]string, error) {
+	var entries []map[string]string
+	for i := range volumes {
+		values := map[string]interface{}{}
+		if err := convertJSON(&volumes[i], &values); err != nil {
+			return nil, err
+		}
+		entry := make(map[string]string, len(values))
+		for key, value := range values {
+			if text, isString := value.(string); isString {
+				entry[key] = text
+				continue
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			entry[key] = string(data)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// commonLabelsMap parses "key=value" entries, an entry without "=" is a label with an empty value and a repeated key
+// keeps its last value
+func commonLabelsMap(list []string) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(list))
+	for _, entry := range list {
+		key, value, _ := strings.Cut(entry, "=")
+		labels[key] = value
+	}
+	return labels
+}
+
+// commonLabelsList renders labels as sorted "key=value" entries, or returns the kept list while it parses to the
+// same labels
+func commonLabelsList(labels map[string]string, kept []string) []string {
+	if kept != nil && maps.Equal(commonLabelsMap(kept), labels) {
+		return kept
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	list := make([]string, 0, len(labels))
+	for key, value := range labels {
+		if value == "" {
+			list = append(list, key)
+			continue
+		}
+		list = append(list, key+"="+value)
+	}
+	sort.Strings(list)
+	return list
+}
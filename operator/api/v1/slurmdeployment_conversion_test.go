@@ -0,0 +1,197 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	slurmv2 "github.com/AaronYang0628/slurm-on-k8s/api/v2"
+)
+
+var _ = Describe("SlurmDeployment conversion", func() {
+	// readSample drops the TypeMeta of the sample, conversions leave it to the scheme
+	readSample := func(name string, object interface{ SetGroupVersionKind(schema.GroupVersionKind) }) {
+		data, err := os.ReadFile(filepath.Join("..", "..", "config", "samples", name))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(yaml.UnmarshalStrict(data, object)).To(Succeed())
+		object.SetGroupVersionKind(schema.GroupVersionKind{})
+	}
+
+	toJSON := func(object interface{}) string {
+		data, err := json.Marshal(object)
+		Expect(err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	roundTripV1 := func(original *SlurmDeployment) (*slurmv2.SlurmDeployment, *SlurmDeployment) {
+		hub := &slurmv2.SlurmDeployment{}
+		Expect(original.DeepCopy().ConvertTo(hub)).To(Succeed())
+		converted := &SlurmDeployment{}
+		Expect(converted.ConvertFrom(hub.DeepCopy())).To(Succeed())
+		return hub, converted
+	}
+
+	newSlurmDeployment := func() *SlurmDeployment {
+		replicas := int32(2)
+		release := &SlurmDeployment{}
+		readSample("slurm_v1_slurmdeployment.yaml", release)
+		release.Annotations = map[string]string{"team": "hpc"}
+		release.Spec.Values.Slurmctld.Resources = &ResourceSpec{
+			Requests: &ResourceRequestSpec{CPU: "1", Memory: "2Gi", EphemeralStorage: "4Gi"},
+		}
+		release.Spec.Values.Slurmctld.Probes = ProbesSpec{Liveness: ProbeSpec{Enabled: true, PeriodSeconds: 20}}
+		release.Spec.Values.Slurmctld.ExtraVolumes = []map[string]string{{"name": "spool", "emptyDir": "{}"}}
+		release.Spec.Values.SlurmdCPU.CommonLabels = []string{"rack=a1", "tier=compute"}
+		release.Spec.Values.SlurmdCPU.Features = []string{"avx512"}
+		release.Spec.Values.SlurmdCPU.Weight = 10
+		release.Spec.Values.SlurmdCPU.Resources.Extended = []SlurmdExtendedResourceSpec{{
+			Name:     "rdma/hca",
+			Quantity: resource.MustParse("1"),
+			Gres:     "rdma",
+		}}
+		release.Spec.Values.SlurmLogin.WebTerminal.Ingress = &WebTerminalIngressSpec{Host: "slurm.example.com"}
+		release.Spec.Values.Slurmdbd.PodTemplate = &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Tolerations: []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+		}}
+		release.Status = SlurmDeploymentStatus{
+			ClusterStatus:   "Running",
+			CurrentRevision: 3,
+			SlurmVersion:    "25.05",
+			AppliedImages:   map[string]ImageSpec{"slurmctld": release.Spec.Values.Slurmctld.Image},
+			Hibernation:     &HibernationStatus{Phase: HibernationPhaseRunning, Replicas: map[string]int32{"login": replicas}},
+			Conditions: []metav1.Condition{{
+				Type:               ConditionPreflightPassed,
+				Status:             metav1.ConditionTrue,
+				Reason:             "Passed",
+				LastTransitionTime: metav1.Now().Rfc3339Copy(),
+			}},
+		}
+		return release
+	}
+
+	It("should keep a v1 SlurmDeployment through a round trip over v2", func() {
+		original := newSlurmDeployment()
+		hub, converted := roundTripV1(original)
+
+		Expect(hub.Spec.Values.SlurmdCPU.CommonLabels).To(Equal(map[string]string{"rack": "a1", "tier": "compute"}))
+		Expect(hub.Spec.Values.Slurmctld.Probes.Liveness.PeriodSeconds).To(Equal(int32(20)))
+		Expect(hub.Spec.Values.Login.ReplicaCount).To(Equal(original.Spec.Values.SlurmLogin.ReplicaCount))
+		Expect(hub.Spec.Values.SlurmdCPU.Resources.Requests.Memory).To(Equal("2Gi"))
+		Expect(hub.Annotations).NotTo(HaveKey(SlurmdCommonLabelsAnnotation))
+		Expect(hub.Annotations).NotTo(HaveKey(SlurmctldExtraVolumesAnnotation))
+		Expect(hub.Spec.Values.Slurmctld.ExtraVolumes).To(Equal([]corev1.Volume{{
+			Name:         "spool",
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		}}))
+		Expect(hub.Status.Conditions).To(HaveLen(1))
+
+		Expect(toJSON(converted)).To(MatchJSON(toJSON(original)))
+	})
+
+	It("should keep the v1 sample through a round trip over v2", func() {
+		original := &SlurmDeployment{}
+		readSample("slurm_v1_slurmdeployment.yaml", original)
+		_, converted := roundTripV1(original)
+		Expect(toJSON(converted)).To(MatchJSON(toJSON(original)))
+	})
+
+	It("should keep slurmd commonLabels that cannot be rebuilt from the v2 map", func() {
+		original := newSlurmDeployment()
+		original.Spec.Values.SlurmdCPU.CommonLabels = []string{"tier=compute", "rack=a1", "tier=batch"}
+		original.Spec.Values.SlurmdGPU.CommonLabels = []string{"gpu", "accelerator="}
+		hub, converted := roundTripV1(original)
+
+		Expect(hub.Spec.Values.SlurmdCPU.CommonLabels).To(Equal(map[string]string{"rack": "a1", "tier": "batch"}))
+		Expect(hub.Spec.Values.SlurmdGPU.CommonLabels).To(Equal(map[string]string{"gpu": "", "accelerator": ""}))
+		Expect(hub.Annotations).To(HaveKey(SlurmdCommonLabelsAnnotation))
+		Expect(hub.Annotations).To(HaveKeyWithValue("team", "hpc"))
+
+		Expect(converted.Annotations).NotTo(HaveKey(SlurmdCommonLabelsAnnotation))
+		Expect(toJSON(converted)).To(MatchJSON(toJSON(original)))
+	})
+
+	It("should render slurmd commonLabels changed through v2 instead of the kept list", func() {
+		original := newSlurmDeployment()
+		original.Spec.Values.SlurmdCPU.CommonLabels = []string{"tier=compute", "rack=a1"}
+		hub := &slurmv2.SlurmDeployment{}
+		Expect(original.ConvertTo(hub)).To(Succeed())
+		Expect(hub.Annotations).To(HaveKey(SlurmdCommonLabelsAnnotation))
+
+		hub.Spec.Values.SlurmdCPU.CommonLabels["zone"] = "b"
+		converted := &SlurmDeployment{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted.Spec.Values.SlurmdCPU.CommonLabels).To(Equal([]string{"rack=a1", "tier=compute", "zone=b"}))
+		Expect(converted.Annotations).To(Equal(map[string]string{"team": "hpc"}))
+	})
+
+	It("should keep slurmctld extraVolumes stored before they were converted to volumes", func() {
+		original := newSlurmDeployment()
+		original.Spec.Values.Slurmctld.ExtraVolumes = []map[string]string{
+			{"name": "spool", "emptyDir": `{ "medium": "Memory" }`},
+			{"name": "data", "hostPath": "/data"},
+		}
+		hub, converted := roundTripV1(original)
+
+		Expect(hub.Spec.Values.Slurmctld.ExtraVolumes).To(Equal([]corev1.Volume{{
+			Name:         "spool",
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+		}}))
+		Expect(hub.Annotations).To(HaveKey(SlurmctldExtraVolumesAnnotation))
+		Expect(converted.Annotations).NotTo(HaveKey(SlurmctldExtraVolumesAnnotation))
+		Expect(toJSON(converted)).To(MatchJSON(toJSON(original)))
+
+		// a volume added through v2 replaces the kept list
+		hub.Spec.Values.Slurmctld.ExtraVolumes = append(hub.Spec.Values.Slurmctld.ExtraVolumes, corev1.Volume{
+			Name:         "conf",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "extra"}}},
+		})
+		converted = &SlurmDeployment{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted.Spec.Values.Slurmctld.ExtraVolumes).To(Equal([]map[string]string{
+			{"name": "spool", "emptyDir": `{"medium":"Memory"}`},
+			{"name": "conf", "configMap": `{"name":"extra"}`},
+		}))
+	})
+
+	It("should keep a v2 SlurmDeployment through a round trip over v1", func() {
+		original := &slurmv2.SlurmDeployment{}
+		readSample("slurm_v2_slurmdeployment.yaml", original)
+		original.Spec.Values.Munged.Probes.Startup.FailureThreshold = 30
+		original.Spec.Values.Slurmdbd.NodeSelector = map[string]string{"disk": "ssd"}
+		original.Status.WebTerminalURL = "https://slurm.example.com/terminal/default/sample-v2/"
+
+		spoke := &SlurmDeployment{}
+		Expect(spoke.ConvertFrom(original.DeepCopy())).To(Succeed())
+		Expect(spoke.Spec.Values.SlurmdCPU.CommonLabels).To(Equal([]string{"slurm.ay.dev/partition=compute"}))
+		Expect(spoke.Spec.Values.SlurmLogin.Service.Type).To(Equal(corev1.ServiceTypeClusterIP))
+		Expect(spoke.Spec.Values.Munged.Probes.Startup.FailureThreshold).To(Equal(int32(30)))
+
+		converted := &slurmv2.SlurmDeployment{}
+		Expect(spoke.ConvertTo(converted)).To(Succeed())
+		Expect(toJSON(converted)).To(MatchJSON(toJSON(original)))
+	})
+})
@@ -19,6 +19,7 @@ package v1
 import (
 	"encoding/json"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -26,10 +27,27 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 type ChartSpec struct {
-	Name       string `json:"name"`
-	Repository string `json:"repository"`
-	Version    string `json:"version"`
-	Namespace  string `json:"namespace,omitempty"`
+	Name string `json:"name"`
+	// Repository and Version fall back to the chart of the operator defaults when empty
+	Repository    string            `json:"repository,omitempty"`
+	Version       string            `json:"version,omitempty"`
+	Namespace     string            `json:"namespace,omitempty"`
+	UpgradePolicy UpgradePolicySpec `json:"upgradePolicy,omitempty"`
+}
+
+// UpgradePolicySpec controls how helm installs and upgrades the chart
+type UpgradePolicySpec struct {
+	// Atomic rolls the release back to its last successful revision when an upgrade fails, implies Wait
+	Atomic bool `json:"atomic,omitempty"`
+	// Wait blocks until all workloads of the release are ready
+	Wait bool `json:"wait,omitempty"`
+	// Timeout of a single helm action, defaults to 5m
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// MaxHistory limits the number of release revisions kept, defaults to 10
+	// +kubebuilder:validation:Minimum=0
+	MaxHistory int32 `json:"maxHistory,omitempty"`
+	// CleanupOnFail deletes resources created by a failed upgrade
+	CleanupOnFail bool `json:"cleanupOnFail,omitempty"`
 }
 
 type MariaDBSpec struct {
@@ -104,6 +122,46 @@ type NodeAffinityPreset struct {
 
 type PersistenceSpec struct {
 	Shared PersistenceSharedSpec `json:"shared"`
+	// Volumes are mounted on the login, slurmd and slurmctld pods, e.g. home, scratch, datasets or software
+	// +listType=map
+	// +listMapKey=name
+	Volumes []SharedVolumeSpec `json:"volumes,omitempty"`
+}
+
+// SharedVolumeSpec is a volume shared by the login, slurmd and slurmctld pods. The operator creates the PVC
+// <release>-<chart>-shared-<name> unless an existing claim is given, and keeps it when the volume or the
+// SlurmDeployment is removed.
+type SharedVolumeSpec struct {
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=40
+	Name string `json:"name"`
+	// ExistingClaim mounts a PVC of the chart namespace instead of creating one
+	ExistingClaim string `json:"existingClaim,omitempty"`
+	// +kubebuilder:default={"ReadWriteMany"}
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// StorageClass of the created PVC, the default storage class when unset
+	StorageClass string `json:"storageClass,omitempty"`
+	// Size of the created PVC, it can grow when the storage class allows volume expansion
+	// +kubebuilder:default="8Gi"
+	Size string `json:"size,omitempty"`
+	// MountPath is where every component mounts the volume unless MountPaths overrides it
+	// +kubebuilder:validation:Pattern=`^/`
+	MountPath string `json:"mountPath"`
+	// +kubebuilder:default={}
+	MountPaths SharedVolumeMountPaths `json:"mountPaths,omitempty"`
+	ReadOnly   bool                   `json:"readOnly,omitempty"`
+	// SubPath mounts a directory of the volume instead of its root
+	SubPath string `json:"subPath,omitempty"`
+}
+
+// SharedVolumeMountPaths overrides the mount path of a shared volume per component
+type SharedVolumeMountPaths struct {
+	// +kubebuilder:validation:Pattern=`^/`
+	Slurmctld string `json:"slurmctld,omitempty"`
+	// +kubebuilder:validation:Pattern=`^/`
+	Slurmd string `json:"slurmd,omitempty"`
+	// +kubebuilder:validation:Pattern=`^/`
+	Login string `json:"login,omitempty"`
 }
 
 type PersistenceSharedSpec struct {
@@ -120,15 +178,14 @@ type PersistenceSharedSpec struct {
 	Size string `json:"size"`
 }
 
+// ImageSpec is the image of a component. Registry, repository and tag left empty are taken from the images of the
+// operator defaults, or else default to localhost, data-and-computing and latest.
 type ImageSpec struct {
-	// +kubebuilder:default="localhost"
-	Registry string `json:"registry"`
-	// +kubebuilder:default="data-and-computing"
-	Repository string `json:"repository"`
+	Registry   string `json:"registry,omitempty"`
+	Repository string `json:"repository,omitempty"`
 	// +kubebuilder:validation:Type=string
 	// +kubebuilder:validation:Format="string-or-int"
-	// +kubebuilder:default="latest"
-	Tag string `json:"tag"`
+	Tag string `json:"tag,omitempty"`
 	// +kubebuilder:default="IfNotPresent"
 	PullPolicy  string   `json:"pullPolicy,omitempty"`
 	PullSecrets []string `json:"pullSecrets,omitempty"`
@@ -148,6 +205,39 @@ type ExtraVolumeMountsSpec struct {
 	MountPath string `json:"mountPath"`
 }
 
+// ProbesSpec configures the health probes of a component container, the checks themselves are chosen per component
+type ProbesSpec struct {
+	// +kubebuilder:default={}
+	Liveness ProbeSpec `json:"liveness,omitempty"`
+	// +kubebuilder:default={}
+	Readiness ProbeSpec `json:"readiness,omitempty"`
+	// +kubebuilder:default={}
+	Startup ProbeSpec `json:"startup,omitempty"`
+}
+
+// ProbeSpec holds the thresholds of one probe
+type ProbeSpec struct {
+	// Enabled adds the probe to the container, probes are opt-in
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled"`
+	// +kubebuilder:default=30
+	// +kubebuilder:validation:Minimum=0
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+	// SuccessThreshold only applies to the readiness probe, liveness and startup probes always use 1
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	SuccessThreshold int32 `json:"successThreshold,omitempty"`
+	// +kubebuilder:default=6
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
 type MungedSpec struct {
 	// +kubebuilder:default="munged"
 	Name              string                  `json:"name"`
@@ -156,6 +246,15 @@ type MungedSpec struct {
 	DiagnosticMode    DiagnosticModeSpec      `json:"diagnosticMode,omitempty"`
 	ExtraVolumes      []corev1.Volume         `json:"extraVolumes,omitempty"`
 	ExtraVolumeMounts []ExtraVolumeMountsSpec `json:"extraVolumeMounts,omitempty"`
+	// Probes of the munged container
+	// +kubebuilder:default={}
+	Probes ProbesSpec `json:"probes,omitempty"`
+	// PodTemplate is a partial pod template strategic-merged onto the rendered pod of every component running munged, e.g. tolerations,
+	// affinity, priorityClassName, securityContext or extra sidecar containers
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodTemplate *corev1.PodTemplateSpec `json:"podTemplate,omitempty"`
 }
 
 type SlurmctldSpec struct {
@@ -171,6 +270,15 @@ type SlurmctldSpec struct {
 	DiagnosticMode     DiagnosticModeSpec      `json:"diagnosticMode,omitempty"`
 	ExtraVolumes       []map[string]string     `json:"extraVolumes,omitempty"`
 	ExtraVolumeMounts  []ExtraVolumeMountsSpec `json:"extraVolumeMounts,omitempty"`
+	// Probes of the slurmctld container
+	// +kubebuilder:default={}
+	Probes ProbesSpec `json:"probes,omitempty"`
+	// PodTemplate is a partial pod template strategic-merged onto the rendered slurmctld workload, e.g. tolerations,
+	// affinity, priorityClassName, securityContext or extra sidecar containers
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodTemplate *corev1.PodTemplateSpec `json:"podTemplate,omitempty"`
 }
 
 type SlurmdCPUSpec struct {
@@ -186,6 +294,23 @@ type SlurmdCPUSpec struct {
 	DiagnosticMode     DiagnosticModeSpec      `json:"diagnosticMode,omitempty"`
 	ExtraVolumes       []corev1.Volume         `json:"extraVolumes,omitempty"`
 	ExtraVolumeMounts  []ExtraVolumeMountsSpec `json:"extraVolumeMounts,omitempty"`
+	// Features are added to every node of the group, jobs pick them with --constraint
+	Features []string `json:"features,omitempty"`
+	// FeaturesFromNodeLabels are labels of the kubernetes node a slurmd pod runs on, e.g. "node.kubernetes.io/instance-type",
+	// whose values are added to the features of its slurm node
+	FeaturesFromNodeLabels []string `json:"featuresFromNodeLabels,omitempty"`
+	// Weight of the nodes, nodes with lower weight are allocated first
+	// +kubebuilder:validation:Minimum=0
+	Weight int32 `json:"weight,omitempty"`
+	// Probes of the slurmd container
+	// +kubebuilder:default={}
+	Probes ProbesSpec `json:"probes,omitempty"`
+	// PodTemplate is a partial pod template strategic-merged onto the rendered slurmd cpu workload, e.g. tolerations,
+	// affinity, priorityClassName, securityContext or extra sidecar containers
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodTemplate *corev1.PodTemplateSpec `json:"podTemplate,omitempty"`
 }
 
 type SlurmdGPUSpec struct {
@@ -201,11 +326,49 @@ type SlurmdGPUSpec struct {
 	DiagnosticMode     DiagnosticModeSpec      `json:"diagnosticMode,omitempty"`
 	ExtraVolumes       []corev1.Volume         `json:"extraVolumes,omitempty"`
 	ExtraVolumeMounts  []ExtraVolumeMountsSpec `json:"extraVolumeMounts,omitempty"`
+	// Features are added to every node of the group, jobs pick them with --constraint
+	Features []string `json:"features,omitempty"`
+	// FeaturesFromNodeLabels are labels of the kubernetes node a slurmd pod runs on, e.g. "node.kubernetes.io/instance-type",
+	// whose values are added to the features of its slurm node
+	FeaturesFromNodeLabels []string `json:"featuresFromNodeLabels,omitempty"`
+	// Weight of the nodes, nodes with lower weight are allocated first
+	// +kubebuilder:validation:Minimum=0
+	Weight int32 `json:"weight,omitempty"`
+	// Probes of the slurmd container
+	// +kubebuilder:default={}
+	Probes ProbesSpec `json:"probes,omitempty"`
+	// PodTemplate is a partial pod template strategic-merged onto the rendered slurmd gpu workload, e.g. tolerations,
+	// affinity, priorityClassName, securityContext or extra sidecar containers
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodTemplate *corev1.PodTemplateSpec `json:"podTemplate,omitempty"`
 }
 
 type SlurmdResourceSpec struct {
 	Requests *SlurmdResourceRequestSpec `json:"requests,omitempty"`
 	Limits   *SlurmdResourceLimitSpec   `json:"limits,omitempty"`
+	// Extended resources and hugepages requested by every slurmd pod of the group
+	Extended []SlurmdExtendedResourceSpec `json:"extended,omitempty"`
+}
+
+// SlurmdExtendedResourceSpec is a kubernetes extended resource such as "rdma/hca", or a hugepages size such as
+// "hugepages-2Mi", optionally exposed to jobs as a Slurm GRES or node feature. The quantity of hugepages is in bytes,
+// so a GRES on hugepages needs its count.
+// +kubebuilder:validation:XValidation:rule="!has(self.gres) || !self.name.startsWith('hugepages-') || (has(self.gresCount) && self.gresCount > 0)",message="gres on hugepages requires gresCount"
+type SlurmdExtendedResourceSpec struct {
+	// Name of the kubernetes resource
+	Name string `json:"name"`
+	// Quantity is both requested and limited, extended resources cannot be overcommitted
+	Quantity resource.Quantity `json:"quantity"`
+	// Gres is the generic resource the quantity is published as, e.g. "rdma" or "gpu:a100"
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_]+(:[a-zA-Z0-9_.-]+)?$`
+	Gres string `json:"gres,omitempty"`
+	// GresCount overrides the GRES count, defaults to the quantity
+	// +kubebuilder:validation:Minimum=0
+	GresCount int64 `json:"gresCount,omitempty"`
+	// Feature is added to the node features, e.g. "ib"
+	Feature string `json:"feature,omitempty"`
 }
 
 type ResourceSpec struct {
@@ -267,6 +430,15 @@ type SlurmdbdSpec struct {
 	NodeSelector       map[string]string       `json:"nodeSelector,omitempty"`
 	ExtraVolumes       []corev1.Volume         `json:"extraVolumes,omitempty"`
 	ExtraVolumeMounts  []ExtraVolumeMountsSpec `json:"extraVolumeMounts,omitempty"`
+	// Probes of the slurmdbd container
+	// +kubebuilder:default={}
+	Probes ProbesSpec `json:"probes,omitempty"`
+	// PodTemplate is a partial pod template strategic-merged onto the rendered slurmdbd workload, e.g. tolerations,
+	// affinity, priorityClassName, securityContext or extra sidecar containers
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodTemplate *corev1.PodTemplateSpec `json:"podTemplate,omitempty"`
 }
 
 type SlurmLogindSpec struct {
@@ -280,6 +452,102 @@ type SlurmLogindSpec struct {
 	NodeSelector       map[string]string       `json:"nodeSelector,omitempty"`
 	ExtraVolumes       []corev1.Volume         `json:"extraVolumes,omitempty"`
 	ExtraVolumeMounts  []ExtraVolumeMountsSpec `json:"extraVolumeMounts,omitempty"`
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	ReplicaCount int32 `json:"replicaCount"`
+	// Service exposes the SSH port of the login nodes
+	// +kubebuilder:default={}
+	Service LoginServiceSpec `json:"service,omitempty"`
+	// WebTerminal serves a browser terminal to the login nodes from the operator
+	WebTerminal WebTerminalSpec `json:"webTerminal,omitempty"`
+	// PodTemplate is a partial pod template strategic-merged onto the rendered login workload, e.g. tolerations,
+	// affinity, priorityClassName, securityContext or extra sidecar containers
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodTemplate *corev1.PodTemplateSpec `json:"podTemplate,omitempty"`
+}
+
+// LoginServiceSpec configures the Service in front of the SSH port of the login nodes
+type LoginServiceSpec struct {
+	// +kubebuilder:default=ClusterIP
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// Annotations of the Service, e.g. for MetalLB address pools
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// NodePort pins the node port of a NodePort or LoadBalancer Service, a free port is picked when unset
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	NodePort int32 `json:"nodePort,omitempty"`
+	// ExternalTrafficPolicy of a NodePort or LoadBalancer Service, Local keeps the client IP
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+	// SessionAffinity ClientIP sends every connection of a client to the same login node
+	// +kubebuilder:validation:Enum=None;ClientIP
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+	// SessionAffinityTimeoutSeconds of ClientIP session affinity, defaults to 3 hours
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=86400
+	SessionAffinityTimeoutSeconds int32 `json:"sessionAffinityTimeoutSeconds,omitempty"`
+}
+
+// WebTerminalSpec serves a browser terminal to the login nodes. The operator authenticates every session and execs
+// a login shell of the authenticated user in a login pod.
+// +kubebuilder:validation:XValidation:rule="!self.enabled || size(self.allowedGroups) > 0 || size(self.users) > 0",message="an enabled web terminal needs allowedGroups or users"
+type WebTerminalSpec struct {
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled"`
+	// +kubebuilder:default={}
+	Authentication WebTerminalAuthSpec `json:"authentication,omitempty"`
+	// AllowedGroups admits members of these groups as the login user named after them
+	// +kubebuilder:default={}
+	AllowedGroups []string `json:"allowedGroups,omitempty"`
+	// Users maps authenticated usernames to login users, they are admitted whatever their groups
+	// +kubebuilder:default={}
+	Users map[string]string `json:"users,omitempty"`
+	// Ingress exposes the terminal outside the cluster, otherwise it is only reachable through its Service
+	Ingress *WebTerminalIngressSpec `json:"ingress,omitempty"`
+}
+
+// WebTerminalAuthSpec authenticates the bearer token of a browser session
+type WebTerminalAuthSpec struct {
+	// Mode TokenReview checks the token against the API server, OIDC verifies an ID token of the issuer
+	// +kubebuilder:default=TokenReview
+	// +kubebuilder:validation:Enum=TokenReview;OIDC
+	Mode string `json:"mode,omitempty"`
+	// Audience is what TokenReview tokens must be issued for, e.g. kubectl create token --audience
+	// +kubebuilder:default=slurm.ay.dev/web-terminal
+	// +kubebuilder:validation:MinLength=1
+	Audience string `json:"audience,omitempty"`
+	// UsernamePrefix is stripped from the authenticated name to get the user on the login node, e.g. "oidc:" or
+	// "https://login.example.com#" for the subject of an OIDC provider
+	UsernamePrefix string `json:"usernamePrefix,omitempty"`
+	// OIDC is required with mode OIDC
+	OIDC *OIDCSpec `json:"oidc,omitempty"`
+}
+
+// OIDCSpec identifies the OpenID Connect provider issuing the ID tokens of the terminal users
+type OIDCSpec struct {
+	// IssuerURL serves the discovery document at /.well-known/openid-configuration
+	// +kubebuilder:validation:Pattern=`^https://`
+	IssuerURL string `json:"issuerURL"`
+	// ClientID is the audience the ID tokens are issued for
+	ClientID string `json:"clientID"`
+	// UsernameClaim names the user, a claim other than email is prefixed with the issuer URL and "#". The email
+	// claim is only accepted when email_verified is true.
+	// +kubebuilder:default=sub
+	UsernameClaim string `json:"usernameClaim,omitempty"`
+	// +kubebuilder:default=groups
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+}
+
+// WebTerminalIngressSpec routes a host to the web terminal Service
+type WebTerminalIngressSpec struct {
+	ClassName *string `json:"className,omitempty"`
+	Host      string  `json:"host"`
+	// TLSSecretName holds the certificate of the host, the terminal is served over plain HTTP when unset
+	TLSSecretName string            `json:"tlsSecretName,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
 }
 
 type ServiceAccountSpec struct {
@@ -303,9 +571,63 @@ type ServiceAccountRoleBindingSpec struct {
 }
 
 type SlurmConfigSpec struct {
-	Cgroup       CgroupSpec `json:"cgroup"`
-	SlurmConf    string     `json:"slurmConf"`
-	SlurmdbdConf string     `json:"slurmdbdConf"`
+	Cgroup       CgroupSpec     `json:"cgroup"`
+	SlurmConf    string         `json:"slurmConf"`
+	SlurmdbdConf string         `json:"slurmdbdConf"`
+	Scheduling   SchedulingSpec `json:"scheduling,omitempty"`
+	// ClusterName of slurm.conf, defaults to the SlurmDeployment name. Releases installed before it could be set keep
+	// "slurm-cluster" since slurmctld refuses to start on a changed ClusterName, which also makes a SlurmRestore
+	// require the ClusterName of the backed up cluster.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9][a-z0-9_-]*$`
+	ClusterName string         `json:"clusterName,omitempty"`
+	Accounting  AccountingSpec `json:"accounting,omitempty"`
+	Topology    TopologySpec   `json:"topology,omitempty"`
+}
+
+// TopologySpec generates topology.conf for the tree topology plugin from the labels of the nodes hosting slurmd pods.
+// Nodes are grouped into one switch per zone, and into one switch per rack inside a zone when RackLabel is set.
+type TopologySpec struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// ZoneLabel defaults to topology.kubernetes.io/zone
+	ZoneLabel string `json:"zoneLabel,omitempty"`
+	// RackLabel is the node label naming the rack, e.g. "topology.example.com/rack"
+	RackLabel string `json:"rackLabel,omitempty"`
+}
+
+// AccountingSpec selects the slurmdbd a cluster reports to. Clusters sharing a slurmdbd must use the same munge key.
+type AccountingSpec struct {
+	// SlurmdbdRef is a SlurmDeployment, in any namespace, whose slurmdbd and accounting database are used,
+	// this release then deploys neither slurmdbd nor MariaDB
+	SlurmdbdRef *SlurmDeploymentReference `json:"slurmdbdRef,omitempty"`
+	// StorageHost and StoragePort are rendered as AccountingStorageHost and AccountingStoragePort. They are filled in
+	// by the operator when SlurmdbdRef is set, or point at a slurmdbd running outside of the operator.
+	StorageHost string `json:"storageHost,omitempty"`
+	StoragePort int32  `json:"storagePort,omitempty"`
+}
+
+// SchedulingSpec holds the priority and preemption plugin settings rendered into slurm.conf.
+// Empty fields are filled in by the operator when EnforceQOS is set and SlurmQOS objects reference the deployment.
+type SchedulingSpec struct {
+	// EnforceQOS makes the SlurmQOS objects of the deployment take effect: unless set otherwise, jobs are
+	// prioritised by QoS with priority/multifactor, AccountingStorageEnforce=associations,limits,qos rejects jobs
+	// outside of their associations and limits, and QoS preempting others enable preempt/qos
+	EnforceQOS bool `json:"enforceQOS,omitempty"`
+	// e.g. "priority/multifactor"
+	PriorityType            string `json:"priorityType,omitempty"`
+	PriorityWeightQOS       int32  `json:"priorityWeightQOS,omitempty"`
+	PriorityWeightFairshare int32  `json:"priorityWeightFairshare,omitempty"`
+	PriorityWeightAge       int32  `json:"priorityWeightAge,omitempty"`
+	PriorityWeightJobSize   int32  `json:"priorityWeightJobSize,omitempty"`
+	// e.g. "preempt/qos"
+	PreemptType string `json:"preemptType,omitempty"`
+	// e.g. "REQUEUE" or "SUSPEND,GANG"
+	PreemptMode string `json:"preemptMode,omitempty"`
+	// e.g. "associations,limits,qos"
+	AccountingStorageEnforce string `json:"accountingStorageEnforce,omitempty"`
+	// PartitionQOS is attached to the compute partition as its partition QoS
+	PartitionQOS string `json:"partitionQOS,omitempty"`
+	// AllowedQOS restricts the QoS that may be used in the compute partition
+	AllowedQOS []string `json:"allowedQOS,omitempty"`
 }
 
 type CgroupSpec struct {
@@ -314,6 +636,27 @@ type CgroupSpec struct {
 	Value string `json:"value"`
 }
 
+// MonitoringSpec configures the metrics components deployed next to a slurm cluster
+type MonitoringSpec struct {
+	Exporter SlurmExporterSpec `json:"exporter,omitempty"`
+}
+
+// SlurmExporterSpec configures a Prometheus slurm exporter that talks to slurmctld as a slurm client.
+// The image has to ship slurm client commands matching the cluster version.
+type SlurmExporterSpec struct {
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled"`
+	// Image defaults to ghcr.io/rivosinc/prometheus-slurm-exporter:v1.6.3
+	Image ImageSpec `json:"image,omitempty"`
+	Args  []string  `json:"args,omitempty"`
+	// +kubebuilder:default=9092
+	Port int32 `json:"port,omitempty"`
+	// +kubebuilder:default="30s"
+	ScrapeInterval string `json:"scrapeInterval,omitempty"`
+	// ServiceMonitorLabels are added to the ServiceMonitor so a Prometheus instance can select it
+	ServiceMonitorLabels map[string]string `json:"serviceMonitorLabels,omitempty"`
+}
+
 type ImageMirrorSpec struct {
 	Mirror MirrorSpec `json:"mirror"`
 }
@@ -333,7 +676,7 @@ type ValuesSpec struct {
 	SlurmdGPU   SlurmdGPUSpec   `json:"slurmdGPU,omitempty"`
 	Slurmdbd    SlurmdbdSpec    `json:"slurmdbd"`
 	SlurmLogin  SlurmLogindSpec `json:"login"`
-	// +kubebuilder:default="nano"
+	// ResourcesPreset defaults to the resourcesPreset of the operator defaults, or else to nano
 	ResourcesPreset string             `json:"resourcesPreset,omitempty"`
 	ServiceAccount  ServiceAccountSpec `json:"serviceAccount,omitempty"`
 	SlurmConfig     SlurmConfigSpec    `json:"configuration,omitempty"`
@@ -343,6 +686,7 @@ type ValuesSpec struct {
 	FullnameOverride  string            `json:"fullnameOverride,omitempty"`
 	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
 	CommonLabels      map[string]string `json:"commonLabels,omitempty"`
+	Monitoring        MonitoringSpec    `json:"monitoring,omitempty"`
 }
 
 // SlurmDeploymentSpec defines the desired state of SlurmDeployment.
@@ -352,6 +696,125 @@ type SlurmDeploymentSpec struct {
 	Chart  ChartSpec    `json:"chart"`
 	Job    SlurmJobSpec `json:"job,omitempty"`
 	Values ValuesSpec   `json:"values"`
+	// ReconcileMode DryRun renders the chart and records the diff against the live release without applying it
+	// +kubebuilder:validation:Enum=Apply;DryRun
+	// +kubebuilder:default=Apply
+	ReconcileMode string `json:"reconcileMode,omitempty"`
+	// Suspend stops every helm action and slurmctld restart for this SlurmDeployment, deletion still uninstalls
+	Suspend     bool            `json:"suspend,omitempty"`
+	Maintenance MaintenanceSpec `json:"maintenance,omitempty"`
+	// Hibernate scales every component to zero while keeping its volumes, helm actions are skipped until resumed
+	Hibernate bool `json:"hibernate,omitempty"`
+	// AllowedReferenceNamespaces lists the other namespaces whose SlurmAccounts, SlurmUsers, SlurmQOS, SlurmBackups,
+	// SlurmRestores, SlurmFederations and slurmdbdRefs may point at this SlurmDeployment. References from its own
+	// namespace are always allowed.
+	AllowedReferenceNamespaces []string `json:"allowedReferenceNamespaces,omitempty"`
+}
+
+const (
+	HibernationPhaseHibernating = "Hibernating"
+	HibernationPhaseHibernated  = "Hibernated"
+	HibernationPhaseResuming    = "Resuming"
+	HibernationPhaseRunning     = "Running"
+)
+
+// HibernationStatus reports the progress of a hibernation or resume
+type HibernationStatus struct {
+	Phase   string `json:"phase,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Replicas keeps the replicas of every component from before the hibernation, keyed by workload name
+	Replicas     map[string]int32 `json:"replicas,omitempty"`
+	HibernatedAt *metav1.Time     `json:"hibernatedAt,omitempty"`
+	ResumedAt    *metav1.Time     `json:"resumedAt,omitempty"`
+}
+
+const (
+	VersionUpgradePhaseInProgress = "InProgress"
+	VersionUpgradePhaseCompleted  = "Completed"
+	VersionUpgradePhaseRefused    = "Refused"
+)
+
+// VersionUpgradeStatus reports a Slurm version upgrade rolled out as slurmdbd, then slurmctld, then slurmd and login
+type VersionUpgradeStatus struct {
+	Phase       string `json:"phase,omitempty"`
+	FromVersion string `json:"fromVersion,omitempty"`
+	ToVersion   string `json:"toVersion,omitempty"`
+	// Stage is the component group being upgraded: slurmdbd, slurmctld or slurmd
+	Stage       string       `json:"stage,omitempty"`
+	Message     string       `json:"message,omitempty"`
+	StartedAt   *metav1.Time `json:"startedAt,omitempty"`
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// Condition types of the pre-flight checks
+const (
+	// ConditionPreflightPassed is true once every pre-flight check passed
+	ConditionPreflightPassed            = "PreflightPassed"
+	ConditionStorageClassAvailable      = "StorageClassAvailable"
+	ConditionSharedStorageReadWriteMany = "SharedStorageReadWriteMany"
+	ConditionImagePullSecretsFound      = "ImagePullSecretsFound"
+	ConditionResourceQuotaSufficient    = "ResourceQuotaSufficient"
+	// ConditionClusterRegistered is true once the cluster is registered in the accounting database
+	ConditionClusterRegistered = "ClusterRegistered"
+	// ConditionHibernationBlocked is true while hibernation is refused because stopping would lose data or stop a
+	// slurmdbd other releases report to
+	ConditionHibernationBlocked = "HibernationBlocked"
+)
+
+// TopologyStatus reports the topology.conf last written to slurmctld
+type TopologyStatus struct {
+	// Hash is the sha256 of the applied topology.conf
+	Hash      string       `json:"hash,omitempty"`
+	Switches  int32        `json:"switches,omitempty"`
+	Nodes     int32        `json:"nodes,omitempty"`
+	Message   string       `json:"message,omitempty"`
+	AppliedAt *metav1.Time `json:"appliedAt,omitempty"`
+}
+
+// MaintenanceSpec takes every partition out of service and waits for the running jobs to finish
+type MaintenanceSpec struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// PartitionState is applied to every partition while in maintenance
+	// +kubebuilder:validation:Enum=DRAIN;DOWN
+	// +kubebuilder:default=DRAIN
+	PartitionState string `json:"partitionState,omitempty"`
+}
+
+const (
+	MaintenancePhaseDraining = "Draining"
+	MaintenancePhaseDrained  = "Drained"
+)
+
+// MaintenanceStatus reports the progress of a maintenance
+type MaintenanceStatus struct {
+	Phase       string       `json:"phase,omitempty"`
+	Message     string       `json:"message,omitempty"`
+	Partitions  []string     `json:"partitions,omitempty"`
+	RunningJobs int32        `json:"runningJobs"`
+	StartedAt   *metav1.Time `json:"startedAt,omitempty"`
+	DrainedAt   *metav1.Time `json:"drainedAt,omitempty"`
+	// PriorStates maps each partition to its state before the maintenance, restored once it ends
+	PriorStates map[string]string `json:"priorStates,omitempty"`
+}
+
+const (
+	ReconcileModeApply  = "Apply"
+	ReconcileModeDryRun = "DryRun"
+)
+
+// DryRunStatus describes the last diff rendered in DryRun mode
+type DryRunStatus struct {
+	// ObservedGeneration is the generation the diff was rendered for
+	ObservedGeneration int64    `json:"observedGeneration,omitempty"`
+	Summary            string   `json:"summary,omitempty"`
+	Added              []string `json:"added,omitempty"`
+	Removed            []string `json:"removed,omitempty"`
+	Changed            []string `json:"changed,omitempty"`
+	// DiffConfigMap holds the full unified diff under the "diff" key
+	DiffConfigMap string       `json:"diffConfigMap,omitempty"`
+	RenderedAt    *metav1.Time `json:"renderedAt,omitempty"`
+	// ValuesHash identifies the values the diff was rendered from, it changes with the shared defaults too
+	ValuesHash string `json:"valuesHash,omitempty"`
 }
 
 type SlurmJobSpec struct {
@@ -373,6 +836,37 @@ type SlurmDeploymentStatus struct {
 	MariadbServiceCount string `json:"mariadbServiceCount,omitempty"`
 	JobCommand          string `json:"jobCommand,omitempty"`
 	ClusterStatus       string `json:"clusterStatus,omitempty"`
+	// CurrentRevision is the latest helm revision of the release, successful or not
+	CurrentRevision int `json:"currentRevision,omitempty"`
+	// LastSuccessfulRevision is the latest helm revision that was deployed successfully
+	LastSuccessfulRevision int `json:"lastSuccessfulRevision,omitempty"`
+	// ObservedGeneration is the generation last applied to the helm release
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// AppliedValuesHash identifies the chart and values last applied, an unchanged hash skips the upgrade
+	AppliedValuesHash string `json:"appliedValuesHash,omitempty"`
+	// DryRun is set while reconcileMode is DryRun
+	DryRun *DryRunStatus `json:"dryRun,omitempty"`
+	// Maintenance is set while spec.maintenance is enabled
+	Maintenance *MaintenanceStatus `json:"maintenance,omitempty"`
+	Hibernation *HibernationStatus `json:"hibernation,omitempty"`
+	// ClusterName is the ClusterName the cluster was installed with
+	ClusterName string `json:"clusterName,omitempty"`
+	// SlurmVersion is the Slurm version every component runs
+	SlurmVersion string `json:"slurmVersion,omitempty"`
+	// AppliedImages are the images of the Slurm components in the last applied helm values
+	AppliedImages  map[string]ImageSpec  `json:"appliedImages,omitempty"`
+	VersionUpgrade *VersionUpgradeStatus `json:"versionUpgrade,omitempty"`
+	// Topology is set while configuration.topology is enabled
+	Topology *TopologyStatus `json:"topology,omitempty"`
+	// LoginEndpoint is the address SSH clients reach the login nodes at, e.g. "203.0.113.10:22"
+	LoginEndpoint string `json:"loginEndpoint,omitempty"`
+	// WebTerminalURL is where browsers open the web terminal while it is enabled
+	WebTerminalURL string `json:"webTerminalURL,omitempty"`
+	// Conditions report the pre-flight checks run before the helm install
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -386,6 +880,9 @@ type SlurmDeploymentStatus struct {
 // +kubebuilder:printcolumn:name="DBsvc",type="string",JSONPath=".status.mariadbServiceCount",description="Number of mariadb nodes"
 // +kubebuilder:printcolumn:name="Job Command",type="string",JSONPath=".status.jobCommand",description="Current job command"
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.clusterStatus",description="Cluster status"
+// +kubebuilder:printcolumn:name="Slurm",type="string",JSONPath=".status.slurmVersion",description="Running Slurm version",priority=1
+// +kubebuilder:printcolumn:name="Login Endpoint",type="string",JSONPath=".status.loginEndpoint",description="SSH endpoint of the login nodes",priority=1
+// +kubebuilder:printcolumn:name="Revision",type="integer",JSONPath=".status.currentRevision",description="Current helm revision",priority=1
 
 // SlurmDeployment is the Schema for the slurmdeployments API.
 type SlurmDeployment struct {
@@ -415,6 +912,7 @@ func (v *ValuesSpec) UnmarshalJSON(data []byte) error {
 		FullnameOverride  string             `json:"fullnameOverride,omitempty"`
 		CommonAnnotations map[string]string  `json:"commonAnnotations,omitempty"`
 		CommonLabels      map[string]string  `json:"commonLabels,omitempty"`
+		Monitoring        MonitoringSpec     `json:"monitoring,omitempty"`
 	}{}
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
@@ -436,6 +934,7 @@ func (v *ValuesSpec) UnmarshalJSON(data []byte) error {
 	v.FullnameOverride = aux.FullnameOverride
 	v.CommonAnnotations = aux.CommonAnnotations
 	v.CommonLabels = aux.CommonLabels
+	v.Monitoring = aux.Monitoring
 	return nil
 }
 
@@ -0,0 +1,89 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SlurmFederationMember references a SlurmDeployment taking part in a federation
+type SlurmFederationMember struct {
+	Name string `json:"name"`
+	// Namespace of the SlurmDeployment, defaults to the namespace of the SlurmFederation. A SlurmDeployment in
+	// another namespace has to list the namespace of the SlurmFederation in allowedReferenceNamespaces.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// SlurmFederationSpec defines the desired state of SlurmFederation.
+// Every member has to report to the same slurmdbd, see accounting.slurmdbdRef of SlurmDeployment.
+type SlurmFederationSpec struct {
+	// FederationName is the name of the federation in sacctmgr, defaults to the object name
+	FederationName string `json:"federationName,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Members []SlurmFederationMember `json:"members"`
+}
+
+// SlurmFederationMemberStatus is a member as seen by `scontrol show federation`
+type SlurmFederationMemberStatus struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace,omitempty"`
+	ClusterName string `json:"clusterName,omitempty"`
+	// FedState e.g. "ACTIVE", "ACTIVE+DRAIN" or "INACTIVE"
+	FedState string `json:"fedState,omitempty"`
+	// Connected is true when the sibling keeps a persistent connection to the reporting cluster
+	Connected bool `json:"connected"`
+	// Synced is true when the sibling has received the job state of the reporting cluster
+	Synced bool `json:"synced"`
+}
+
+// SlurmFederationStatus defines the observed state of SlurmFederation.
+type SlurmFederationStatus struct {
+	Phase              string                        `json:"phase,omitempty"`
+	Message            string                        `json:"message,omitempty"`
+	Members            []SlurmFederationMemberStatus `json:"members,omitempty"`
+	ObservedGeneration int64                         `json:"observedGeneration,omitempty"`
+	LastSyncTime       *metav1.Time                  `json:"lastSyncTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=sfed
+// +kubebuilder:printcolumn:name="Federation",type="string",JSONPath=".spec.federationName",description="Federation name in sacctmgr"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Sync phase"
+// +kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.message",description="Sync message",priority=1
+
+// SlurmFederation is the Schema for the slurmfederations API.
+type SlurmFederation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SlurmFederationSpec   `json:"spec,omitempty"`
+	Status SlurmFederationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SlurmFederationList contains a list of SlurmFederation.
+type SlurmFederationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SlurmFederation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SlurmFederation{}, &SlurmFederationList{})
+}
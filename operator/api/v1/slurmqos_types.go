@@ -0,0 +1,81 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SlurmQOSSpec defines the desired state of SlurmQOS.
+type SlurmQOSSpec struct {
+	DeploymentRef SlurmDeploymentReference `json:"deploymentRef"`
+	// QOSName defaults to metadata.name
+	QOSName     string `json:"qosName,omitempty"`
+	Description string `json:"description,omitempty"`
+	Priority    *int32 `json:"priority,omitempty"`
+	// +kubebuilder:validation:Enum=OFF;CANCEL;REQUEUE;SUSPEND;CLUSTER
+	PreemptMode string `json:"preemptMode,omitempty"`
+	// Preempt lists the QoS that jobs of this QoS may preempt
+	Preempt []string `json:"preempt,omitempty"`
+	// MaxTRESPerUser e.g. "cpu=128,gres/gpu=8"
+	MaxTRESPerUser string `json:"maxTRESPerUser,omitempty"`
+	// MaxWall per job in slurm time format, e.g. "1-00:00:00"
+	MaxWall string `json:"maxWall,omitempty"`
+	GrpTRES string `json:"grpTRES,omitempty"`
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	UsageFactor string   `json:"usageFactor,omitempty"`
+	Flags       []string `json:"flags,omitempty"`
+}
+
+// SlurmQOSStatus defines the observed state of SlurmQOS.
+type SlurmQOSStatus struct {
+	Phase              string       `json:"phase,omitempty"`
+	Message            string       `json:"message,omitempty"`
+	Drift              []string     `json:"drift,omitempty"`
+	ObservedGeneration int64        `json:"observedGeneration,omitempty"`
+	LastSyncTime       *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=sqos
+// +kubebuilder:printcolumn:name="Deployment",type="string",JSONPath=".spec.deploymentRef.name",description="Target SlurmDeployment"
+// +kubebuilder:printcolumn:name="Priority",type="integer",JSONPath=".spec.priority",description="QoS priority"
+// +kubebuilder:printcolumn:name="Preempt Mode",type="string",JSONPath=".spec.preemptMode",description="Preempt mode"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Sync phase"
+
+// SlurmQOS is the Schema for the slurmqos API.
+type SlurmQOS struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SlurmQOSSpec   `json:"spec,omitempty"`
+	Status SlurmQOSStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SlurmQOSList contains a list of SlurmQOS.
+type SlurmQOSList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SlurmQOS `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SlurmQOS{}, &SlurmQOSList{})
+}
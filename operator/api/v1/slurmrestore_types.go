@@ -0,0 +1,80 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SlurmRestoreSpec defines the desired state of SlurmRestore.
+type SlurmRestoreSpec struct {
+	// DeploymentRef is the SlurmDeployment restored into, usually a freshly created one
+	DeploymentRef SlurmDeploymentReference `json:"deploymentRef"`
+	// BackupRef is the SlurmBackup whose target and images are used
+	BackupRef string `json:"backupRef"`
+	// BackupID selects a backup run, defaults to the last successful backup of BackupRef
+	BackupID string `json:"backupID,omitempty"`
+}
+
+const (
+	RestorePhasePending     = "Pending"
+	RestorePhaseScalingDown = "ScalingDown"
+	RestorePhaseRestoring   = "Restoring"
+	RestorePhaseScalingUp   = "ScalingUp"
+	RestorePhaseCompleted   = "Completed"
+	RestorePhaseFailed      = "Failed"
+)
+
+// SlurmRestoreStatus defines the observed state of SlurmRestore.
+type SlurmRestoreStatus struct {
+	Phase          string       `json:"phase,omitempty"`
+	Message        string       `json:"message,omitempty"`
+	BackupID       string       `json:"backupID,omitempty"`
+	JobName        string       `json:"jobName,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=srst
+// +kubebuilder:printcolumn:name="Deployment",type="string",JSONPath=".spec.deploymentRef.name",description="Target SlurmDeployment"
+// +kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".spec.backupRef",description="Source SlurmBackup"
+// +kubebuilder:printcolumn:name="Backup ID",type="string",JSONPath=".status.backupID",description="Restored backup run"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Restore phase"
+
+// SlurmRestore is the Schema for the slurmrestores API.
+type SlurmRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SlurmRestoreSpec   `json:"spec,omitempty"`
+	Status SlurmRestoreStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SlurmRestoreList contains a list of SlurmRestore.
+type SlurmRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SlurmRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SlurmRestore{}, &SlurmRestoreList{})
+}
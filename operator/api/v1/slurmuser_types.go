@@ -0,0 +1,79 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SlurmUserSpec defines the desired state of SlurmUser.
+type SlurmUserSpec struct {
+	DeploymentRef SlurmDeploymentReference `json:"deploymentRef"`
+	// UserName defaults to metadata.name
+	UserName       string `json:"userName,omitempty"`
+	DefaultAccount string `json:"defaultAccount"`
+	// Accounts the user is associated with besides DefaultAccount
+	Accounts []string `json:"accounts,omitempty"`
+	// +kubebuilder:validation:Enum=None;Operator;Administrator
+	// +kubebuilder:default="None"
+	AdminLevel string `json:"adminLevel,omitempty"`
+	// +kubebuilder:default=1
+	Fairshare  int32                      `json:"fairshare,omitempty"`
+	Limits     SlurmAssociationLimitsSpec `json:"limits,omitempty"`
+	DefaultQOS string                     `json:"defaultQOS,omitempty"`
+	QOS        []string                   `json:"qos,omitempty"`
+}
+
+// SlurmUserStatus defines the observed state of SlurmUser.
+type SlurmUserStatus struct {
+	Phase              string       `json:"phase,omitempty"`
+	Message            string       `json:"message,omitempty"`
+	Drift              []string     `json:"drift,omitempty"`
+	Accounts           []string     `json:"accounts,omitempty"`
+	ObservedGeneration int64        `json:"observedGeneration,omitempty"`
+	LastSyncTime       *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=suser
+// +kubebuilder:printcolumn:name="Deployment",type="string",JSONPath=".spec.deploymentRef.name",description="Target SlurmDeployment"
+// +kubebuilder:printcolumn:name="Account",type="string",JSONPath=".spec.defaultAccount",description="Default account"
+// +kubebuilder:printcolumn:name="Admin",type="string",JSONPath=".spec.adminLevel",description="Admin level"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Sync phase"
+
+// SlurmUser is the Schema for the slurmusers API.
+type SlurmUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SlurmUserSpec   `json:"spec,omitempty"`
+	Status SlurmUserStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SlurmUserList contains a list of SlurmUser.
+type SlurmUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SlurmUser `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SlurmUser{}, &SlurmUserList{})
+}
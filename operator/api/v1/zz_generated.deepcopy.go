@@ -22,9 +22,30 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccountingSpec) DeepCopyInto(out *AccountingSpec) {
+	*out = *in
+	if in.SlurmdbdRef != nil {
+		in, out := &in.SlurmdbdRef, &out.SlurmdbdRef
+		*out = new(SlurmDeploymentReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountingSpec.
+func (in *AccountingSpec) DeepCopy() *AccountingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AccountingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthSSHConfigmapSpec) DeepCopyInto(out *AuthSSHConfigmapSpec) {
 	*out = *in
@@ -109,6 +130,99 @@ func (in *AuthSpec) DeepCopy() *AuthSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupImagesSpec) DeepCopyInto(out *BackupImagesSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupImagesSpec.
+func (in *BackupImagesSpec) DeepCopy() *BackupImagesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupImagesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupPVCTargetSpec) DeepCopyInto(out *BackupPVCTargetSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPVCTargetSpec.
+func (in *BackupPVCTargetSpec) DeepCopy() *BackupPVCTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupPVCTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRecord) DeepCopyInto(out *BackupRecord) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRecord.
+func (in *BackupRecord) DeepCopy() *BackupRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupS3TargetSpec) DeepCopyInto(out *BackupS3TargetSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupS3TargetSpec.
+func (in *BackupS3TargetSpec) DeepCopy() *BackupS3TargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupS3TargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupTargetSpec) DeepCopyInto(out *BackupTargetSpec) {
+	*out = *in
+	if in.PVC != nil {
+		in, out := &in.PVC, &out.PVC
+		*out = new(BackupPVCTargetSpec)
+		**out = **in
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(BackupS3TargetSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupTargetSpec.
+func (in *BackupTargetSpec) DeepCopy() *BackupTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CgroupSpec) DeepCopyInto(out *CgroupSpec) {
 	*out = *in
@@ -127,6 +241,7 @@ func (in *CgroupSpec) DeepCopy() *CgroupSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChartSpec) DeepCopyInto(out *ChartSpec) {
 	*out = *in
+	in.UpgradePolicy.DeepCopyInto(&out.UpgradePolicy)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartSpec.
@@ -164,6 +279,40 @@ func (in *DiagnosticModeSpec) DeepCopy() *DiagnosticModeSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DryRunStatus) DeepCopyInto(out *DryRunStatus) {
+	*out = *in
+	if in.Added != nil {
+		in, out := &in.Added, &out.Added
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Removed != nil {
+		in, out := &in.Removed, &out.Removed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Changed != nil {
+		in, out := &in.Changed, &out.Changed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RenderedAt != nil {
+		in, out := &in.RenderedAt, &out.RenderedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunStatus.
+func (in *DryRunStatus) DeepCopy() *DryRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DryRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExtraVolumeMountsSpec) DeepCopyInto(out *ExtraVolumeMountsSpec) {
 	*out = *in
@@ -179,6 +328,36 @@ func (in *ExtraVolumeMountsSpec) DeepCopy() *ExtraVolumeMountsSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HibernationStatus) DeepCopyInto(out *HibernationStatus) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.HibernatedAt != nil {
+		in, out := &in.HibernatedAt, &out.HibernatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ResumedAt != nil {
+		in, out := &in.ResumedAt, &out.ResumedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HibernationStatus.
+func (in *HibernationStatus) DeepCopy() *HibernationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HibernationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageMirrorSpec) DeepCopyInto(out *ImageMirrorSpec) {
 	*out = *in
@@ -215,6 +394,78 @@ func (in *ImageSpec) DeepCopy() *ImageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoginServiceSpec) DeepCopyInto(out *LoginServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoginServiceSpec.
+func (in *LoginServiceSpec) DeepCopy() *LoginServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoginServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceSpec) DeepCopyInto(out *MaintenanceSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceSpec.
+func (in *MaintenanceSpec) DeepCopy() *MaintenanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceStatus) DeepCopyInto(out *MaintenanceStatus) {
+	*out = *in
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.DrainedAt != nil {
+		in, out := &in.DrainedAt, &out.DrainedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.PriorStates != nil {
+		in, out := &in.PriorStates, &out.PriorStates
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceStatus.
+func (in *MaintenanceStatus) DeepCopy() *MaintenanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MariaDBAuthSpec) DeepCopyInto(out *MariaDBAuthSpec) {
 	*out = *in
@@ -297,6 +548,22 @@ func (in *MirrorSpec) DeepCopy() *MirrorSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+	*out = *in
+	in.Exporter.DeepCopyInto(&out.Exporter)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MungedSpec) DeepCopyInto(out *MungedSpec) {
 	*out = *in
@@ -321,6 +588,12 @@ func (in *MungedSpec) DeepCopyInto(out *MungedSpec) {
 		*out = make([]ExtraVolumeMountsSpec, len(*in))
 		copy(*out, *in)
 	}
+	out.Probes = in.Probes
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(corev1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MungedSpec.
@@ -353,6 +626,21 @@ func (in *NodeAffinityPreset) DeepCopy() *NodeAffinityPreset {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCSpec) DeepCopyInto(out *OIDCSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCSpec.
+func (in *OIDCSpec) DeepCopy() *OIDCSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PersistenceSharedSpec) DeepCopyInto(out *PersistenceSharedSpec) {
 	*out = *in
@@ -377,6 +665,13 @@ func (in *PersistenceSharedSpec) DeepCopy() *PersistenceSharedSpec {
 func (in *PersistenceSpec) DeepCopyInto(out *PersistenceSpec) {
 	*out = *in
 	in.Shared.DeepCopyInto(&out.Shared)
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]SharedVolumeSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistenceSpec.
@@ -389,6 +684,39 @@ func (in *PersistenceSpec) DeepCopy() *PersistenceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeSpec) DeepCopyInto(out *ProbeSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeSpec.
+func (in *ProbeSpec) DeepCopy() *ProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbesSpec) DeepCopyInto(out *ProbesSpec) {
+	*out = *in
+	out.Liveness = in.Liveness
+	out.Readiness = in.Readiness
+	out.Startup = in.Startup
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbesSpec.
+func (in *ProbesSpec) DeepCopy() *ProbesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceLimitSpec) DeepCopyInto(out *ResourceLimitSpec) {
 	*out = *in
@@ -444,6 +772,26 @@ func (in *ResourceSpec) DeepCopy() *ResourceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingSpec) DeepCopyInto(out *SchedulingSpec) {
+	*out = *in
+	if in.AllowedQOS != nil {
+		in, out := &in.AllowedQOS, &out.AllowedQOS
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingSpec.
+func (in *SchedulingSpec) DeepCopy() *SchedulingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceAccountRoleBindingSpec) DeepCopyInto(out *ServiceAccountRoleBindingSpec) {
 	*out = *in
@@ -499,42 +847,62 @@ func (in *ServiceAccountSpec) DeepCopy() *ServiceAccountSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlurmConfigSpec) DeepCopyInto(out *SlurmConfigSpec) {
+func (in *SharedVolumeMountPaths) DeepCopyInto(out *SharedVolumeMountPaths) {
 	*out = *in
-	out.Cgroup = in.Cgroup
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmConfigSpec.
-func (in *SlurmConfigSpec) DeepCopy() *SlurmConfigSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeMountPaths.
+func (in *SharedVolumeMountPaths) DeepCopy() *SharedVolumeMountPaths {
 	if in == nil {
 		return nil
 	}
-	out := new(SlurmConfigSpec)
+	out := new(SharedVolumeMountPaths)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlurmDeployment) DeepCopyInto(out *SlurmDeployment) {
+func (in *SharedVolumeSpec) DeepCopyInto(out *SharedVolumeSpec) {
+	*out = *in
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	out.MountPaths = in.MountPaths
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeSpec.
+func (in *SharedVolumeSpec) DeepCopy() *SharedVolumeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmAccount) DeepCopyInto(out *SlurmAccount) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmDeployment.
-func (in *SlurmDeployment) DeepCopy() *SlurmDeployment {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmAccount.
+func (in *SlurmAccount) DeepCopy() *SlurmAccount {
 	if in == nil {
 		return nil
 	}
-	out := new(SlurmDeployment)
+	out := new(SlurmAccount)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SlurmDeployment) DeepCopyObject() runtime.Object {
+func (in *SlurmAccount) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -542,25 +910,277 @@ func (in *SlurmDeployment) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlurmDeploymentList) DeepCopyInto(out *SlurmDeploymentList) {
+func (in *SlurmAccountList) DeepCopyInto(out *SlurmAccountList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SlurmDeployment, len(*in))
+		*out = make([]SlurmAccount, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmDeploymentList.
-func (in *SlurmDeploymentList) DeepCopy() *SlurmDeploymentList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmAccountList.
+func (in *SlurmAccountList) DeepCopy() *SlurmAccountList {
 	if in == nil {
 		return nil
 	}
-	out := new(SlurmDeploymentList)
+	out := new(SlurmAccountList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmAccountList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmAccountSpec) DeepCopyInto(out *SlurmAccountSpec) {
+	*out = *in
+	out.DeploymentRef = in.DeploymentRef
+	in.Limits.DeepCopyInto(&out.Limits)
+	if in.QOS != nil {
+		in, out := &in.QOS, &out.QOS
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmAccountSpec.
+func (in *SlurmAccountSpec) DeepCopy() *SlurmAccountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmAccountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmAccountStatus) DeepCopyInto(out *SlurmAccountStatus) {
+	*out = *in
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmAccountStatus.
+func (in *SlurmAccountStatus) DeepCopy() *SlurmAccountStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmAccountStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmAssociationLimitsSpec) DeepCopyInto(out *SlurmAssociationLimitsSpec) {
+	*out = *in
+	if in.MaxJobs != nil {
+		in, out := &in.MaxJobs, &out.MaxJobs
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxSubmitJobs != nil {
+		in, out := &in.MaxSubmitJobs, &out.MaxSubmitJobs
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmAssociationLimitsSpec.
+func (in *SlurmAssociationLimitsSpec) DeepCopy() *SlurmAssociationLimitsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmAssociationLimitsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmBackup) DeepCopyInto(out *SlurmBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmBackup.
+func (in *SlurmBackup) DeepCopy() *SlurmBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmBackupList) DeepCopyInto(out *SlurmBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SlurmBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmBackupList.
+func (in *SlurmBackupList) DeepCopy() *SlurmBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmBackupSpec) DeepCopyInto(out *SlurmBackupSpec) {
+	*out = *in
+	out.DeploymentRef = in.DeploymentRef
+	in.Target.DeepCopyInto(&out.Target)
+	out.Images = in.Images
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmBackupSpec.
+func (in *SlurmBackupSpec) DeepCopy() *SlurmBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmBackupStatus) DeepCopyInto(out *SlurmBackupStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Backups != nil {
+		in, out := &in.Backups, &out.Backups
+		*out = make([]BackupRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmBackupStatus.
+func (in *SlurmBackupStatus) DeepCopy() *SlurmBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmConfigSpec) DeepCopyInto(out *SlurmConfigSpec) {
+	*out = *in
+	out.Cgroup = in.Cgroup
+	in.Scheduling.DeepCopyInto(&out.Scheduling)
+	in.Accounting.DeepCopyInto(&out.Accounting)
+	out.Topology = in.Topology
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmConfigSpec.
+func (in *SlurmConfigSpec) DeepCopy() *SlurmConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmDeployment) DeepCopyInto(out *SlurmDeployment) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmDeployment.
+func (in *SlurmDeployment) DeepCopy() *SlurmDeployment {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmDeployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmDeployment) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmDeploymentList) DeepCopyInto(out *SlurmDeploymentList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SlurmDeployment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmDeploymentList.
+func (in *SlurmDeploymentList) DeepCopy() *SlurmDeploymentList {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmDeploymentList)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -574,104 +1194,659 @@ func (in *SlurmDeploymentList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlurmDeploymentSpec) DeepCopyInto(out *SlurmDeploymentSpec) {
+func (in *SlurmDeploymentReference) DeepCopyInto(out *SlurmDeploymentReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmDeploymentReference.
+func (in *SlurmDeploymentReference) DeepCopy() *SlurmDeploymentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmDeploymentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmDeploymentSpec) DeepCopyInto(out *SlurmDeploymentSpec) {
+	*out = *in
+	in.Chart.DeepCopyInto(&out.Chart)
+	in.Job.DeepCopyInto(&out.Job)
+	in.Values.DeepCopyInto(&out.Values)
+	out.Maintenance = in.Maintenance
+	if in.AllowedReferenceNamespaces != nil {
+		in, out := &in.AllowedReferenceNamespaces, &out.AllowedReferenceNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmDeploymentSpec.
+func (in *SlurmDeploymentSpec) DeepCopy() *SlurmDeploymentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmDeploymentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmDeploymentStatus) DeepCopyInto(out *SlurmDeploymentStatus) {
+	*out = *in
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(DryRunStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(MaintenanceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hibernation != nil {
+		in, out := &in.Hibernation, &out.Hibernation
+		*out = new(HibernationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AppliedImages != nil {
+		in, out := &in.AppliedImages, &out.AppliedImages
+		*out = make(map[string]ImageSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.VersionUpgrade != nil {
+		in, out := &in.VersionUpgrade, &out.VersionUpgrade
+		*out = new(VersionUpgradeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Topology != nil {
+		in, out := &in.Topology, &out.Topology
+		*out = new(TopologyStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmDeploymentStatus.
+func (in *SlurmDeploymentStatus) DeepCopy() *SlurmDeploymentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmDeploymentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmExporterSpec) DeepCopyInto(out *SlurmExporterSpec) {
+	*out = *in
+	in.Image.DeepCopyInto(&out.Image)
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceMonitorLabels != nil {
+		in, out := &in.ServiceMonitorLabels, &out.ServiceMonitorLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmExporterSpec.
+func (in *SlurmExporterSpec) DeepCopy() *SlurmExporterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmExporterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmFederation) DeepCopyInto(out *SlurmFederation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmFederation.
+func (in *SlurmFederation) DeepCopy() *SlurmFederation {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmFederation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmFederation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmFederationList) DeepCopyInto(out *SlurmFederationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SlurmFederation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmFederationList.
+func (in *SlurmFederationList) DeepCopy() *SlurmFederationList {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmFederationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmFederationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmFederationMember) DeepCopyInto(out *SlurmFederationMember) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmFederationMember.
+func (in *SlurmFederationMember) DeepCopy() *SlurmFederationMember {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmFederationMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmFederationMemberStatus) DeepCopyInto(out *SlurmFederationMemberStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmFederationMemberStatus.
+func (in *SlurmFederationMemberStatus) DeepCopy() *SlurmFederationMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmFederationMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmFederationSpec) DeepCopyInto(out *SlurmFederationSpec) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]SlurmFederationMember, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmFederationSpec.
+func (in *SlurmFederationSpec) DeepCopy() *SlurmFederationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmFederationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmFederationStatus) DeepCopyInto(out *SlurmFederationStatus) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]SlurmFederationMemberStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmFederationStatus.
+func (in *SlurmFederationStatus) DeepCopy() *SlurmFederationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmFederationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmJobSpec) DeepCopyInto(out *SlurmJobSpec) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmJobSpec.
+func (in *SlurmJobSpec) DeepCopy() *SlurmJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmLogindSpec) DeepCopyInto(out *SlurmLogindSpec) {
+	*out = *in
+	if in.CommonLabels != nil {
+		in, out := &in.CommonLabels, &out.CommonLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Image.DeepCopyInto(&out.Image)
+	in.Resources.DeepCopyInto(&out.Resources)
+	in.DiagnosticMode.DeepCopyInto(&out.DiagnosticMode)
+	in.NodeAffinityPreset.DeepCopyInto(&out.NodeAffinityPreset)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraVolumes != nil {
+		in, out := &in.ExtraVolumes, &out.ExtraVolumes
+		*out = make([]corev1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraVolumeMounts != nil {
+		in, out := &in.ExtraVolumeMounts, &out.ExtraVolumeMounts
+		*out = make([]ExtraVolumeMountsSpec, len(*in))
+		copy(*out, *in)
+	}
+	in.Service.DeepCopyInto(&out.Service)
+	in.WebTerminal.DeepCopyInto(&out.WebTerminal)
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(corev1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmLogindSpec.
+func (in *SlurmLogindSpec) DeepCopy() *SlurmLogindSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmLogindSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmQOS) DeepCopyInto(out *SlurmQOS) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmQOS.
+func (in *SlurmQOS) DeepCopy() *SlurmQOS {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmQOS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmQOS) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmQOSList) DeepCopyInto(out *SlurmQOSList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SlurmQOS, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmQOSList.
+func (in *SlurmQOSList) DeepCopy() *SlurmQOSList {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmQOSList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmQOSList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmQOSSpec) DeepCopyInto(out *SlurmQOSSpec) {
+	*out = *in
+	out.DeploymentRef = in.DeploymentRef
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Preempt != nil {
+		in, out := &in.Preempt, &out.Preempt
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Flags != nil {
+		in, out := &in.Flags, &out.Flags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmQOSSpec.
+func (in *SlurmQOSSpec) DeepCopy() *SlurmQOSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmQOSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmQOSStatus) DeepCopyInto(out *SlurmQOSStatus) {
+	*out = *in
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmQOSStatus.
+func (in *SlurmQOSStatus) DeepCopy() *SlurmQOSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmQOSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmRestore) DeepCopyInto(out *SlurmRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmRestore.
+func (in *SlurmRestore) DeepCopy() *SlurmRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmRestoreList) DeepCopyInto(out *SlurmRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SlurmRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmRestoreList.
+func (in *SlurmRestoreList) DeepCopy() *SlurmRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmRestoreSpec) DeepCopyInto(out *SlurmRestoreSpec) {
 	*out = *in
-	out.Chart = in.Chart
-	in.Job.DeepCopyInto(&out.Job)
-	in.Values.DeepCopyInto(&out.Values)
+	out.DeploymentRef = in.DeploymentRef
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmDeploymentSpec.
-func (in *SlurmDeploymentSpec) DeepCopy() *SlurmDeploymentSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmRestoreSpec.
+func (in *SlurmRestoreSpec) DeepCopy() *SlurmRestoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SlurmDeploymentSpec)
+	out := new(SlurmRestoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlurmDeploymentStatus) DeepCopyInto(out *SlurmDeploymentStatus) {
+func (in *SlurmRestoreStatus) DeepCopyInto(out *SlurmRestoreStatus) {
 	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmDeploymentStatus.
-func (in *SlurmDeploymentStatus) DeepCopy() *SlurmDeploymentStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmRestoreStatus.
+func (in *SlurmRestoreStatus) DeepCopy() *SlurmRestoreStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SlurmDeploymentStatus)
+	out := new(SlurmRestoreStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlurmJobSpec) DeepCopyInto(out *SlurmJobSpec) {
+func (in *SlurmUser) DeepCopyInto(out *SlurmUser) {
 	*out = *in
-	if in.Command != nil {
-		in, out := &in.Command, &out.Command
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmUser.
+func (in *SlurmUser) DeepCopy() *SlurmUser {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmUser) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmUserList) DeepCopyInto(out *SlurmUserList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SlurmUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmUserList.
+func (in *SlurmUserList) DeepCopy() *SlurmUserList {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmUserList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlurmUserList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmUserSpec) DeepCopyInto(out *SlurmUserSpec) {
+	*out = *in
+	out.DeploymentRef = in.DeploymentRef
+	if in.Accounts != nil {
+		in, out := &in.Accounts, &out.Accounts
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.Args != nil {
-		in, out := &in.Args, &out.Args
+	in.Limits.DeepCopyInto(&out.Limits)
+	if in.QOS != nil {
+		in, out := &in.QOS, &out.QOS
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmJobSpec.
-func (in *SlurmJobSpec) DeepCopy() *SlurmJobSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmUserSpec.
+func (in *SlurmUserSpec) DeepCopy() *SlurmUserSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SlurmJobSpec)
+	out := new(SlurmUserSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlurmLogindSpec) DeepCopyInto(out *SlurmLogindSpec) {
+func (in *SlurmUserStatus) DeepCopyInto(out *SlurmUserStatus) {
 	*out = *in
-	if in.CommonLabels != nil {
-		in, out := &in.CommonLabels, &out.CommonLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	in.Image.DeepCopyInto(&out.Image)
-	in.Resources.DeepCopyInto(&out.Resources)
-	in.DiagnosticMode.DeepCopyInto(&out.DiagnosticMode)
-	in.NodeAffinityPreset.DeepCopyInto(&out.NodeAffinityPreset)
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.ExtraVolumes != nil {
-		in, out := &in.ExtraVolumes, &out.ExtraVolumes
-		*out = make([]corev1.Volume, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.ExtraVolumeMounts != nil {
-		in, out := &in.ExtraVolumeMounts, &out.ExtraVolumeMounts
-		*out = make([]ExtraVolumeMountsSpec, len(*in))
+	if in.Accounts != nil {
+		in, out := &in.Accounts, &out.Accounts
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmLogindSpec.
-func (in *SlurmLogindSpec) DeepCopy() *SlurmLogindSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmUserStatus.
+func (in *SlurmUserStatus) DeepCopy() *SlurmUserStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SlurmLogindSpec)
+	out := new(SlurmUserStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -719,6 +1894,12 @@ func (in *SlurmctldSpec) DeepCopyInto(out *SlurmctldSpec) {
 		*out = make([]ExtraVolumeMountsSpec, len(*in))
 		copy(*out, *in)
 	}
+	out.Probes = in.Probes
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(corev1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmctldSpec.
@@ -762,6 +1943,22 @@ func (in *SlurmdCPUSpec) DeepCopyInto(out *SlurmdCPUSpec) {
 		*out = make([]ExtraVolumeMountsSpec, len(*in))
 		copy(*out, *in)
 	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FeaturesFromNodeLabels != nil {
+		in, out := &in.FeaturesFromNodeLabels, &out.FeaturesFromNodeLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Probes = in.Probes
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(corev1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmdCPUSpec.
@@ -774,6 +1971,22 @@ func (in *SlurmdCPUSpec) DeepCopy() *SlurmdCPUSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlurmdExtendedResourceSpec) DeepCopyInto(out *SlurmdExtendedResourceSpec) {
+	*out = *in
+	out.Quantity = in.Quantity.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmdExtendedResourceSpec.
+func (in *SlurmdExtendedResourceSpec) DeepCopy() *SlurmdExtendedResourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlurmdExtendedResourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SlurmdGPUSpec) DeepCopyInto(out *SlurmdGPUSpec) {
 	*out = *in
@@ -805,6 +2018,22 @@ func (in *SlurmdGPUSpec) DeepCopyInto(out *SlurmdGPUSpec) {
 		*out = make([]ExtraVolumeMountsSpec, len(*in))
 		copy(*out, *in)
 	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FeaturesFromNodeLabels != nil {
+		in, out := &in.FeaturesFromNodeLabels, &out.FeaturesFromNodeLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Probes = in.Probes
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(corev1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmdGPUSpec.
@@ -860,6 +2089,13 @@ func (in *SlurmdResourceSpec) DeepCopyInto(out *SlurmdResourceSpec) {
 		*out = new(SlurmdResourceLimitSpec)
 		**out = **in
 	}
+	if in.Extended != nil {
+		in, out := &in.Extended, &out.Extended
+		*out = make([]SlurmdExtendedResourceSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmdResourceSpec.
@@ -904,6 +2140,12 @@ func (in *SlurmdbdSpec) DeepCopyInto(out *SlurmdbdSpec) {
 		*out = make([]ExtraVolumeMountsSpec, len(*in))
 		copy(*out, *in)
 	}
+	out.Probes = in.Probes
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(corev1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlurmdbdSpec.
@@ -916,6 +2158,60 @@ func (in *SlurmdbdSpec) DeepCopy() *SlurmdbdSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologySpec) DeepCopyInto(out *TopologySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologySpec.
+func (in *TopologySpec) DeepCopy() *TopologySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyStatus) DeepCopyInto(out *TopologyStatus) {
+	*out = *in
+	if in.AppliedAt != nil {
+		in, out := &in.AppliedAt, &out.AppliedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyStatus.
+func (in *TopologyStatus) DeepCopy() *TopologyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradePolicySpec) DeepCopyInto(out *UpgradePolicySpec) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePolicySpec.
+func (in *UpgradePolicySpec) DeepCopy() *UpgradePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ValuesSpec) DeepCopyInto(out *ValuesSpec) {
 	*out = *in
@@ -930,7 +2226,7 @@ func (in *ValuesSpec) DeepCopyInto(out *ValuesSpec) {
 	in.Slurmdbd.DeepCopyInto(&out.Slurmdbd)
 	in.SlurmLogin.DeepCopyInto(&out.SlurmLogin)
 	in.ServiceAccount.DeepCopyInto(&out.ServiceAccount)
-	out.SlurmConfig = in.SlurmConfig
+	in.SlurmConfig.DeepCopyInto(&out.SlurmConfig)
 	if in.CommonAnnotations != nil {
 		in, out := &in.CommonAnnotations, &out.CommonAnnotations
 		*out = make(map[string]string, len(*in))
@@ -945,6 +2241,7 @@ func (in *ValuesSpec) DeepCopyInto(out *ValuesSpec) {
 			(*out)[key] = val
 		}
 	}
+	in.Monitoring.DeepCopyInto(&out.Monitoring)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValuesSpec.
@@ -956,3 +2253,106 @@ func (in *ValuesSpec) DeepCopy() *ValuesSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionUpgradeStatus) DeepCopyInto(out *VersionUpgradeStatus) {
+	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionUpgradeStatus.
+func (in *VersionUpgradeStatus) DeepCopy() *VersionUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebTerminalAuthSpec) DeepCopyInto(out *WebTerminalAuthSpec) {
+	*out = *in
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(OIDCSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebTerminalAuthSpec.
+func (in *WebTerminalAuthSpec) DeepCopy() *WebTerminalAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WebTerminalAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebTerminalIngressSpec) DeepCopyInto(out *WebTerminalIngressSpec) {
+	*out = *in
+	if in.ClassName != nil {
+		in, out := &in.ClassName, &out.ClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebTerminalIngressSpec.
+func (in *WebTerminalIngressSpec) DeepCopy() *WebTerminalIngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WebTerminalIngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebTerminalSpec) DeepCopyInto(out *WebTerminalSpec) {
+	*out = *in
+	in.Authentication.DeepCopyInto(&out.Authentication)
+	if in.AllowedGroups != nil {
+		in, out := &in.AllowedGroups, &out.AllowedGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(WebTerminalIngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebTerminalSpec.
+func (in *WebTerminalSpec) DeepCopy() *WebTerminalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WebTerminalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
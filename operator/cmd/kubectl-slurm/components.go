@@ -0,0 +1,165 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	"github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+const (
+	loginComponent   = "login"
+	mariadbComponent = "mariadb"
+	mungedContainer  = "munged"
+)
+
+// components lists the workloads of a release in the order the status prints them
+var components = []string{"slurmctld", "slurmdbd", "slurmd-cpu", "slurmd-gpu", loginComponent, mariadbComponent}
+
+// workload is the observed state of the StatefulSet or Deployment of a component
+type workload struct {
+	component string
+	name      string
+	found     bool
+	replicas  int32
+	ready     int32
+	selector  *metav1.LabelSelector
+}
+
+// workloadName returns the workload name of a component, following <release>-<chart>-<component> except for the
+// mariadb subchart which is named after the release only
+func workloadName(release *slurmv1.SlurmDeployment, component string) string {
+	if component == mariadbComponent {
+		return fmt.Sprintf("%s-%s", release.Name, mariadbComponent)
+	}
+	return utils.ComponentName(release, component)
+}
+
+// validComponent fails for a name that is not in the components list
+func validComponent(component string) error {
+	for _, known := range components {
+		if component == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown component %q, expected one of %s", component, strings.Join(components, ", "))
+}
+
+// getWorkload reads the workload of a component, the login nodes run as a Deployment and every other component as a
+// StatefulSet. A workload that does not exist, e.g. the gpu nodes of a cpu only cluster, is returned as not found.
+func getWorkload(ctx context.Context, c client.Client, release *slurmv1.SlurmDeployment, component string) (*workload, error) {
+	key := types.NamespacedName{Namespace: release.Spec.Chart.Namespace, Name: workloadName(release, component)}
+	result := &workload{component: component, name: key.Name}
+
+	var err error
+	if component == loginComponent {
+		deploy := &appsv1.Deployment{}
+		if err = c.Get(ctx, key, deploy); err == nil {
+			result.replicas, result.ready, result.selector = deploy.Status.Replicas, deploy.Status.ReadyReplicas, deploy.Spec.Selector
+		}
+	} else {
+		sts := &appsv1.StatefulSet{}
+		if err = c.Get(ctx, key, sts); err == nil {
+			result.replicas, result.ready, result.selector = sts.Status.Replicas, sts.Status.ReadyReplicas, sts.Spec.Selector
+		}
+	}
+	if apierrors.IsNotFound(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the %s workload %s: %v", component, key.Name, err)
+	}
+	result.found = true
+	return result, nil
+}
+
+// componentPods lists the pods of a component sorted by name
+func componentPods(ctx context.Context, c client.Client, release *slurmv1.SlurmDeployment, component string) ([]corev1.Pod, error) {
+	w, err := getWorkload(ctx, c, release, component)
+	if err != nil {
+		return nil, err
+	}
+	if !w.found {
+		return nil, fmt.Errorf("the %s workload %s does not exist", component, w.name)
+	}
+	if w.selector == nil || len(w.selector.MatchLabels) == 0 {
+		return nil, fmt.Errorf("the %s workload %s selects no pods", component, w.name)
+	}
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(release.Spec.Chart.Namespace),
+		client.MatchingLabels(w.selector.MatchLabels)); err != nil {
+		return nil, fmt.Errorf("failed to list the %s pods: %v", component, err)
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+	return pods.Items, nil
+}
+
+// loginPod returns the first ready login pod and the container running the login shell
+func loginPod(ctx context.Context, c client.Client, release *slurmv1.SlurmDeployment) (*corev1.Pod, string, error) {
+	pods, err := componentPods(ctx, c, release, loginComponent)
+	if err != nil {
+		return nil, "", err
+	}
+	for i := range pods {
+		if podReady(&pods[i]) {
+			return &pods[i], componentContainer(&pods[i], loginComponent), nil
+		}
+	}
+	return nil, "", errors.New("no login pod is ready")
+}
+
+// podReady is true for a running pod with the Ready condition that is not being deleted
+func podReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning || !pod.DeletionTimestamp.IsZero() {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// componentContainer returns the container named after the component, else the first container that is not the
+// munged sidecar
+func componentContainer(pod *corev1.Pod, component string) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == component {
+			return container.Name
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name != mungedContainer {
+			return container.Name
+		}
+	}
+	return ""
+}
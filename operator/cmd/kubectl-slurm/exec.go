@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// execOptions are the streams attached to a command run in a pod, utils.PodExecutor only captures stdout
+type execOptions struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	// tty allocates a terminal in the container, stdin is switched to raw mode while the command runs
+	tty bool
+}
+
+// streamExec runs a command inside a container and streams its input and output
+func (o *options) streamExec(ctx context.Context, pod *corev1.Pod, container string, command []string, streams execOptions) error {
+	req := o.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     streams.stdin != nil,
+			Stdout:    streams.stdout != nil,
+			// a terminal merges stderr into stdout
+			Stderr: streams.stderr != nil && !streams.tty,
+			TTY:    streams.tty,
+		}, clientgoscheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(o.restConfig, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create executor for pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	streamOptions := remotecommand.StreamOptions{Stdin: streams.stdin, Stdout: streams.stdout, Tty: streams.tty}
+	if !streams.tty {
+		streamOptions.Stderr = streams.stderr
+	}
+
+	if streams.tty {
+		if file, ok := streams.stdin.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+			state, err := term.MakeRaw(int(file.Fd()))
+			if err != nil {
+				return fmt.Errorf("failed to switch the terminal to raw mode: %v", err)
+			}
+			defer func() { _ = term.Restore(int(file.Fd()), state) }()
+		}
+		if file, ok := streams.stdout.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+			streamOptions.TerminalSizeQueue = newTerminalSizeQueue(ctx, int(file.Fd()))
+		}
+	}
+
+	if err := executor.StreamWithContext(ctx, streamOptions); err != nil {
+		return fmt.Errorf("command [%s] failed in pod %s/%s: %v", strings.Join(command, " "), pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
+
+// shellQuote quotes an argument for sh -c
+func shellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// asUser runs a command as a login user through su, the command runs as root in the container without one
+func asUser(user string, command []string) []string {
+	if user == "" {
+		return command
+	}
+	quoted := make([]string, 0, len(command))
+	for _, arg := range command {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	return []string{"su", "-", user, "-c", strings.Join(quoted, " ")}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func newSubmitCommand(o *options) *cobra.Command {
+	var runAs string
+	cmd := &cobra.Command{
+		Use:   "submit <script> [-- sbatch flags...]",
+		Short: "Submit a batch script with sbatch on a login node, - reads the script from stdin",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var script io.Reader = o.streams.In
+			if args[0] != "-" {
+				file, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to open the batch script: %v", err)
+				}
+				defer file.Close()
+				script = file
+			}
+
+			user, err := o.jobUser(runAs)
+			if err != nil {
+				return err
+			}
+			if err := o.complete(); err != nil {
+				return err
+			}
+			release, err := o.release(cmd.Context())
+			if err != nil {
+				return err
+			}
+			pod, container, err := loginPod(cmd.Context(), o.client, release)
+			if err != nil {
+				return err
+			}
+			// sbatch reads the script from stdin when it is given no file
+			command := asUser(user, append([]string{"sbatch"}, args[1:]...))
+			return o.streamExec(cmd.Context(), pod, container, command, execOptions{
+				stdin: script, stdout: o.streams.Out, stderr: o.streams.ErrOut,
+			})
+		},
+	}
+	cmd.Flags().StringVar(&runAs, "run-as", "", "login user submitting the job, the kubeconfig user if empty")
+	return cmd
+}
+
+func newQueueCommand(o *options) *cobra.Command {
+	var runAs string
+	cmd := &cobra.Command{
+		Use:   "queue [-- squeue flags...]",
+		Short: "List the jobs with squeue on a login node",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			user, err := o.jobUser(runAs)
+			if err != nil {
+				return err
+			}
+			if err := o.complete(); err != nil {
+				return err
+			}
+			release, err := o.release(cmd.Context())
+			if err != nil {
+				return err
+			}
+			pod, container, err := loginPod(cmd.Context(), o.client, release)
+			if err != nil {
+				return err
+			}
+			command := asUser(user, append([]string{"squeue"}, args...))
+			return o.streamExec(cmd.Context(), pod, container, command, execOptions{
+				stdout: o.streams.Out, stderr: o.streams.ErrOut,
+			})
+		},
+	}
+	cmd.Flags().StringVar(&runAs, "run-as", "", "login user running squeue, the kubeconfig user if empty")
+	return cmd
+}
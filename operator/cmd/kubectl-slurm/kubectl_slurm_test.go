@@ -0,0 +1,186 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+func testRelease(name string) *slurmv1.SlurmDeployment {
+	return &slurmv1.SlurmDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: slurmv1.SlurmDeploymentSpec{
+			Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"},
+		},
+	}
+}
+
+func loginTestPod(name string, ready bool, containers ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "slurm", Labels: map[string]string{"app": "login"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container})
+	}
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}
+	return pod
+}
+
+var _ = Describe("kubectl-slurm", func() {
+	ctx := context.Background()
+
+	Context("resolving the SlurmDeployment", func() {
+		newTestOptions := func(objects ...client.Object) *options {
+			o := newOptions(genericclioptions.NewTestIOStreamsDiscard())
+			o.namespace = "default"
+			o.client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			return o
+		}
+
+		It("picks the only SlurmDeployment of the namespace", func() {
+			release, err := newTestOptions(testRelease("lab")).release(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release.Name).To(Equal("lab"))
+		})
+
+		It("asks for --deployment when the namespace has several", func() {
+			_, err := newTestOptions(testRelease("lab"), testRelease("prod")).release(ctx)
+			Expect(err).To(MatchError(ContainSubstring("lab, prod")))
+		})
+
+		It("places the workloads next to the SlurmDeployment when the chart has no namespace", func() {
+			lab := testRelease("lab")
+			lab.Spec.Chart.Namespace = ""
+			o := newTestOptions(lab, testRelease("prod"))
+			o.deployment = "lab"
+			release, err := o.release(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release.Spec.Chart.Namespace).To(Equal("default"))
+		})
+	})
+
+	Context("finding the workloads", func() {
+		release := testRelease("lab")
+		login := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "lab-slurm-login", Namespace: "slurm"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "login"}},
+			},
+			Status: appsv1.DeploymentStatus{Replicas: 2, ReadyReplicas: 1},
+		}
+
+		It("follows the naming convention of the chart", func() {
+			Expect(workloadName(release, "slurmd-cpu")).To(Equal("lab-slurm-slurmd-cpu"))
+			Expect(workloadName(release, mariadbComponent)).To(Equal("lab-mariadb"))
+		})
+
+		It("reports a missing workload without failing", func() {
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(login).Build()
+			w, err := getWorkload(ctx, c, release, "slurmd-gpu")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.found).To(BeFalse())
+
+			w, err = getWorkload(ctx, c, release, loginComponent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.found).To(BeTrue())
+			Expect(w.ready).To(Equal(int32(1)))
+			Expect(w.replicas).To(Equal(int32(2)))
+		})
+
+		It("picks the first ready login pod and its login container", func() {
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(login,
+				loginTestPod("lab-slurm-login-a", false, "munged", "login"),
+				loginTestPod("lab-slurm-login-c", true, "munged", "login"),
+				loginTestPod("lab-slurm-login-b", true, "munged", "login"),
+			).Build()
+			pod, container, err := loginPod(ctx, c, release)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pod.Name).To(Equal("lab-slurm-login-b"))
+			Expect(container).To(Equal("login"))
+		})
+
+		It("skips the munged sidecar when no container is named after the component", func() {
+			pod := loginTestPod("lab-slurm-slurmd-cpu-0", true, "munged", "slurmd")
+			Expect(componentContainer(pod, "slurmd-cpu")).To(Equal("slurmd"))
+		})
+	})
+
+	Context("building commands", func() {
+		It("runs a command as a login user through su", func() {
+			Expect(asUser("", []string{"squeue"})).To(Equal([]string{"squeue"}))
+			Expect(asUser("alice", []string{"sbatch", "--job-name=it's"})).To(Equal(
+				[]string{"su", "-", "alice", "-c", `'sbatch' '--job-name=it'\''s'`}))
+		})
+
+		It("runs jobs as the kubeconfig user unless --run-as names another", func() {
+			kubeconfig := filepath.Join(GinkgoT().TempDir(), "config")
+			Expect(os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+current-context: lab
+contexts:
+- name: lab
+  context: {cluster: lab, user: alice}
+- name: admin
+  context: {cluster: lab, user: kubernetes-admin@lab}
+clusters:
+- name: lab
+  cluster: {server: https://203.0.113.10:6443}
+users:
+- name: alice
+- name: kubernetes-admin@lab
+`), 0o600)).To(Succeed())
+			o := newOptions(genericclioptions.NewTestIOStreamsDiscard())
+			o.configFlags.KubeConfig = &kubeconfig
+
+			Expect(o.jobUser("")).To(Equal("alice"))
+			Expect(o.jobUser("bob")).To(Equal("bob"))
+			Expect(o.jobUser("root")).To(BeEmpty(), "root runs the command without su")
+
+			adminContext := "admin"
+			o.configFlags.Context = &adminContext
+			_, err := o.jobUser("")
+			Expect(err).To(MatchError(ContainSubstring("--run-as")))
+		})
+
+		It("connects ssh to the login endpoint", func() {
+			args, err := sshCommand("203.0.113.10:30022", "alice", []string{"-A"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(args).To(Equal([]string{"-p", "30022", "-l", "alice", "-A", "203.0.113.10"}))
+
+			_, err = sshCommand("", "", nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
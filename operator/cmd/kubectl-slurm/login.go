@@ -0,0 +1,102 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+
+	"github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+func newShellCommand(o *options) *cobra.Command {
+	var runAs string
+	cmd := &cobra.Command{
+		Use:   "shell",
+		Short: "Open an interactive shell on a login node through the API server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := o.complete(); err != nil {
+				return err
+			}
+			release, err := o.release(cmd.Context())
+			if err != nil {
+				return err
+			}
+			pod, container, err := loginPod(cmd.Context(), o.client, release)
+			if err != nil {
+				return err
+			}
+			command := []string{"/bin/bash", "-l"}
+			if runAs != "" {
+				command = []string{"su", "-", runAs}
+			}
+			return o.streamExec(cmd.Context(), pod, container, command, execOptions{
+				stdin: o.streams.In, stdout: o.streams.Out, tty: true,
+			})
+		},
+	}
+	cmd.Flags().StringVar(&runAs, "run-as", "", "login user of the shell, root if empty")
+	return cmd
+}
+
+func newSSHCommand(o *options) *cobra.Command {
+	var login string
+	cmd := &cobra.Command{
+		Use:   "ssh [-- ssh flags...]",
+		Short: "Connect to the login endpoint of the SlurmDeployment with the local ssh client",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.complete(); err != nil {
+				return err
+			}
+			release, err := o.release(cmd.Context())
+			if err != nil {
+				return err
+			}
+			sshArgs, err := sshCommand(release.Status.LoginEndpoint, login, args)
+			if err != nil {
+				return fmt.Errorf("SlurmDeployment %s: %v", release.Name, err)
+			}
+			ssh := exec.CommandContext(cmd.Context(), "ssh", sshArgs...)
+			ssh.Stdin, ssh.Stdout, ssh.Stderr = o.streams.In, o.streams.Out, o.streams.ErrOut
+			return ssh.Run()
+		},
+	}
+	cmd.Flags().StringVarP(&login, "login", "l", "", "user to log in as, defaults to the ssh client configuration")
+	return cmd
+}
+
+// sshCommand returns the arguments of the ssh client for a login endpoint such as "203.0.113.10:22"
+func sshCommand(endpoint, login string, extra []string) ([]string, error) {
+	if endpoint == "" {
+		return nil, errors.New("no login endpoint is reported yet, use `kubectl slurm shell` instead")
+	}
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		host, port = endpoint, fmt.Sprint(utils.LoginSSHPort)
+	}
+	args := []string{"-p", port}
+	if login != "" {
+		args = append(args, "-l", login)
+	}
+	args = append(args, extra...)
+	return append(args, host), nil
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// logsOptions are the flags of the logs command
+type logsOptions struct {
+	container string
+	pod       string
+	follow    bool
+	previous  bool
+	tail      int64
+}
+
+func newLogsCommand(o *options) *cobra.Command {
+	logs := &logsOptions{}
+	cmd := &cobra.Command{
+		Use:       "logs <component>",
+		Short:     "Print the logs of the pods of a component",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: components,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			component := args[0]
+			if err := validComponent(component); err != nil {
+				return err
+			}
+			if err := o.complete(); err != nil {
+				return err
+			}
+			release, err := o.release(cmd.Context())
+			if err != nil {
+				return err
+			}
+			pods, err := componentPods(cmd.Context(), o.client, release, component)
+			if err != nil {
+				return err
+			}
+			if logs.pod != "" {
+				pods = selectPod(pods, logs.pod)
+				if len(pods) == 0 {
+					return fmt.Errorf("pod %s is not a %s pod", logs.pod, component)
+				}
+			}
+			if len(pods) == 0 {
+				return fmt.Errorf("the %s workload has no pods", component)
+			}
+			return o.streamLogs(cmd.Context(), pods, component, logs)
+		},
+	}
+	cmd.Flags().StringVarP(&logs.container, "container", "c", "",
+		"container to print the logs of, defaults to the component container")
+	cmd.Flags().StringVar(&logs.pod, "pod", "", "only print the logs of this pod")
+	cmd.Flags().BoolVarP(&logs.follow, "follow", "f", false, "stream the logs")
+	cmd.Flags().BoolVarP(&logs.previous, "previous", "p", false, "print the logs of the previous container instance")
+	cmd.Flags().Int64Var(&logs.tail, "tail", -1, "lines of recent log to print per pod, -1 prints all")
+	return cmd
+}
+
+// selectPod keeps the pod with the given name
+func selectPod(pods []corev1.Pod, name string) []corev1.Pod {
+	for _, pod := range pods {
+		if pod.Name == name {
+			return []corev1.Pod{pod}
+		}
+	}
+	return nil
+}
+
+// streamLogs prints the logs of every pod, the lines are prefixed with the pod name when there are several pods.
+// Followed logs are streamed from all pods at once, otherwise the pods are printed one after the other.
+func (o *options) streamLogs(ctx context.Context, pods []corev1.Pod, component string, logs *logsOptions) error {
+	out := &lockedWriter{w: o.streams.Out}
+	if !logs.follow || len(pods) == 1 {
+		for i := range pods {
+			if err := o.podLogs(ctx, &pods[i], component, logs, out, len(pods) > 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(pods))
+	for i := range pods {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = o.podLogs(ctx, &pods[i], component, logs, out, true)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *options) podLogs(ctx context.Context, pod *corev1.Pod, component string, logs *logsOptions, out *lockedWriter, prefix bool) error {
+	container := logs.container
+	if container == "" {
+		container = componentContainer(pod, component)
+	}
+	logOptions := &corev1.PodLogOptions{Container: container, Follow: logs.follow, Previous: logs.previous}
+	if logs.tail >= 0 {
+		tail := logs.tail
+		logOptions.TailLines = &tail
+	}
+	stream, err := o.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, logOptions).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the logs of %s/%s container %s: %v", pod.Namespace, pod.Name, container, err)
+	}
+	defer stream.Close()
+
+	if !prefix {
+		_, err = io.Copy(out, stream)
+		return err
+	}
+	reader := bufio.NewReader(stream)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if line[len(line)-1] != '\n' {
+				line += "\n"
+			}
+			if _, writeErr := fmt.Fprintf(out, "[%s] %s", pod.Name, line); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the logs of %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+}
+
+// lockedWriter keeps the lines of concurrent log streams whole
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-slurm is a kubectl plugin to operate the Slurm cluster of a SlurmDeployment, installed on the PATH it runs
+// as `kubectl slurm`
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+)
+
+func main() {
+	// the shared utils log their failures for the operator, here the errors are returned to the user instead
+	log.SetOutput(io.Discard)
+	if err := newRootCommand(genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}).Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	o := newOptions(streams)
+	cmd := &cobra.Command{
+		Use:          "kubectl-slurm",
+		Short:        "Operate the Slurm cluster of a SlurmDeployment",
+		SilenceUsage: true,
+		Annotations: map[string]string{
+			cobra.CommandDisplayNameAnnotation: "kubectl slurm",
+		},
+	}
+	cmd.SetIn(streams.In)
+	cmd.SetOut(streams.Out)
+	cmd.SetErr(streams.ErrOut)
+
+	o.configFlags.AddFlags(cmd.PersistentFlags())
+	cmd.PersistentFlags().StringVarP(&o.deployment, "deployment", "d", "",
+		"name of the SlurmDeployment, defaults to the only SlurmDeployment of the namespace")
+
+	cmd.AddCommand(
+		newStatusCommand(o),
+		newSubmitCommand(o),
+		newQueueCommand(o),
+		newShellCommand(o),
+		newSSHCommand(o),
+		newLogsCommand(o),
+		newReconfigureCommand(o),
+	)
+	return cmd
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	"github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(slurmv1.AddToScheme(scheme))
+}
+
+// options holds the flags and the clients shared by every command
+type options struct {
+	configFlags *genericclioptions.ConfigFlags
+	streams     genericclioptions.IOStreams
+	deployment  string
+
+	namespace  string
+	restConfig *rest.Config
+	client     client.Client
+	clientset  kubernetes.Interface
+	executor   utils.PodExecutor
+}
+
+func newOptions(streams genericclioptions.IOStreams) *options {
+	return &options{
+		configFlags: genericclioptions.NewConfigFlags(true),
+		streams:     streams,
+	}
+}
+
+// complete creates the clients from the kubeconfig flags, it runs once the flags are parsed
+func (o *options) complete() error {
+	namespace, _, err := o.configFlags.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return fmt.Errorf("failed to resolve the namespace: %v", err)
+	}
+	o.namespace = namespace
+
+	o.restConfig, err = o.configFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load the kubeconfig: %v", err)
+	}
+	o.client, err = client.New(o.restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("failed to create client: %v", err)
+	}
+	o.clientset, err = kubernetes.NewForConfig(o.restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %v", err)
+	}
+	o.executor, err = utils.NewPodExecutor(o.restConfig)
+	return err
+}
+
+// release returns the SlurmDeployment named by --deployment, or the only one of the namespace
+func (o *options) release(ctx context.Context) (*slurmv1.SlurmDeployment, error) {
+	release := &slurmv1.SlurmDeployment{}
+	if o.deployment != "" {
+		if err := o.client.Get(ctx, types.NamespacedName{Namespace: o.namespace, Name: o.deployment}, release); err != nil {
+			return nil, fmt.Errorf("failed to get SlurmDeployment %s/%s: %v", o.namespace, o.deployment, err)
+		}
+		return releaseWithNamespace(release), nil
+	}
+
+	releases := &slurmv1.SlurmDeploymentList{}
+	if err := o.client.List(ctx, releases, client.InNamespace(o.namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list SlurmDeployments in namespace %s: %v", o.namespace, err)
+	}
+	switch len(releases.Items) {
+	case 0:
+		return nil, fmt.Errorf("no SlurmDeployment found in namespace %s", o.namespace)
+	case 1:
+		return releaseWithNamespace(&releases.Items[0]), nil
+	}
+	names := make([]string, 0, len(releases.Items))
+	for _, item := range releases.Items {
+		names = append(names, item.Name)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("namespace %s has several SlurmDeployments (%s), choose one with --deployment",
+		o.namespace, strings.Join(names, ", "))
+}
+
+// loginUserPattern matches the user names a login node accepts
+var loginUserPattern = regexp.MustCompile(`^[a-z_][a-z0-9_-]*[$]?$`)
+
+// jobUser returns the login user that submits or lists jobs: the one given with --run-as, or else the user of the
+// kubeconfig context. Jobs never run as root unless asked for with --run-as=root.
+func (o *options) jobUser(runAs string) (string, error) {
+	if runAs == "" {
+		runAs = o.kubeconfigUser()
+		if !loginUserPattern.MatchString(runAs) {
+			return "", fmt.Errorf("cannot use the kubeconfig user %q as login user, choose one with --run-as", runAs)
+		}
+	}
+	if runAs == "root" {
+		return "", nil
+	}
+	return runAs, nil
+}
+
+// kubeconfigUser returns the user of the kubeconfig context, honouring --user and --context
+func (o *options) kubeconfigUser() string {
+	if o.configFlags.AuthInfoName != nil && *o.configFlags.AuthInfoName != "" {
+		return *o.configFlags.AuthInfoName
+	}
+	rawConfig, err := o.configFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return ""
+	}
+	contextName := rawConfig.CurrentContext
+	if o.configFlags.Context != nil && *o.configFlags.Context != "" {
+		contextName = *o.configFlags.Context
+	}
+	if kubeContext, found := rawConfig.Contexts[contextName]; found {
+		return kubeContext.AuthInfo
+	}
+	return ""
+}
+
+// releaseWithNamespace fills the namespace of the chart, the workloads live next to the SlurmDeployment unless the
+// chart names another namespace
+func releaseWithNamespace(release *slurmv1.SlurmDeployment) *slurmv1.SlurmDeployment {
+	if release.Spec.Chart.Namespace == "" {
+		release.Spec.Chart.Namespace = release.Namespace
+	}
+	return release
+}
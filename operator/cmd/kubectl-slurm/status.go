@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+func newStatusCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the readiness of every component and a summary of the Slurm partitions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := o.complete(); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			release, err := o.release(ctx)
+			if err != nil {
+				return err
+			}
+
+			out := o.streams.Out
+			fmt.Fprintf(out, "SlurmDeployment: %s/%s\n", release.Namespace, release.Name)
+			printField(out, "Cluster status", release.Status.ClusterStatus)
+			printField(out, "Slurm version", release.Status.SlurmVersion)
+			printField(out, "Login endpoint", release.Status.LoginEndpoint)
+			printField(out, "Web terminal", release.Status.WebTerminalURL)
+			if release.Status.Hibernation != nil {
+				printField(out, "Hibernation", release.Status.Hibernation.Phase)
+			}
+			if release.Status.Maintenance != nil {
+				printField(out, "Maintenance", release.Status.Maintenance.Phase)
+			}
+			if release.Status.VersionUpgrade != nil {
+				printField(out, "Version upgrade", release.Status.VersionUpgrade.Phase)
+			}
+			fmt.Fprintln(out)
+
+			table := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(table, "COMPONENT\tREADY\tWORKLOAD")
+			for _, component := range components {
+				w, err := getWorkload(ctx, o.client, release, component)
+				if err != nil {
+					return err
+				}
+				ready := "-"
+				if w.found {
+					ready = strconv.Itoa(int(w.ready)) + "/" + strconv.Itoa(int(w.replicas))
+				}
+				fmt.Fprintf(table, "%s\t%s\t%s\n", component, ready, w.name)
+			}
+			if err := table.Flush(); err != nil {
+				return err
+			}
+
+			// slurmctld may be down while the workloads are still worth showing
+			sinfo, err := utils.RunSlurmctldCommand(ctx, o.executor, release, "sinfo", "--summarize")
+			if err != nil {
+				fmt.Fprintf(o.streams.ErrOut, "\nfailed to run sinfo in slurmctld: %v\n", err)
+				return nil
+			}
+			fmt.Fprintf(out, "\n%s", sinfo)
+			return nil
+		},
+	}
+}
+
+func newReconfigureCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reconfigure",
+		Short: "Make slurmctld and every slurmd reread slurm.conf",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := o.complete(); err != nil {
+				return err
+			}
+			release, err := o.release(cmd.Context())
+			if err != nil {
+				return err
+			}
+			output, err := utils.RunSlurmctldCommand(cmd.Context(), o.executor, release, "scontrol", "reconfigure")
+			fmt.Fprint(o.streams.Out, output)
+			if err != nil {
+				return fmt.Errorf("failed to reconfigure SlurmDeployment %s: %v", release.Name, err)
+			}
+			fmt.Fprintf(o.streams.Out, "SlurmDeployment %s reconfigured\n", release.Name)
+			return nil
+		},
+	}
+}
+
+// printField prints a status field, empty fields are left out
+func printField(out io.Writer, name, value string) {
+	if value != "" {
+		fmt.Fprintf(out, "%s: %s\n", name, value)
+	}
+}
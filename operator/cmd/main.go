@@ -17,10 +17,12 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -28,6 +30,7 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -39,7 +42,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	slurmv2 "github.com/AaronYang0628/slurm-on-k8s/api/v2"
 	"github.com/AaronYang0628/slurm-on-k8s/internal/controller"
+	"github.com/AaronYang0628/slurm-on-k8s/internal/defaults"
+	"github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+	webhookslurmv2 "github.com/AaronYang0628/slurm-on-k8s/internal/webhook/v2"
+	"github.com/AaronYang0628/slurm-on-k8s/internal/webterminal"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -52,6 +60,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(slurmv1.AddToScheme(scheme))
+	utilruntime.Must(slurmv2.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -64,6 +73,10 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var webTerminalAddr string
+	var webTerminalService string
+	var defaultsConfigMap string
+	var defaultsFile string
 	var leaderElectionLeaseDuration time.Duration
 	var leaderElectionRenewDeadline time.Duration
 	var leaderElectionRetryPeriod time.Duration
@@ -91,6 +104,17 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&webTerminalAddr, "web-terminal-bind-address", "0", "The address the web terminals of the "+
+		"login nodes are served on, e.g. :8090, or leave as 0 to disable the web terminals.")
+	flag.StringVar(&webTerminalService, "web-terminal-service", "",
+		"The host:port of the Service in front of the web terminal server, the web terminal Services of "+
+			"the SlurmDeployments point at it.")
+	flag.StringVar(&defaultsConfigMap, "defaults-configmap", "",
+		"The <namespace>/<name> of the ConfigMap holding the operator defaults of the SlurmDeployments under the "+
+			"defaults.yaml key, it is watched for changes.")
+	flag.StringVar(&defaultsFile, "defaults-file", "",
+		"The YAML file holding the operator defaults of the SlurmDeployments, it is re-read when it changes. "+
+			"Mutually exclusive with --defaults-configmap.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -215,13 +239,117 @@ func main() {
 		os.Exit(1)
 	}
 
+	podExecutor, err := utils.NewPodExecutor(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create pod executor")
+		os.Exit(1)
+	}
+	if webTerminalAddr == "0" {
+		webTerminalService = ""
+	}
+
+	// the defaults are loaded before any SlurmDeployment is reconciled, so no release is upgraded without them
+	operatorDefaults := defaults.NewStore()
+	switch {
+	case defaultsConfigMap != "" && defaultsFile != "":
+		setupLog.Error(nil, "--defaults-configmap and --defaults-file are mutually exclusive")
+		os.Exit(1)
+	case defaultsConfigMap != "":
+		namespace, name, found := strings.Cut(defaultsConfigMap, "/")
+		if !found || namespace == "" || name == "" {
+			setupLog.Error(nil, "--defaults-configmap must be <namespace>/<name>", "value", defaultsConfigMap)
+			os.Exit(1)
+		}
+		configMapKey := types.NamespacedName{Namespace: namespace, Name: name}
+		if err = operatorDefaults.LoadConfigMap(context.Background(), mgr.GetAPIReader(), configMapKey); err != nil {
+			setupLog.Error(err, "unable to load the operator defaults")
+			os.Exit(1)
+		}
+		if err = (&controller.OperatorDefaultsReconciler{
+			Client:    mgr.GetClient(),
+			Recorder:  mgr.GetEventRecorderFor("operatordefaults-controller"),
+			Defaults:  operatorDefaults,
+			ConfigMap: configMapKey,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "OperatorDefaults")
+			os.Exit(1)
+		}
+	case defaultsFile != "":
+		if err = operatorDefaults.LoadFile(defaultsFile); err != nil {
+			setupLog.Error(err, "unable to load the operator defaults")
+			os.Exit(1)
+		}
+		if err = mgr.Add(operatorDefaults.WatchFile(defaultsFile, 30*time.Second)); err != nil {
+			setupLog.Error(err, "unable to watch the operator defaults file")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controller.SlurmDeploymentReconciler{
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		Executor:           podExecutor,
+		Recorder:           mgr.GetEventRecorderFor("slurmdeployment-controller"),
+		WebTerminalService: webTerminalService,
+		Defaults:           operatorDefaults,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SlurmDeployment")
+		os.Exit(1)
+	}
+	if err = (&controller.SlurmAccountReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Executor: podExecutor,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SlurmAccount")
+		os.Exit(1)
+	}
+	if err = (&controller.SlurmUserReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Executor: podExecutor,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SlurmUser")
+		os.Exit(1)
+	}
+	if err = (&controller.SlurmQOSReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Executor: podExecutor,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SlurmQOS")
+		os.Exit(1)
+	}
+	if err = (&controller.SlurmBackupReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "SlurmDeployment")
+		setupLog.Error(err, "unable to create controller", "controller", "SlurmBackup")
 		os.Exit(1)
 	}
+	if err = (&controller.SlurmRestoreReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SlurmRestore")
+		os.Exit(1)
+	}
+	if err = (&controller.SlurmFederationReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Executor: podExecutor,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SlurmFederation")
+		os.Exit(1)
+	}
+	// SlurmDeployments are stored as v2, the API server calls the conversion webhook to serve them as v1
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookslurmv2.SetupSlurmDeploymentWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SlurmDeployment")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -232,6 +360,19 @@ func main() {
 		}
 	}
 
+	if webTerminalAddr != "0" {
+		terminalExecutor, err := webterminal.NewTerminalExecutor(mgr.GetConfig())
+		if err != nil {
+			setupLog.Error(err, "unable to create web terminal executor")
+			os.Exit(1)
+		}
+		setupLog.Info("Adding web terminal server to manager", "web-terminal-bind-address", webTerminalAddr)
+		if err := mgr.Add(webterminal.NewServer(webTerminalAddr, mgr.GetClient(), terminalExecutor)); err != nil {
+			setupLog.Error(err, "unable to add web terminal server to manager")
+			os.Exit(1)
+		}
+	}
+
 	if webhookCertWatcher != nil {
 		setupLog.Info("Adding webhook certificate watcher to manager")
 		if err := mgr.Add(webhookCertWatcher); err != nil {
@@ -0,0 +1,87 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"log"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/AaronYang0628/slurm-on-k8s/internal/defaults"
+)
+
+const (
+	ReasonDefaultsLoaded  = "DefaultsLoaded"
+	ReasonInvalidDefaults = "InvalidDefaults"
+)
+
+// OperatorDefaultsReconciler loads the operator defaults from a ConfigMap into the Store shared with the
+// SlurmDeployment controller
+type OperatorDefaultsReconciler struct {
+	client.Client
+	Recorder  record.EventRecorder
+	Defaults  *defaults.Store
+	ConfigMap types.NamespacedName
+}
+
+// Reconcile parses the defaults ConfigMap. Invalid defaults are reported on the ConfigMap and the previous defaults
+// stay in use, a deleted ConfigMap removes the defaults.
+func (r *OperatorDefaultsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, req.NamespacedName, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Printf("Defaults ConfigMap %s deleted, running without operator defaults", req.NamespacedName)
+			r.Defaults.Set(nil)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	parsed, err := defaults.FromConfigMap(configMap)
+	if err != nil {
+		log.Printf("Keeping the previous operator defaults, ConfigMap %s: %v", req.NamespacedName, err)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(configMap, corev1.EventTypeWarning, ReasonInvalidDefaults, "Keeping the previous defaults: %v", err)
+		}
+		// the next change of the ConfigMap triggers a new attempt
+		return ctrl.Result{}, nil
+	}
+	if r.Defaults.Set(parsed) {
+		log.Printf("Loaded the operator defaults from ConfigMap %s", req.NamespacedName)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(configMap, corev1.EventTypeNormal, ReasonDefaultsLoaded, "Loaded the operator defaults, every SlurmDeployment is reconciled again")
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OperatorDefaultsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("operatordefaults").
+		For(&corev1.ConfigMap{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetNamespace() == r.ConfigMap.Namespace && obj.GetName() == r.ConfigMap.Name
+		}))).
+		Complete(r)
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+const (
+	// SlurmAccountingFinalizer is the finalizer added to objects applied through sacctmgr
+	SlurmAccountingFinalizer = "slurm.ay.dev/accounting-finalizer"
+
+	AccountingPhasePending = "Pending"
+	AccountingPhaseSynced  = "Synced"
+	AccountingPhaseError   = "Error"
+
+	// accountingResyncInterval is how often applied objects are compared against the accounting database
+	accountingResyncInterval = 5 * time.Minute
+)
+
+// SlurmAccountReconciler reconciles a SlurmAccount object
+type SlurmAccountReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Executor utils.PodExecutor
+}
+
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmaccounts/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmaccounts/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=pods/exec,verbs=create
+
+// Reconcile applies a SlurmAccount to the accounting database of its SlurmDeployment through sacctmgr,
+// reporting every field that drifted from the spec before correcting it.
+func (r *SlurmAccountReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	account := &slurmv1.SlurmAccount{}
+	if findAccountErr := r.Get(ctx, req.NamespacedName, account); findAccountErr != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(findAccountErr)
+	}
+	accountName := account.Spec.AccountName
+	if accountName == "" {
+		accountName = account.Name
+	}
+
+	release, findReleaseErr := findSlurmDeployment(ctx, r.Client, account.Namespace, account.Spec.DeploymentRef)
+
+	if !account.ObjectMeta.DeletionTimestamp.IsZero() {
+		if utils.CheckIfExistInArray(account.ObjectMeta.Finalizers, SlurmAccountingFinalizer) {
+			if findReleaseErr == nil && utils.SacctmgrCluster(release) != "" {
+				log.Printf("Deleting slurm account %s from SlurmDeployment %s", accountName, release.Name)
+				if _, deleteErr := utils.RunSacctmgr(ctx, r.Executor, release, "delete", "account", "name="+accountName,
+					utils.SacctmgrCluster(release)); deleteErr != nil && !utils.IsSacctmgrNothingChanged(deleteErr) {
+					log.Printf("Failed to delete slurm account %s: %v", accountName, deleteErr)
+					return ctrl.Result{}, deleteErr
+				}
+			} else {
+				log.Printf("SlurmDeployment %s of account %s is gone, skipping sacctmgr cleanup", account.Spec.DeploymentRef.Name, accountName)
+			}
+			account.ObjectMeta.Finalizers = utils.SplitHeadArray(account.ObjectMeta.Finalizers, SlurmAccountingFinalizer)
+			if updateErr := r.Update(ctx, account); updateErr != nil {
+				return ctrl.Result{}, updateErr
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if findReleaseErr != nil {
+		if apierrors.IsNotFound(findReleaseErr) {
+			return r.updateAccountStatus(ctx, account, AccountingPhasePending,
+				fmt.Sprintf("SlurmDeployment %s not found", account.Spec.DeploymentRef.Name), nil, 30*time.Second)
+		}
+		if errors.Is(findReleaseErr, errReferenceNotAllowed) {
+			return r.updateAccountStatus(ctx, account, AccountingPhasePending, findReleaseErr.Error(), nil, 30*time.Second)
+		}
+		return ctrl.Result{}, findReleaseErr
+	}
+	cluster := utils.SacctmgrCluster(release)
+	if cluster == "" {
+		return r.updateAccountStatus(ctx, account, AccountingPhasePending,
+			fmt.Sprintf("SlurmDeployment %s has no ClusterName yet", release.Name), nil, 30*time.Second)
+	}
+
+	if !utils.CheckIfExistInArray(account.ObjectMeta.Finalizers, SlurmAccountingFinalizer) {
+		log.Printf("Adding finalizer to SlurmAccount %s", account.Name)
+		account.ObjectMeta.Finalizers = append(account.ObjectMeta.Finalizers, SlurmAccountingFinalizer)
+		if updateErr := r.Update(ctx, account); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	desired := utils.DesiredAssociation(account.Spec.ParentAccount, account.Spec.Fairshare, account.Spec.Limits,
+		account.Spec.DefaultQOS, account.Spec.QOS)
+	if account.Spec.Description != "" {
+		desired["Descr"] = account.Spec.Description
+	}
+	if account.Spec.Organization != "" {
+		desired["Org"] = account.Spec.Organization
+	}
+
+	observed, queryErr := r.queryAccount(ctx, release, accountName, cluster)
+	if queryErr != nil {
+		log.Printf("Failed to query slurm account %s: %v", accountName, queryErr)
+		_, _ = r.updateAccountStatus(ctx, account, AccountingPhaseError, queryErr.Error(), account.Status.Drift, 0)
+		return ctrl.Result{}, queryErr
+	}
+
+	var drift []string
+	if observed == nil {
+		log.Printf("Creating slurm account %s in SlurmDeployment %s", accountName, release.Name)
+		args := append([]string{"add", "account", accountName, cluster}, utils.AssociationSetArgs(desired)...)
+		if _, addErr := utils.RunSacctmgr(ctx, r.Executor, release, args...); addErr != nil && !utils.IsSacctmgrNothingChanged(addErr) {
+			_, _ = r.updateAccountStatus(ctx, account, AccountingPhaseError, addErr.Error(), nil, 0)
+			return ctrl.Result{}, addErr
+		}
+	} else if drift = utils.DiffSacctmgrFields(desired, observed); len(drift) > 0 {
+		log.Printf("Slurm account %s drifted from spec: %v", accountName, drift)
+		args := append([]string{"modify", "account", "where", "name=" + accountName, cluster, "set"}, utils.AssociationSetArgs(desired)...)
+		if _, modifyErr := utils.RunSacctmgr(ctx, r.Executor, release, args...); modifyErr != nil && !utils.IsSacctmgrNothingChanged(modifyErr) {
+			_, _ = r.updateAccountStatus(ctx, account, AccountingPhaseError, modifyErr.Error(), drift, 0)
+			return ctrl.Result{}, modifyErr
+		}
+	}
+
+	message := fmt.Sprintf("Account %s applied", accountName)
+	if len(drift) > 0 {
+		message = fmt.Sprintf("Account %s corrected %d drifted field(s)", accountName, len(drift))
+	}
+	return r.updateAccountStatus(ctx, account, AccountingPhaseSynced, message, drift, accountingResyncInterval)
+}
+
+// queryAccount merges the account record and its account-level association on the cluster, returning nil if the
+// account does not exist or, when the slurmdbd is shared, only exists on other clusters
+func (r *SlurmAccountReconciler) queryAccount(ctx context.Context, release *slurmv1.SlurmDeployment, accountName, cluster string) (map[string]string, error) {
+	accounts, err := utils.QuerySacctmgr(ctx, r.Executor, release, "account", []string{"where", "name=" + accountName}, []string{"Account", "Descr", "Org"})
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+	observed := accounts[0]
+
+	associations, err := utils.QuerySacctmgr(ctx, r.Executor, release, "association", []string{"where", "account=" + accountName, cluster}, utils.AssociationFields)
+	if err != nil {
+		return nil, err
+	}
+	for _, association := range associations {
+		if association["User"] == "" {
+			for key, value := range association {
+				observed[key] = value
+			}
+			return observed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *SlurmAccountReconciler) updateAccountStatus(ctx context.Context, account *slurmv1.SlurmAccount, phase, message string, drift []string, requeueAfter time.Duration) (ctrl.Result, error) {
+	now := metav1.Now()
+	account.Status.Phase = phase
+	account.Status.Message = message
+	account.Status.Drift = drift
+	account.Status.ObservedGeneration = account.Generation
+	account.Status.LastSyncTime = &now
+	if updateStatusErr := r.Status().Update(ctx, account); updateStatusErr != nil {
+		log.Printf("Failed to update SlurmAccount %s status: %v", account.Name, updateStatusErr)
+		return ctrl.Result{}, updateStatusErr
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// errReferenceNotAllowed is wrapped by findSlurmDeployment for a reference from a namespace that the SlurmDeployment
+// does not allow
+var errReferenceNotAllowed = errors.New("namespace is not in allowedReferenceNamespaces")
+
+// findSlurmDeployment resolves a SlurmDeploymentReference made from the namespace of the referring object. A
+// SlurmDeployment in another namespace is only returned when it allows references from that namespace.
+func findSlurmDeployment(ctx context.Context, c client.Client, namespace string, ref slurmv1.SlurmDeploymentReference) (*slurmv1.SlurmDeployment, error) {
+	release := &slurmv1.SlurmDeployment{}
+	if err := c.Get(ctx, slurmDeploymentKey(namespace, ref), release); err != nil {
+		return nil, err
+	}
+	if !referenceAllowed(namespace, release) {
+		return nil, fmt.Errorf("SlurmDeployment %s/%s refuses references from namespace %s: %w",
+			release.Namespace, release.Name, namespace, errReferenceNotAllowed)
+	}
+	return release, nil
+}
+
+// referenceAllowed tells whether objects in namespace may point at release
+func referenceAllowed(namespace string, release *slurmv1.SlurmDeployment) bool {
+	return namespace == release.Namespace || slices.Contains(release.Spec.AllowedReferenceNamespaces, namespace)
+}
+
+// slurmDeploymentKey returns the SlurmDeployment a reference points at, the namespace of the referring object
+// unless the reference names one
+func slurmDeploymentKey(namespace string, ref slurmv1.SlurmDeploymentReference) types.NamespacedName {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: ref.Name}
+}
+
+// refersTo tells whether a reference made from namespace points at release and is allowed by it
+func refersTo(namespace string, ref slurmv1.SlurmDeploymentReference, release *slurmv1.SlurmDeployment) bool {
+	return slurmDeploymentKey(namespace, ref) == types.NamespacedName{Namespace: release.Namespace, Name: release.Name} &&
+		referenceAllowed(namespace, release)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SlurmAccountReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&slurmv1.SlurmAccount{}).
+		Complete(r)
+}
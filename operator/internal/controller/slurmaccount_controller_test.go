@@ -0,0 +1,221 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+var _ = Describe("SlurmAccount Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		slurmaccount := &slurmv1.SlurmAccount{}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind SlurmAccount")
+			err := k8sClient.Get(ctx, typeNamespacedName, slurmaccount)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &slurmv1.SlurmAccount{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: slurmv1.SlurmAccountSpec{
+						DeploymentRef: slurmv1.SlurmDeploymentReference{Name: "missing-deployment"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &slurmv1.SlurmAccount{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance SlurmAccount")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+		It("should wait for the referenced SlurmDeployment", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := &SlurmAccountReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &slurmv1.SlurmAccount{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Phase).To(Equal(AccountingPhasePending))
+		})
+	})
+})
+
+var _ = Describe("SlurmAccount sacctmgr commands", func() {
+	const (
+		showAccount     = "sacctmgr --immediate --noheader --parsable2 show account where name=physics format=Account,Descr,Org"
+		showAssociation = "sacctmgr --immediate --noheader --parsable2 show association where account=physics cluster=lab " +
+			"format=Account,User,ParentName,Share,MaxJobs,MaxSubmit,MaxWall,MaxTRES,GrpTRES,DefaultQOS,QOS"
+	)
+
+	var (
+		executor   *fakePodExecutor
+		reconciler *SlurmAccountReconciler
+		request    reconcile.Request
+	)
+
+	BeforeEach(func() {
+		executor = &fakePodExecutor{outputs: map[string]string{}}
+		release := &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"}},
+			Status:     slurmv1.SlurmDeploymentStatus{ClusterName: "lab"},
+		}
+		account := &slurmv1.SlurmAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "physics", Namespace: "default", Finalizers: []string{SlurmAccountingFinalizer}},
+			Spec: slurmv1.SlurmAccountSpec{
+				DeploymentRef: slurmv1.SlurmDeploymentReference{Name: "cluster"},
+				ParentAccount: "root",
+				Fairshare:     5,
+				Description:   "physics department",
+			},
+		}
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		reconciler = &SlurmAccountReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(release, account).
+				WithStatusSubresource(account).Build(),
+			Scheme:   scheme,
+			Executor: executor,
+		}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "physics"}}
+	})
+
+	It("adds a missing account on the cluster of its SlurmDeployment", func() {
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(Equal([]string{
+			showAccount,
+			"sacctmgr --immediate --noheader --parsable2 add account physics cluster=lab Description=physics department Parent=root Fairshare=5",
+		}))
+	})
+
+	It("treats an account that only exists on another cluster as missing", func() {
+		executor.outputs[showAccount] = "physics|physics department|\n"
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(Equal([]string{
+			showAccount,
+			showAssociation,
+			"sacctmgr --immediate --noheader --parsable2 add account physics cluster=lab Description=physics department Parent=root Fairshare=5",
+		}))
+	})
+
+	It("modifies a drifted account on its cluster only", func() {
+		executor.outputs[showAccount] = "physics|physics department|\n"
+		executor.outputs[showAssociation] = "physics||root|1||||||normal|normal\n"
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(Equal([]string{
+			showAccount,
+			showAssociation,
+			"sacctmgr --immediate --noheader --parsable2 modify account where name=physics cluster=lab set " +
+				"Description=physics department Parent=root Fairshare=5",
+		}))
+
+		account := &slurmv1.SlurmAccount{}
+		Expect(reconciler.Get(context.Background(), request.NamespacedName, account)).To(Succeed())
+		Expect(account.Status.Phase).To(Equal(AccountingPhaseSynced))
+		Expect(account.Status.Drift).To(Equal([]string{`Share: want "5", got "1"`}))
+	})
+
+	It("leaves an account in sync untouched", func() {
+		executor.outputs[showAccount] = "physics|physics department|\n"
+		executor.outputs[showAssociation] = "physics||root|5|||||||\nphysics|alice|physics|1|||||||\n"
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(Equal([]string{showAccount, showAssociation}))
+	})
+
+	It("refuses a reference from a namespace the SlurmDeployment does not allow", func() {
+		ctx := context.Background()
+		foreign := &slurmv1.SlurmAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "physics", Namespace: "tenant", Finalizers: []string{SlurmAccountingFinalizer}},
+			Spec: slurmv1.SlurmAccountSpec{
+				DeploymentRef: slurmv1.SlurmDeploymentReference{Name: "cluster", Namespace: "default"},
+				ParentAccount: "root",
+				Fairshare:     5,
+				Description:   "physics department",
+			},
+		}
+		Expect(reconciler.Create(ctx, foreign)).To(Succeed())
+		foreignRequest := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "tenant", Name: "physics"}}
+
+		_, err := reconciler.Reconcile(ctx, foreignRequest)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(BeEmpty())
+		Expect(reconciler.Get(ctx, foreignRequest.NamespacedName, foreign)).To(Succeed())
+		Expect(foreign.Status.Phase).To(Equal(AccountingPhasePending))
+		Expect(foreign.Status.Message).To(ContainSubstring("refuses references from namespace tenant"))
+
+		release := &slurmv1.SlurmDeployment{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Namespace: "default", Name: "cluster"}, release)).To(Succeed())
+		release.Spec.AllowedReferenceNamespaces = []string{"tenant"}
+		Expect(reconciler.Update(ctx, release)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, foreignRequest)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(ContainElement(showAccount))
+	})
+
+	It("deletes the account from its cluster only", func() {
+		ctx := context.Background()
+		account := &slurmv1.SlurmAccount{}
+		Expect(reconciler.Get(ctx, request.NamespacedName, account)).To(Succeed())
+		Expect(reconciler.Delete(ctx, account)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(Equal([]string{
+			"sacctmgr --immediate --noheader --parsable2 delete account name=physics cluster=lab",
+		}))
+		Expect(errors.IsNotFound(reconciler.Get(ctx, request.NamespacedName, account))).To(BeTrue())
+	})
+})
@@ -0,0 +1,356 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+const (
+	backupLabel          = "slurm.ay.dev/backup"
+	backupNamespaceLabel = "slurm.ay.dev/backup-namespace"
+	restoreLabel         = "slurm.ay.dev/restore"
+
+	backupPollInterval   = 15 * time.Second
+	backupJobTTLSeconds  = int32(24 * 60 * 60)
+	mariadbRootSecretKey = "mariadb-root-password"
+)
+
+// SlurmBackupReconciler reconciles a SlurmBackup object
+type SlurmBackupReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmbackups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmbackups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmbackups/finalizers,verbs=update
+
+// Reconcile starts a backup job whenever the schedule of a SlurmBackup is due, tracks the jobs it started and
+// keeps the number of backups within the retention.
+func (r *SlurmBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	backup := &slurmv1.SlurmBackup{}
+	if findBackupErr := r.Get(ctx, req.NamespacedName, backup); findBackupErr != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(findBackupErr)
+	}
+	if !backup.ObjectMeta.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	release, findReleaseErr := findSlurmDeployment(ctx, r.Client, backup.Namespace, backup.Spec.DeploymentRef)
+	if findReleaseErr != nil {
+		if apierrors.IsNotFound(findReleaseErr) {
+			return r.updateBackupStatus(ctx, backup, slurmv1.BackupPhasePending,
+				fmt.Sprintf("SlurmDeployment %s not found", backup.Spec.DeploymentRef.Name), 30*time.Second)
+		}
+		if errors.Is(findReleaseErr, errReferenceNotAllowed) {
+			return r.updateBackupStatus(ctx, backup, slurmv1.BackupPhasePending, findReleaseErr.Error(), 30*time.Second)
+		}
+		return ctrl.Result{}, findReleaseErr
+	}
+	if (backup.Spec.Target.PVC == nil) == (backup.Spec.Target.S3 == nil) {
+		return r.updateBackupStatus(ctx, backup, slurmv1.BackupPhaseFailed, "exactly one of target.pvc and target.s3 must be set", 0)
+	}
+
+	running, syncErr := r.syncBackupRecords(ctx, release, backup)
+	if syncErr != nil {
+		return ctrl.Result{}, syncErr
+	}
+
+	now := time.Now()
+	due, nextRun, parseErr := backupDue(backup, now)
+	if parseErr != nil {
+		return r.updateBackupStatus(ctx, backup, slurmv1.BackupPhaseFailed, fmt.Sprintf("invalid schedule: %v", parseErr), 0)
+	}
+
+	message := backup.Status.Message
+	if due && running {
+		message = "Skipping a scheduled run, the previous backup is still running"
+		due = false
+	}
+	if due {
+		record, startErr := r.startBackupJob(ctx, release, backup, now)
+		if startErr != nil {
+			log.Printf("Failed to start backup %s: %v", backup.Name, startErr)
+			return r.updateBackupStatus(ctx, backup, slurmv1.BackupPhasePending, startErr.Error(), time.Minute)
+		}
+		scheduled := metav1.NewTime(now)
+		backup.Status.LastScheduleTime = &scheduled
+		backup.Status.Backups = append(backup.Status.Backups, record)
+		running = true
+		message = fmt.Sprintf("Started backup %s", record.ID)
+	}
+
+	backup.Status.Backups = trimBackupRecords(backup.Status.Backups, backup.Spec.Retention)
+
+	phase := slurmv1.BackupPhasePending
+	if count := len(backup.Status.Backups); count > 0 {
+		phase = backup.Status.Backups[count-1].Phase
+	}
+	requeueAfter := time.Duration(0)
+	if running {
+		requeueAfter = backupPollInterval
+	} else if !nextRun.IsZero() {
+		requeueAfter = time.Until(nextRun)
+	}
+	return r.updateBackupStatus(ctx, backup, phase, message, requeueAfter)
+}
+
+// backupDue tells whether a backup has to be started at now and when the schedule runs next. Without a schedule a
+// single backup is taken and there is no next run.
+func backupDue(backup *slurmv1.SlurmBackup, now time.Time) (bool, time.Time, error) {
+	if backup.Spec.Schedule == "" {
+		return len(backup.Status.Backups) == 0 && backup.Status.LastScheduleTime == nil, time.Time{}, nil
+	}
+	schedule, parseErr := cron.ParseStandard(backup.Spec.Schedule)
+	if parseErr != nil {
+		return false, time.Time{}, parseErr
+	}
+	last := backup.CreationTimestamp.Time
+	if backup.Status.LastScheduleTime != nil {
+		last = backup.Status.LastScheduleTime.Time
+	}
+	nextRun := schedule.Next(last)
+	if now.Before(nextRun) {
+		return false, nextRun, nil
+	}
+	return true, schedule.Next(now), nil
+}
+
+// trimBackupRecords keeps the newest records within the retention, the same runs the job keeps in the target
+func trimBackupRecords(records []slurmv1.BackupRecord, retention int32) []slurmv1.BackupRecord {
+	keep := int(retention)
+	if keep < 1 {
+		keep = 1
+	}
+	if len(records) > keep {
+		return records[len(records)-keep:]
+	}
+	return records
+}
+
+// syncBackupRecords copies the state of every backup job into the status and tells whether one is still running
+func (r *SlurmBackupReconciler) syncBackupRecords(ctx context.Context, release *slurmv1.SlurmDeployment, backup *slurmv1.SlurmBackup) (bool, error) {
+	running := false
+	for i := range backup.Status.Backups {
+		record := &backup.Status.Backups[i]
+		if record.Phase == slurmv1.BackupPhaseSucceeded || record.Phase == slurmv1.BackupPhaseFailed {
+			continue
+		}
+		job := &batchv1.Job{}
+		if getJobErr := r.Get(ctx, types.NamespacedName{Namespace: release.Spec.Chart.Namespace, Name: record.JobName}, job); getJobErr != nil {
+			if !apierrors.IsNotFound(getJobErr) {
+				return false, getJobErr
+			}
+			record.Phase = slurmv1.BackupPhaseFailed
+			continue
+		}
+		record.Phase = jobPhase(job)
+		if record.Phase == slurmv1.BackupPhaseRunning {
+			running = true
+			continue
+		}
+		record.CompletionTime = job.Status.CompletionTime
+		if record.CompletionTime == nil {
+			now := metav1.Now()
+			record.CompletionTime = &now
+		}
+		if record.Phase == slurmv1.BackupPhaseSucceeded {
+			backup.Status.LastSuccessfulBackup = record.ID
+		}
+	}
+	return running, nil
+}
+
+func (r *SlurmBackupReconciler) startBackupJob(ctx context.Context, release *slurmv1.SlurmDeployment, backup *slurmv1.SlurmBackup, now time.Time) (slurmv1.BackupRecord, error) {
+	stateClaim, nodeName, claimErr := findSlurmctldStateClaim(ctx, r.Client, release)
+	if claimErr != nil {
+		return slurmv1.BackupRecord{}, claimErr
+	}
+	id := now.UTC().Format(utils.BackupIDFormat)
+	options := backupJobOptions(release, backup, id)
+	if databaseErr := backupDatabase(ctx, r.Client, release, &options); databaseErr != nil {
+		return slurmv1.BackupRecord{}, databaseErr
+	}
+	options.Name = jobName(backup.Name, "backup", id)
+	options.Labels = map[string]string{backupLabel: backup.Name, backupNamespaceLabel: backup.Namespace}
+	options.StateClaim = stateClaim
+	options.NodeName = nodeName
+
+	job := utils.BuildBackupJob(options)
+	ttl := backupJobTTLSeconds
+	job.Spec.TTLSecondsAfterFinished = &ttl
+	if createErr := r.Create(ctx, job); createErr != nil {
+		return slurmv1.BackupRecord{}, createErr
+	}
+	log.Printf("Started backup job %s for SlurmDeployment %s", job.Name, release.Name)
+	started := metav1.NewTime(now)
+	return slurmv1.BackupRecord{ID: id, JobName: job.Name, Phase: slurmv1.BackupPhaseRunning, StartTime: &started,
+		ClusterName: release.Status.ClusterName}, nil
+}
+
+func (r *SlurmBackupReconciler) updateBackupStatus(ctx context.Context, backup *slurmv1.SlurmBackup, phase, message string, requeueAfter time.Duration) (ctrl.Result, error) {
+	backup.Status.Phase = phase
+	backup.Status.Message = message
+	if updateStatusErr := r.Status().Update(ctx, backup); updateStatusErr != nil {
+		log.Printf("Failed to update SlurmBackup %s status: %v", backup.Name, updateStatusErr)
+		return ctrl.Result{}, updateStatusErr
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// backupJobOptions fills the target and database settings shared by backup and restore jobs
+func backupJobOptions(release *slurmv1.SlurmDeployment, backup *slurmv1.SlurmBackup, id string) utils.BackupJobOptions {
+	options := utils.BackupJobOptions{
+		Namespace: release.Spec.Chart.Namespace,
+		BackupID:  id,
+		Directory: utils.BackupDirectory(backup),
+		Retention: backup.Spec.Retention,
+		Target:    backup.Spec.Target,
+		Images:    backup.Spec.Images,
+	}
+	setDatabaseOptions(&options, release)
+	return options
+}
+
+// setDatabaseOptions points a job at the MariaDB deployed by a release
+func setDatabaseOptions(options *utils.BackupJobOptions, release *slurmv1.SlurmDeployment) {
+	port := release.Spec.Values.Mariadb.Port
+	if port == 0 {
+		port = 3306
+	}
+	database := "slurm_acct_db"
+	if release.Spec.Values.Mariadb.Auth != nil && release.Spec.Values.Mariadb.Auth.DatabaseName != "" {
+		database = release.Spec.Values.Mariadb.Auth.DatabaseName
+	}
+	options.DatabaseHost = fmt.Sprintf("%s-mariadb", release.Name)
+	options.DatabasePort = port
+	options.DatabaseName = database
+	options.DatabaseSecret = fmt.Sprintf("%s-mariadb", release.Name)
+	options.DatabaseSecretKey = mariadbRootSecretKey
+}
+
+// backupDatabase points a backup at the accounting database the release reports to. A release sharing the slurmdbd
+// of another release dumps the MariaDB of that release, whose secret the job can only read from the same chart
+// namespace. A slurmdbd running outside of the operator is left out of the backup.
+func backupDatabase(ctx context.Context, c client.Client, release *slurmv1.SlurmDeployment, options *utils.BackupJobOptions) error {
+	accounting := release.Spec.Values.SlurmConfig.Accounting
+	if accounting.SlurmdbdRef == nil {
+		options.SkipDatabase = accounting.StorageHost != ""
+		return nil
+	}
+	owner, findOwnerErr := findSlurmDeployment(ctx, c, release.Namespace, *accounting.SlurmdbdRef)
+	if findOwnerErr != nil {
+		return fmt.Errorf("failed to find SlurmDeployment %s providing slurmdbd: %w", accounting.SlurmdbdRef.Name, findOwnerErr)
+	}
+	if owner.Spec.Chart.Namespace != release.Spec.Chart.Namespace {
+		return fmt.Errorf("the accounting database of SlurmDeployment %s runs in namespace %s, the backup job in namespace %s cannot read its secret",
+			owner.Name, owner.Spec.Chart.Namespace, release.Spec.Chart.Namespace)
+	}
+	setDatabaseOptions(options, owner)
+	return nil
+}
+
+// findSlurmctldStateClaim returns the PVC mounted at the StateSaveLocation of slurmctld and the node slurmctld runs on
+func findSlurmctldStateClaim(ctx context.Context, c client.Client, release *slurmv1.SlurmDeployment) (string, string, error) {
+	sts := &appsv1.StatefulSet{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: release.Spec.Chart.Namespace, Name: utils.ComponentName(release, "slurmctld")}, sts); err != nil {
+		return "", "", err
+	}
+	volumeName := ""
+	for _, container := range sts.Spec.Template.Spec.Containers {
+		if container.Name != utils.SlurmctldContainerName {
+			continue
+		}
+		for _, mount := range container.VolumeMounts {
+			if mount.MountPath == utils.SlurmctldStateMountPath {
+				volumeName = mount.Name
+			}
+		}
+	}
+	claim := ""
+	for _, template := range sts.Spec.VolumeClaimTemplates {
+		if template.Name == volumeName {
+			claim = fmt.Sprintf("%s-%s-0", template.Name, sts.Name)
+		}
+	}
+	for _, volume := range sts.Spec.Template.Spec.Volumes {
+		if volume.Name == volumeName && volume.PersistentVolumeClaim != nil {
+			claim = volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+	if claim == "" {
+		return "", "", fmt.Errorf("slurmctld state %s of %s is not stored on a persistent volume", utils.SlurmctldStateMountPath, release.Name)
+	}
+
+	nodeName := ""
+	pod := &corev1.Pod{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: release.Spec.Chart.Namespace, Name: utils.SlurmctldPodName(release)}, pod); err == nil {
+		nodeName = pod.Spec.NodeName
+	}
+	return claim, nodeName, nil
+}
+
+// jobPhase maps the state of a backup or restore job to a backup phase
+func jobPhase(job *batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return slurmv1.BackupPhaseSucceeded
+		case batchv1.JobFailed:
+			return slurmv1.BackupPhaseFailed
+		}
+	}
+	return slurmv1.BackupPhaseRunning
+}
+
+// jobName builds a job name from an object name and a backup ID within the 63 character limit of labels
+func jobName(owner, action, id string) string {
+	suffix := fmt.Sprintf("-%s-%s", action, id)
+	if len(owner)+len(suffix) > 63 {
+		owner = owner[:63-len(suffix)]
+	}
+	return owner + suffix
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SlurmBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&slurmv1.SlurmBackup{}).
+		Complete(r)
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+var _ = Describe("SlurmBackup Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		slurmbackup := &slurmv1.SlurmBackup{}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind SlurmBackup")
+			err := k8sClient.Get(ctx, typeNamespacedName, slurmbackup)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &slurmv1.SlurmBackup{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: slurmv1.SlurmBackupSpec{
+						DeploymentRef: slurmv1.SlurmDeploymentReference{Name: "missing-deployment"},
+						Retention:     7,
+						Target: slurmv1.BackupTargetSpec{
+							PVC: &slurmv1.BackupPVCTargetSpec{ClaimName: "slurm-backups"},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &slurmv1.SlurmBackup{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance SlurmBackup")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+		It("should wait for the referenced SlurmDeployment", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := &SlurmBackupReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &slurmv1.SlurmBackup{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Phase).To(Equal(slurmv1.BackupPhasePending))
+		})
+	})
+})
+
+var _ = Describe("SlurmBackup database", func() {
+	var (
+		owner *slurmv1.SlurmDeployment
+		team  *slurmv1.SlurmDeployment
+	)
+
+	newClient := func() client.Client {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(owner, team).Build()
+	}
+
+	BeforeEach(func() {
+		owner = &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "accounting", Namespace: "default"},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"}},
+		}
+		owner.Spec.Values.Mariadb.Port = 3307
+		team = &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "default"},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"}},
+		}
+		team.Spec.Values.SlurmConfig.Accounting.SlurmdbdRef = &slurmv1.SlurmDeploymentReference{Name: "accounting"}
+	})
+
+	It("dumps the MariaDB of the release whose slurmdbd is shared", func() {
+		backup := &slurmv1.SlurmBackup{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"}}
+		options := backupJobOptions(team, backup, "20250102-030000")
+		Expect(backupDatabase(context.Background(), newClient(), team, &options)).To(Succeed())
+		Expect(options.SkipDatabase).To(BeFalse())
+		Expect(options.DatabaseHost).To(Equal("accounting-mariadb"))
+		Expect(options.DatabasePort).To(Equal(int32(3307)))
+		Expect(options.DatabaseSecret).To(Equal("accounting-mariadb"))
+	})
+
+	It("refuses a shared MariaDB in another chart namespace", func() {
+		owner.Spec.Chart.Namespace = "accounting"
+		backup := &slurmv1.SlurmBackup{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"}}
+		options := backupJobOptions(team, backup, "20250102-030000")
+		err := backupDatabase(context.Background(), newClient(), team, &options)
+		Expect(err).To(MatchError(ContainSubstring("cannot read its secret")))
+	})
+
+	It("leaves a slurmdbd outside of the operator out of the backup", func() {
+		team.Spec.Values.SlurmConfig.Accounting = slurmv1.AccountingSpec{StorageHost: "slurmdbd.example.com"}
+		backup := &slurmv1.SlurmBackup{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"}}
+		options := backupJobOptions(team, backup, "20250102-030000")
+		Expect(backupDatabase(context.Background(), newClient(), team, &options)).To(Succeed())
+		Expect(options.SkipDatabase).To(BeTrue())
+	})
+})
+
+var _ = Describe("SlurmBackup schedule", func() {
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newBackup := func(schedule string) *slurmv1.SlurmBackup {
+		return &slurmv1.SlurmBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default", CreationTimestamp: metav1.NewTime(created)},
+			Spec:       slurmv1.SlurmBackupSpec{Schedule: schedule},
+		}
+	}
+
+	It("takes a single backup without a schedule", func() {
+		backup := newBackup("")
+		due, next, err := backupDue(backup, created)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(due).To(BeTrue())
+		Expect(next.IsZero()).To(BeTrue())
+
+		scheduled := metav1.NewTime(created)
+		backup.Status.LastScheduleTime = &scheduled
+		due, _, err = backupDue(backup, created.Add(time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(due).To(BeFalse())
+	})
+
+	It("waits for the first run after the creation", func() {
+		due, next, err := backupDue(newBackup("0 3 * * *"), created.Add(time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(due).To(BeFalse())
+		Expect(next).To(Equal(time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC)))
+	})
+
+	It("runs once the schedule is due and plans the following run", func() {
+		backup := newBackup("0 3 * * *")
+		scheduled := metav1.NewTime(time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC))
+		backup.Status.LastScheduleTime = &scheduled
+		due, next, err := backupDue(backup, time.Date(2025, 1, 3, 3, 0, 5, 0, time.UTC))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(due).To(BeTrue())
+		Expect(next).To(Equal(time.Date(2025, 1, 4, 3, 0, 0, 0, time.UTC)))
+	})
+
+	It("catches up on missed runs with a single backup", func() {
+		backup := newBackup("0 3 * * *")
+		due, next, err := backupDue(backup, time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(due).To(BeTrue())
+		Expect(next).To(Equal(time.Date(2025, 1, 11, 3, 0, 0, 0, time.UTC)))
+	})
+
+	It("rejects an invalid schedule", func() {
+		_, _, err := backupDue(newBackup("every night"), created)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("keeps the newest records within the retention", func() {
+		records := []slurmv1.BackupRecord{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+		Expect(trimBackupRecords(records, 2)).To(Equal([]slurmv1.BackupRecord{{ID: "2"}, {ID: "3"}}))
+		Expect(trimBackupRecords(records, 5)).To(HaveLen(3))
+		Expect(trimBackupRecords(records, 0)).To(Equal([]slurmv1.BackupRecord{{ID: "3"}}))
+	})
+})
@@ -0,0 +1,180 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+const (
+	ReasonAccountingFailed   = "AccountingFailed"
+	ReasonClusterNameChanged = "ClusterNameChanged"
+	ReasonClusterRegistered  = "ClusterRegistered"
+)
+
+// applyAccountingDefaults fills in the ClusterName and, for a release sharing the slurmdbd of another release, the
+// host slurmctld sends its accounting to. installed tells whether the helm release already exists.
+func (r *SlurmDeploymentReconciler) applyAccountingDefaults(ctx context.Context, release *slurmv1.SlurmDeployment, installed bool) error {
+	config := &release.Spec.Values.SlurmConfig
+	if config.ClusterName == "" {
+		switch {
+		case release.Status.ClusterName != "":
+			config.ClusterName = release.Status.ClusterName
+		case installed:
+			// slurmctld refuses to start when the ClusterName of its state changes
+			config.ClusterName = utils.DefaultSlurmClusterName
+		default:
+			config.ClusterName = release.Name
+		}
+	}
+	if release.Status.ClusterName != "" && release.Status.ClusterName != config.ClusterName {
+		r.recordEvent(release, corev1.EventTypeWarning, ReasonClusterNameChanged,
+			"ClusterName changes from %s to %s, slurmctld has to be started with a fresh StateSaveLocation", release.Status.ClusterName, config.ClusterName)
+		meta.RemoveStatusCondition(&release.Status.Conditions, slurmv1.ConditionClusterRegistered)
+	}
+	release.Status.ClusterName = config.ClusterName
+
+	ref := config.Accounting.SlurmdbdRef
+	if ref == nil {
+		return nil
+	}
+	if accountingErr := r.resolveSharedSlurmdbd(ctx, release, *ref); accountingErr != nil {
+		r.recordEvent(release, corev1.EventTypeWarning, ReasonAccountingFailed, "%v", accountingErr)
+		return accountingErr
+	}
+	return nil
+}
+
+func (r *SlurmDeploymentReconciler) resolveSharedSlurmdbd(ctx context.Context, release *slurmv1.SlurmDeployment, ref slurmv1.SlurmDeploymentReference) error {
+	if refersTo(release.Namespace, ref, release) {
+		return fmt.Errorf("accounting.slurmdbdRef of %s points at itself", release.Name)
+	}
+	owner, findOwnerErr := findSlurmDeployment(ctx, r.Client, release.Namespace, ref)
+	if findOwnerErr != nil {
+		return fmt.Errorf("failed to find SlurmDeployment %s providing slurmdbd: %w", ref.Name, findOwnerErr)
+	}
+	if owner.Spec.Values.SlurmConfig.Accounting.SlurmdbdRef != nil || owner.Spec.Values.SlurmConfig.Accounting.StorageHost != "" {
+		return fmt.Errorf("SlurmDeployment %s does not run its own slurmdbd", owner.Name)
+	}
+
+	// every cluster of a slurmdbd needs its own ClusterName, whatever namespace it lives in
+	releases := &slurmv1.SlurmDeploymentList{}
+	if listErr := r.List(ctx, releases); listErr != nil {
+		return listErr
+	}
+	for i := range releases.Items {
+		other := &releases.Items[i]
+		if other.Namespace == release.Namespace && other.Name == release.Name {
+			continue
+		}
+		otherRef := other.Spec.Values.SlurmConfig.Accounting.SlurmdbdRef
+		sharesSlurmdbd := (other.Namespace == owner.Namespace && other.Name == owner.Name) ||
+			(otherRef != nil && refersTo(other.Namespace, *otherRef, owner))
+		if sharesSlurmdbd && other.Status.ClusterName == release.Spec.Values.SlurmConfig.ClusterName {
+			return fmt.Errorf("ClusterName %s is already used by SlurmDeployment %s/%s on the same slurmdbd",
+				other.Status.ClusterName, other.Namespace, other.Name)
+		}
+	}
+
+	release.Spec.Values.SlurmConfig.Accounting.StorageHost = slurmdbdServiceHost(owner)
+	release.Spec.Values.SlurmConfig.Accounting.StoragePort = 6819
+	return nil
+}
+
+// slurmdbdServiceHost is the address of the slurmdbd deployed by a release, reachable from other namespaces
+func slurmdbdServiceHost(release *slurmv1.SlurmDeployment) string {
+	slurmdbd := utils.ComponentName(release, "slurmdbd")
+	return fmt.Sprintf("%s-0.%s-headless.%s.svc", slurmdbd, slurmdbd, release.Spec.Chart.Namespace)
+}
+
+// normalizeSlurmdbdHost lets a StorageHost typed by hand match the address of the slurmdbd deployed by a release,
+// e.g. the fully qualified name or a trailing dot
+func normalizeSlurmdbdHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	return strings.TrimSuffix(host, ".cluster.local")
+}
+
+// slurmdbdClients lists the other releases, as namespace/name, whose slurmctld reports to the slurmdbd of owner
+func slurmdbdClients(ctx context.Context, c client.Client, owner *slurmv1.SlurmDeployment) ([]string, error) {
+	releases := &slurmv1.SlurmDeploymentList{}
+	if listErr := c.List(ctx, releases); listErr != nil {
+		return nil, listErr
+	}
+	var clients []string
+	for i := range releases.Items {
+		other := &releases.Items[i]
+		ref := other.Spec.Values.SlurmConfig.Accounting.SlurmdbdRef
+		if ref == nil || (other.Namespace == owner.Namespace && other.Name == owner.Name) {
+			continue
+		}
+		if refersTo(other.Namespace, *ref, owner) {
+			clients = append(clients, other.Namespace+"/"+other.Name)
+		}
+	}
+	sort.Strings(clients)
+	return clients, nil
+}
+
+// usesSharedSlurmdbd tells whether the release reports to a slurmdbd it does not deploy
+func usesSharedSlurmdbd(release *slurmv1.SlurmDeployment) bool {
+	accounting := release.Spec.Values.SlurmConfig.Accounting
+	return accounting.SlurmdbdRef != nil || accounting.StorageHost != ""
+}
+
+// registerSlurmCluster adds the cluster to the accounting database once slurmctld runs. Failures are reported in
+// the ClusterRegistered condition and retried on the next reconcile.
+func (r *SlurmDeploymentReconciler) registerSlurmCluster(ctx context.Context, release *slurmv1.SlurmDeployment) {
+	if r.Executor == nil || meta.IsStatusConditionTrue(release.Status.Conditions, slurmv1.ConditionClusterRegistered) {
+		return
+	}
+	clusterName := utils.SlurmClusterName(&release.Spec.Values.SlurmConfig)
+	condition := metav1.Condition{
+		Type:               slurmv1.ConditionClusterRegistered,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Registered",
+		Message:            fmt.Sprintf("Cluster %s is registered in the accounting database", clusterName),
+		ObservedGeneration: release.Generation,
+	}
+	rows, queryErr := utils.QuerySacctmgr(ctx, r.Executor, release, "cluster", []string{"where", "name=" + clusterName}, []string{"Cluster"})
+	if queryErr == nil && len(rows) == 0 {
+		if _, addErr := utils.RunSacctmgr(ctx, r.Executor, release, "add", "cluster", clusterName); addErr != nil {
+			queryErr = addErr
+		} else {
+			log.Printf("Registered cluster %s of %s in the accounting database", clusterName, release.Name)
+			r.recordEvent(release, corev1.EventTypeNormal, ReasonClusterRegistered, "Registered cluster %s in the accounting database", clusterName)
+		}
+	}
+	if queryErr != nil {
+		log.Printf("Failed to register cluster %s of %s: %v", clusterName, release.Name, queryErr)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "RegistrationFailed"
+		condition.Message = queryErr.Error()
+	}
+	meta.SetStatusCondition(&release.Status.Conditions, condition)
+}
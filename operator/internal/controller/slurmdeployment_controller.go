@@ -28,15 +28,25 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/cli"
+	helmrelease "helm.sh/helm/v3/pkg/release"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
 
+	"github.com/AaronYang0628/slurm-on-k8s/internal/defaults"
+	"github.com/AaronYang0628/slurm-on-k8s/internal/metrics"
 	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
@@ -44,18 +54,49 @@ import (
 // SlurmDeploymentReconciler reconciles a SlurmDeployment object
 type SlurmDeploymentReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Executor utils.PodExecutor
+	Recorder record.EventRecorder
+	// WebTerminalService is the host:port the web terminal Services of the releases point at, empty when the
+	// operator does not serve web terminals
+	WebTerminalService string
+	// Defaults fill the images, chart source, resources and storage classes a SlurmDeployment leaves empty
+	Defaults *defaults.Store
 }
 
+// Event reasons recorded on SlurmDeployment objects
+const (
+	ReasonFinalizerAdded         = "FinalizerAdded"
+	ReasonFinalizerRemoved       = "FinalizerRemoved"
+	ReasonChartDownloadFailed    = "ChartDownloadFailed"
+	ReasonInstalled              = "Installed"
+	ReasonInstallFailed          = "InstallFailed"
+	ReasonUpgraded               = "Upgraded"
+	ReasonUpgradeFailed          = "UpgradeFailed"
+	ReasonUninstalled            = "Uninstalled"
+	ReasonUninstallFailed        = "UninstallFailed"
+	ReasonSlurmctldRestarted     = "SlurmctldRestarted"
+	ReasonSlurmctldRestartFailed = "SlurmctldRestartFailed"
+	ReasonExporterFailed         = "ExporterFailed"
+	ReasonRolledBack             = "RolledBack"
+	ReasonInvalidPodTemplate     = "InvalidPodTemplate"
+	ReasonDefaultsMigrated       = "DefaultsMigrated"
+)
+
 // +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmdeployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmdeployments/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmdeployments/finalizers,verbs=update
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmqoses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmrestores,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;create;update;patch;delete;watch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;create;update;patch;delete
-// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=resourcequotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;create;update;patch;delete;watch
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;create;update;patch;delete;watch
 // +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;create;update;patch;delete;watch
@@ -113,22 +154,45 @@ func (r *SlurmDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 			uninstallClient.Timeout = 60 * time.Second
 			uninstallClient.Wait = false
 
-			if _, uninstallErr := uninstallClient.Run(release.Name); uninstallErr != nil {
+			uninstallStart := time.Now()
+			_, uninstallErr := uninstallClient.Run(release.Name)
+			metrics.ObserveHelmAction(release.Namespace, release.Name, metrics.HelmActionUninstall, uninstallStart, uninstallErr)
+			if uninstallErr != nil {
 				if strings.Contains(uninstallErr.Error(), "release: not found") {
 					log.Printf("SlurmDeployment %s not found, skipping uninstall", release.Name)
 				} else if strings.Contains(uninstallErr.Error(), "timed out") || strings.Contains(uninstallErr.Error(), "BackoffLimitExceeded") {
 					log.Printf("SlurmDeployment %s uninstall timed out or job failed, continuing with CR deletion: %v", release.Name, uninstallErr)
+					r.recordEvent(release, corev1.EventTypeWarning, ReasonUninstallFailed, "Uninstall of release %s did not finish, continuing with deletion: %v", release.Name, uninstallErr)
 				} else {
 					log.Printf("Failed to uninstall SlurmDeployment %s: %v", release.Name, uninstallErr)
+					r.recordEvent(release, corev1.EventTypeWarning, ReasonUninstallFailed, "Failed to uninstall release %s: %v", release.Name, uninstallErr)
 					return ctrl.Result{}, uninstallErr
 				}
+			} else {
+				r.recordEvent(release, corev1.EventTypeNormal, ReasonUninstalled, "Uninstalled release %s from namespace %s", release.Name, release.Spec.Chart.Namespace)
+			}
+
+			// the exporter, topology.conf and the web terminal are not part of the helm release
+			if exporterErr := r.DeleteSlurmExporter(ctx, release); exporterErr != nil {
+				r.recordEvent(release, corev1.EventTypeWarning, ReasonExporterFailed, "Failed to delete slurm exporter: %v", exporterErr)
+				return ctrl.Result{}, exporterErr
+			}
+			if topologyErr := r.DeleteTopologyConf(ctx, release); topologyErr != nil {
+				return ctrl.Result{}, topologyErr
+			}
+			if terminalErr := r.DeleteWebTerminal(ctx, release); terminalErr != nil {
+				r.recordEvent(release, corev1.EventTypeWarning, ReasonWebTerminalFailed, "Failed to delete web terminal: %v", terminalErr)
+				return ctrl.Result{}, terminalErr
 			}
 
+			metrics.ForgetSlurmDeployment(release.Namespace, release.Name)
+
 			// Remove our finalizer from the list and update it
 			release.ObjectMeta.Finalizers = utils.SplitHeadArray(release.ObjectMeta.Finalizers, SlurmDeploymentFinalizer)
 			if updateStatusErr := r.Update(ctx, release); updateStatusErr != nil {
 				return ctrl.Result{}, updateStatusErr
 			}
+			r.recordEvent(release, corev1.EventTypeNormal, ReasonFinalizerRemoved, "Removed finalizer %s", SlurmDeploymentFinalizer)
 		}
 		// Stop reconciliation as the item is being deleted
 		return ctrl.Result{}, nil
@@ -141,48 +205,251 @@ func (r *SlurmDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		if updateStatusErr := r.Update(ctx, release); updateStatusErr != nil {
 			return ctrl.Result{}, updateStatusErr
 		}
+		r.recordEvent(release, corev1.EventTypeNormal, ReasonFinalizerAdded, "Added finalizer %s", SlurmDeploymentFinalizer)
 		// Requeue to continue with installation after finalizer is added
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	// build values yaml content for Slurm Chart
-	chartValues := utils.BuildSlurmValues(&release.Spec.Values)
+	// SlurmDeployments created before the operator defaults carry the former CRD defaults, which would hide them
+	reconciledBefore := release.Status.ObservedGeneration > 0
+	if defaults.Migrate(release) {
+		if updateErr := r.Update(ctx, release); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		if reconciledBefore {
+			log.Printf("Cleared the former CRD defaults of SlurmDeployment %s", release.Name)
+			r.recordEvent(release, corev1.EventTypeNormal, ReasonDefaultsMigrated,
+				"Cleared the image fields and resources preset still holding the former CRD defaults, they follow the operator defaults now")
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	if release.Spec.Suspend {
+		if release.Status.ClusterStatus != ClusterStatusSuspended {
+			log.Printf("SlurmDeployment %s is suspended, skipping helm actions", release.Name)
+			release.Status.ClusterStatus = ClusterStatusSuspended
+			if updateStatusErr := r.Status().Update(ctx, release); updateStatusErr != nil {
+				return ctrl.Result{}, updateStatusErr
+			}
+			r.recordEvent(release, corev1.EventTypeNormal, ReasonSuspended, "Suspended, no helm actions or slurmctld restarts until spec.suspend is cleared")
+		}
+		return ctrl.Result{}, nil
+	}
+	if release.Status.ClusterStatus == ClusterStatusSuspended {
+		release.Status.ClusterStatus = ""
+	}
+
+	// helm would scale the components back up, so it is skipped while hibernated or resuming
+	if handled, hibernationResult, hibernationErr := r.ReconcileHibernation(ctx, release); handled {
+		return hibernationResult, hibernationErr
+	}
+
+	// a restore stops slurmctld while it writes the state back, helm would start it too early
+	if restoring, restoreErr := restoreInProgress(ctx, r.Client, release); restoreErr != nil {
+		return ctrl.Result{}, restoreErr
+	} else if restoring {
+		log.Printf("Skipping %s while a SlurmRestore is in progress", release.Name)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	// the defaults are only applied in memory, a SlurmDeployment follows them as they change
+	defaults.Apply(release, r.Defaults.Get())
+
+	dryRun := release.Spec.ReconcileMode == slurmv1.ReconcileModeDryRun
+	if !dryRun {
+		// the diff of a former DryRun pass holds nothing once the values are applied
+		if deleteErr := r.DeleteDryRun(ctx, release); deleteErr != nil {
+			return ctrl.Result{}, deleteErr
+		}
+	}
 
 	// Check release if exists
 	histClient := action.NewHistory(actionConfig)
-	slurmChart := utils.DownloadChart(release.Spec.Chart.Name, release.Spec.Chart.Repository, release.Spec.Chart.Version)
-	if _, getHistoryErr := histClient.Run(release.Name); getHistoryErr == nil {
-		// upgrade release
-		upgradeClient := action.NewUpgrade(actionConfig)
-		upgradeClient.Namespace = release.Spec.Chart.Namespace
-
-		if _, upgradeError := upgradeClient.Run(release.Name, slurmChart, chartValues); upgradeError != nil {
-			log.Printf("Failed to upgrade release %s in namespace [%s]: %v", release.Name, release.Spec.Chart.Namespace, upgradeError)
-			return ctrl.Result{}, upgradeError
-		} else {
-			if _, updateStatusErr := r.UpdateReleaseStatus(ctx, release); updateStatusErr != nil {
+	history, getHistoryErr := histClient.Run(release.Name)
+
+	// pick the ClusterName and the slurmdbd the cluster reports to
+	if accountingErr := r.applyAccountingDefaults(ctx, release, getHistoryErr == nil); accountingErr != nil {
+		return ctrl.Result{}, accountingErr
+	}
+
+	// enable the scheduling plugins required by the SlurmQOS objects of this release when asked to enforce them
+	if applyQOSErr := applyQOSSchedulingDefaults(ctx, r.Client, release); applyQOSErr != nil {
+		return ctrl.Result{}, applyQOSErr
+	}
+
+	// a Slurm version change is rolled out one component group at a time
+	values := &release.Spec.Values
+	if !dryRun {
+		upgradeValues, upgradeWait, upgradeErr := r.PrepareVersionUpgrade(ctx, release)
+		if upgradeErr != nil {
+			return ctrl.Result{}, upgradeErr
+		}
+		if upgradeWait > 0 {
+			if updateStatusErr := r.Status().Update(ctx, release); updateStatusErr != nil {
+				log.Printf("Failed to update status: %v", updateStatusErr)
 				return ctrl.Result{}, updateStatusErr
 			}
+			return ctrl.Result{RequeueAfter: upgradeWait}, nil
+		}
+		values = upgradeValues
+	}
+
+	// build values yaml content for Slurm Chart
+	chartValues := utils.BuildSlurmValues(values)
+	// shared volumes, probes and podTemplate overrides are merged onto the rendered workloads, the chart has no values for them
+	postRenderer, postRendererErr := utils.ReleasePostRenderer(release, values)
+	if postRendererErr != nil {
+		r.recordEvent(release, corev1.EventTypeWarning, ReasonInvalidPodTemplate, "%v", postRendererErr)
+		return ctrl.Result{}, postRendererErr
+	}
+
+	valuesHash, hashErr := utils.ReleaseValuesHash(release.Spec.Chart, chartValues, values)
+	if hashErr != nil {
+		return ctrl.Result{}, hashErr
+	}
+	if dryRun {
+		if release.Status.DryRun != nil && release.Status.DryRun.ValuesHash == valuesHash {
+			// the diff of these values is already rendered, whether they changed through the spec or the defaults
+			return ctrl.Result{}, nil
+		}
+		slurmChart, downloadErr := r.downloadChart(release)
+		if downloadErr != nil {
+			return ctrl.Result{}, downloadErr
+		}
+		return r.ReconcileDryRun(ctx, actionConfig, release, slurmChart, chartValues, postRenderer, valuesHash)
+	}
+	if getHistoryErr == nil {
+		if volumesErr := r.ReconcileSharedVolumes(ctx, release, values); volumesErr != nil {
+			r.recordEvent(release, corev1.EventTypeWarning, ReasonSharedVolumesFailed, "Failed to apply shared volumes: %v", volumesErr)
+			return ctrl.Result{}, volumesErr
+		}
+		if releaseUpToDate(release, history, valuesHash) {
+			// the periodic requeues of maintenance, topology and features land here and never write a helm revision
+			log.Printf("Release %s already runs generation %d with the same values, skipping upgrade", release.Name, release.Generation)
+		} else {
+			slurmChart, downloadErr := r.downloadChart(release)
+			if downloadErr != nil {
+				return ctrl.Result{}, downloadErr
+			}
+			// upgrade release
+			upgradeClient := action.NewUpgrade(actionConfig)
+			upgradeClient.Namespace = release.Spec.Chart.Namespace
+			upgradeClient.PostRenderer = postRenderer
+			utils.ApplyUpgradePolicy(upgradeClient, release.Spec.Chart.UpgradePolicy)
+
+			// a failed atomic upgrade restores the revision deployed before it, under a new revision number
+			rollbackRevision := utils.DeployedRevision(history)
+			upgradeStart := time.Now()
+			_, upgradeError := upgradeClient.Run(release.Name, slurmChart, chartValues)
+			metrics.ObserveHelmAction(release.Namespace, release.Name, metrics.HelmActionUpgrade, upgradeStart, upgradeError)
+			if upgradeError != nil {
+				log.Printf("Failed to upgrade release %s in namespace [%s]: %v", release.Name, release.Spec.Chart.Namespace, upgradeError)
+				r.recordEvent(release, corev1.EventTypeWarning, ReasonUpgradeFailed, "Failed to upgrade release %s: %v", release.Name, upgradeError)
+				r.updateReleaseRevisions(actionConfig, release)
+				if release.Spec.Chart.UpgradePolicy.Atomic && strings.Contains(upgradeError.Error(), "has been rolled back") {
+					r.recordEvent(release, corev1.EventTypeWarning, ReasonRolledBack, "Rolled release %s back to revision %d", release.Name, rollbackRevision)
+				}
+				// the running cluster can still be drained or resumed while its chart fails to upgrade
+				if _, maintenanceErr := r.ReconcileMaintenance(ctx, release); maintenanceErr != nil {
+					log.Printf("Failed to reconcile maintenance of %s: %v", release.Name, maintenanceErr)
+				}
+				if updateStatusErr := r.Status().Update(ctx, release); updateStatusErr != nil {
+					log.Printf("Failed to update status: %v", updateStatusErr)
+				}
+				return ctrl.Result{}, upgradeError
+			}
+			r.recordEvent(release, corev1.EventTypeNormal, ReasonUpgraded, "Upgraded release %s to chart %s version %s", release.Name, release.Spec.Chart.Name, release.Spec.Chart.Version)
+		}
+		if exporterErr := r.ReconcileSlurmExporter(ctx, release); exporterErr != nil {
+			r.recordEvent(release, corev1.EventTypeWarning, ReasonExporterFailed, "Failed to apply slurm exporter: %v", exporterErr)
+			return ctrl.Result{}, exporterErr
 		}
-		return ctrl.Result{}, getHistoryErr
+		if terminalErr := r.ReconcileWebTerminal(ctx, release); terminalErr != nil {
+			r.recordEvent(release, corev1.EventTypeWarning, ReasonWebTerminalFailed, "Failed to apply web terminal: %v", terminalErr)
+			return ctrl.Result{}, terminalErr
+		}
+		r.updateReleaseRevisions(actionConfig, release)
+		release.Status.AppliedImages = utils.SlurmComponentImages(values)
+		release.Status.ObservedGeneration, release.Status.AppliedValuesHash = release.Generation, valuesHash
+		return r.UpdateReleaseStatus(ctx, release)
 	} else {
 		log.Printf("Cannot find release %s in namespace [%s] : %v", release.Name, release.Spec.Chart.Namespace, getHistoryErr)
+		// check what the chart depends on instead of leaving pods pending
+		if passed, preflightErr := r.RunPreflight(ctx, release, values); preflightErr != nil {
+			return ctrl.Result{}, preflightErr
+		} else if !passed {
+			if updateStatusErr := r.Status().Update(ctx, release); updateStatusErr != nil {
+				log.Printf("Failed to update status: %v", updateStatusErr)
+				return ctrl.Result{}, updateStatusErr
+			}
+			return ctrl.Result{RequeueAfter: preflightRequeue}, nil
+		}
+		if volumesErr := r.ReconcileSharedVolumes(ctx, release, values); volumesErr != nil {
+			r.recordEvent(release, corev1.EventTypeWarning, ReasonSharedVolumesFailed, "Failed to apply shared volumes: %v", volumesErr)
+			return ctrl.Result{}, volumesErr
+		}
+		slurmChart, downloadErr := r.downloadChart(release)
+		if downloadErr != nil {
+			return ctrl.Result{}, downloadErr
+		}
 		// install a new release
 		installClient := action.NewInstall(actionConfig)
 		installClient.ReleaseName = release.Name
 		installClient.Namespace = release.Spec.Chart.Namespace
+		installClient.PostRenderer = postRenderer
+		utils.ApplyInstallPolicy(installClient, release.Spec.Chart.UpgradePolicy)
 
-		if _, installErr := installClient.Run(slurmChart, chartValues); installErr != nil {
+		installStart := time.Now()
+		_, installErr := installClient.Run(slurmChart, chartValues)
+		metrics.ObserveHelmAction(release.Namespace, release.Name, metrics.HelmActionInstall, installStart, installErr)
+		if installErr != nil {
 			log.Printf("Failed to install release %s in namespace [%s]: %v", release.Name, release.Spec.Chart.Namespace, installErr)
+			r.recordEvent(release, corev1.EventTypeWarning, ReasonInstallFailed, "Failed to install release %s: %v", release.Name, installErr)
 			return ctrl.Result{}, installErr
 		} else {
-			if _, updateStatusErr := r.UpdateReleaseStatus(ctx, release); updateStatusErr != nil {
-				return ctrl.Result{}, updateStatusErr
+			r.recordEvent(release, corev1.EventTypeNormal, ReasonInstalled, "Installed release %s with chart %s version %s", release.Name, release.Spec.Chart.Name, release.Spec.Chart.Version)
+			if exporterErr := r.ReconcileSlurmExporter(ctx, release); exporterErr != nil {
+				r.recordEvent(release, corev1.EventTypeWarning, ReasonExporterFailed, "Failed to apply slurm exporter: %v", exporterErr)
+				return ctrl.Result{}, exporterErr
+			}
+			if terminalErr := r.ReconcileWebTerminal(ctx, release); terminalErr != nil {
+				r.recordEvent(release, corev1.EventTypeWarning, ReasonWebTerminalFailed, "Failed to apply web terminal: %v", terminalErr)
+				return ctrl.Result{}, terminalErr
 			}
+			r.updateReleaseRevisions(actionConfig, release)
+			release.Status.AppliedImages = utils.SlurmComponentImages(values)
+			release.Status.ObservedGeneration, release.Status.AppliedValuesHash = release.Generation, valuesHash
+			return r.UpdateReleaseStatus(ctx, release)
 		}
 	}
+}
 
-	return ctrl.Result{}, nil
+// downloadChart fetches the chart of a SlurmDeployment, a failure is recorded as an event
+func (r *SlurmDeploymentReconciler) downloadChart(release *slurmv1.SlurmDeployment) (*chart.Chart, error) {
+	downloadStart := time.Now()
+	slurmChart := utils.DownloadChart(release.Spec.Chart.Name, release.Spec.Chart.Repository, release.Spec.Chart.Version)
+	metrics.ObserveChartDownload(release.Spec.Chart.Name, release.Spec.Chart.Version, downloadStart, slurmChart != nil)
+	if slurmChart == nil {
+		downloadErr := fmt.Errorf("failed to download chart %s version %s from %s", release.Spec.Chart.Name, release.Spec.Chart.Version, release.Spec.Chart.Repository)
+		r.recordEvent(release, corev1.EventTypeWarning, ReasonChartDownloadFailed, "%v", downloadErr)
+		return nil, downloadErr
+	}
+	return slurmChart, nil
+}
+
+// releaseUpToDate tells whether the latest helm revision deployed this generation with the same values, an
+// upgrade would only write another revision then
+func releaseUpToDate(release *slurmv1.SlurmDeployment, history []*helmrelease.Release, valuesHash string) bool {
+	if release.Status.ObservedGeneration != release.Generation || release.Status.AppliedValuesHash != valuesHash {
+		return false
+	}
+	var latest *helmrelease.Release
+	for _, revision := range history {
+		if latest == nil || revision.Version > latest.Version {
+			latest = revision
+		}
+	}
+	return latest != nil && latest.Info != nil && latest.Info.Status == helmrelease.StatusDeployed
 }
 
 // UpdateReleaseStatus updates the SlurmDeployment status with node counts and saves to Kubernetes
@@ -192,10 +459,10 @@ func (r *SlurmDeploymentReconciler) UpdateReleaseStatus(ctx context.Context, rel
 	if cpuSTS, cpuSTSErr := r.RetrieveStatefulSetInfo(ctx, release.Spec.Chart.Namespace,
 		fmt.Sprintf("%s-%s-%s", release.Name, release.Spec.Chart.Name, "slurmd-cpu")); cpuSTSErr == nil {
 		release.Status.CPUNodeCount = fmt.Sprintf("%d/%d", cpuSTS.Status.ReadyReplicas, cpuSTS.Status.Replicas)
-		if release.Status.CPUNodeStsVersion != cpuSTS.ResourceVersion {
-			needRestartSlurmctldFlag = true
-			release.Status.CPUNodeStsVersion = cpuSTS.ResourceVersion
-		}
+		metrics.SetComponentReplicas(release.Namespace, release.Name, "slurmd-cpu", cpuSTS.Status.ReadyReplicas, cpuSTS.Status.Replicas)
+		version, changed := slurmdStsChanged(release.Status.CPUNodeStsVersion, &cpuSTS)
+		needRestartSlurmctldFlag = needRestartSlurmctldFlag || changed
+		release.Status.CPUNodeStsVersion = version
 	} else {
 		log.Printf("Error retrieving CPU Node StatefulSet: %v", cpuSTSErr)
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, cpuSTSErr
@@ -204,10 +471,10 @@ func (r *SlurmDeploymentReconciler) UpdateReleaseStatus(ctx context.Context, rel
 	if gpuSTS, gpuSTSErr := r.RetrieveStatefulSetInfo(ctx, release.Spec.Chart.Namespace,
 		fmt.Sprintf("%s-%s-%s", release.Name, release.Spec.Chart.Name, "slurmd-gpu")); gpuSTSErr == nil {
 		release.Status.GPUNodeCount = fmt.Sprintf("%d/%d", gpuSTS.Status.ReadyReplicas, gpuSTS.Status.Replicas)
-		if release.Status.GPUNodeStsVersion != gpuSTS.ResourceVersion {
-			needRestartSlurmctldFlag = true
-			release.Status.GPUNodeStsVersion = gpuSTS.ResourceVersion
-		}
+		metrics.SetComponentReplicas(release.Namespace, release.Name, "slurmd-gpu", gpuSTS.Status.ReadyReplicas, gpuSTS.Status.Replicas)
+		version, changed := slurmdStsChanged(release.Status.GPUNodeStsVersion, &gpuSTS)
+		needRestartSlurmctldFlag = needRestartSlurmctldFlag || changed
+		release.Status.GPUNodeStsVersion = version
 	} else {
 		log.Printf("Error retrieving GPU Node StatefulSet: %v", gpuSTSErr)
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, gpuSTSErr
@@ -216,6 +483,7 @@ func (r *SlurmDeploymentReconciler) UpdateReleaseStatus(ctx context.Context, rel
 	if controldSTS, controldSTSErr := r.RetrieveStatefulSetInfo(ctx, release.Spec.Chart.Namespace,
 		fmt.Sprintf("%s-%s-%s", release.Name, release.Spec.Chart.Name, "slurmctld")); controldSTSErr == nil {
 		release.Status.ControldDeamonCount = fmt.Sprintf("%d/%d", controldSTS.Status.ReadyReplicas, controldSTS.Status.Replicas)
+		metrics.SetComponentReplicas(release.Namespace, release.Name, "slurmctld", controldSTS.Status.ReadyReplicas, controldSTS.Status.Replicas)
 		slurmctldSTSLabels = controldSTS.Spec.Selector.MatchLabels
 	} else {
 		log.Printf("Error retrieving control deamon StatefulSet: %v", controldSTSErr)
@@ -225,14 +493,19 @@ func (r *SlurmDeploymentReconciler) UpdateReleaseStatus(ctx context.Context, rel
 	if databasedSTS, databasedSTSErr := r.RetrieveStatefulSetInfo(ctx, release.Spec.Chart.Namespace,
 		fmt.Sprintf("%s-%s-%s", release.Name, release.Spec.Chart.Name, "slurmdbd")); databasedSTSErr == nil {
 		release.Status.DatabaseDeamonCount = fmt.Sprintf("%d/%d", databasedSTS.Status.ReadyReplicas, databasedSTS.Status.Replicas)
+		metrics.SetComponentReplicas(release.Namespace, release.Name, "slurmdbd", databasedSTS.Status.ReadyReplicas, databasedSTS.Status.Replicas)
 	} else {
 		log.Printf("Error retrieving database deamon StatefulSet: %v", databasedSTSErr)
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, databasedSTSErr
 	}
 
-	if mariadbSTS, mariadbSTSErr := r.RetrieveStatefulSetInfo(ctx, release.Spec.Chart.Namespace,
+	if usesSharedSlurmdbd(release) {
+		// MariaDB belongs to the release providing slurmdbd
+		release.Status.MariadbServiceCount = "shared"
+	} else if mariadbSTS, mariadbSTSErr := r.RetrieveStatefulSetInfo(ctx, release.Spec.Chart.Namespace,
 		fmt.Sprintf("%s-%s", release.Name, "mariadb")); mariadbSTSErr == nil {
 		release.Status.MariadbServiceCount = fmt.Sprintf("%d/%d", mariadbSTS.Status.ReadyReplicas, mariadbSTS.Status.Replicas)
+		metrics.SetComponentReplicas(release.Namespace, release.Name, "mariadb", mariadbSTS.Status.ReadyReplicas, mariadbSTS.Status.Replicas)
 	} else {
 		log.Printf("Error retrieving MariaDB StatefulSet: %v", mariadbSTSErr)
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, mariadbSTSErr
@@ -241,14 +514,42 @@ func (r *SlurmDeploymentReconciler) UpdateReleaseStatus(ctx context.Context, rel
 	if loginNodeDeploy, loginNodeDeployErr := r.RetrieveDeployInfo(ctx, release.Spec.Chart.Namespace,
 		fmt.Sprintf("%s-%s-%s", release.Name, release.Spec.Chart.Name, "login")); loginNodeDeployErr == nil {
 		release.Status.LoginNodeCount = fmt.Sprintf("%d/%d", loginNodeDeploy.Status.AvailableReplicas, loginNodeDeploy.Status.Replicas)
+		metrics.SetComponentReplicas(release.Namespace, release.Name, "login", loginNodeDeploy.Status.AvailableReplicas, loginNodeDeploy.Status.Replicas)
 	} else {
 		log.Printf("Error retrieving login Node Deployment: %v", loginNodeDeployErr)
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, loginNodeDeployErr
 	}
 
+	// Resolve where SSH clients reach the login nodes, a load balancer may still be assigning an address
+	loginRequeue := r.ReconcileLoginEndpoint(ctx, release)
+
 	// Show the command
 	release.Status.JobCommand = strings.Join(append(release.Spec.Job.Command, release.Spec.Job.Args...), " ")
 
+	// Export slurm node states, the cluster may still be starting so failures are not fatal
+	if r.Executor != nil {
+		if nodeStates, sinfoErr := utils.QuerySlurmNodeStates(ctx, r.Executor, release); sinfoErr == nil {
+			metrics.SetSlurmNodeStates(release.Namespace, release.Name, nodeStates)
+		} else {
+			log.Printf("Failed to query slurm node states of %s: %v", release.Name, sinfoErr)
+		}
+	}
+
+	// Register the cluster in the accounting database, failures are kept in a condition
+	r.registerSlurmCluster(ctx, release)
+
+	// Keep topology.conf in line with the nodes the slurmd pods run on, failures are kept in the status
+	topologyRequeue := r.ReconcileTopology(ctx, release)
+
+	// Push the features resolved from kubernetes node labels, slurmd may still be registering
+	featuresRequeue := r.ReconcileNodeFeatures(ctx, release)
+
+	// Drain or resume partitions, the status is saved below
+	maintenanceRequeue, maintenanceErr := r.ReconcileMaintenance(ctx, release)
+	if maintenanceErr != nil {
+		return ctrl.Result{}, maintenanceErr
+	}
+
 	// Update the status in Kubernetes
 	if updateStatusErr := r.Status().Update(ctx, release); updateStatusErr != nil {
 		log.Printf("Failed to update status: %v", updateStatusErr)
@@ -269,14 +570,54 @@ func (r *SlurmDeploymentReconciler) UpdateReleaseStatus(ctx context.Context, rel
 			if err := r.Delete(ctx, &pod); err != nil {
 				if !apierrors.IsNotFound(err) {
 					log.Printf("Failed to delete pod %s: %v", pod.Name, err)
+					r.recordEvent(release, corev1.EventTypeWarning, ReasonSlurmctldRestartFailed, "Failed to delete slurmctld pod %s: %v", pod.Name, err)
 					return ctrl.Result{RequeueAfter: 5 * time.Second}, err
 				}
 			} else {
 				log.Printf("Deleted pod %s to restart slurmctld", pod.Name)
+				metrics.SlurmctldRestartsTotal.WithLabelValues(release.Namespace, release.Name).Inc()
+				r.recordEvent(release, corev1.EventTypeNormal, ReasonSlurmctldRestarted, "Deleted slurmctld pod %s to pick up slurmd changes", pod.Name)
 			}
 		}
 	}
-	return ctrl.Result{}, nil
+	requeueAfter := maintenanceRequeue
+	for _, requeue := range []time.Duration{topologyRequeue, featuresRequeue, loginRequeue} {
+		if requeue > 0 && (requeueAfter == 0 || requeue < requeueAfter) {
+			requeueAfter = requeue
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// slurmdStsVersionPrefix marks a slurmd StatefulSet version recorded as its generation
+const slurmdStsVersionPrefix = "generation-"
+
+// slurmdStsChanged returns the version to record for a slurmd StatefulSet and whether its spec changed since the
+// recorded one. The generation only moves with the spec, a status update such as pods turning ready does not restart
+// slurmctld. A version recorded as a resourceVersion by an older operator, or none at all on install, is replaced
+// without a restart.
+func slurmdStsChanged(recorded string, sts *appsv1.StatefulSet) (string, bool) {
+	version := fmt.Sprintf("%s%d", slurmdStsVersionPrefix, sts.Generation)
+	return version, recorded != version && strings.HasPrefix(recorded, slurmdStsVersionPrefix)
+}
+
+// updateReleaseRevisions copies the current and last successful helm revisions into the status, it does not save it
+func (r *SlurmDeploymentReconciler) updateReleaseRevisions(actionConfig *action.Configuration, release *slurmv1.SlurmDeployment) {
+	current, lastSuccessful, historyErr := utils.ReleaseRevisions(actionConfig, release.Name)
+	if historyErr != nil {
+		log.Printf("Failed to read history of release %s: %v", release.Name, historyErr)
+		return
+	}
+	release.Status.CurrentRevision = current
+	release.Status.LastSuccessfulRevision = lastSuccessful
+}
+
+// recordEvent emits an event on the SlurmDeployment when a recorder is configured
+func (r *SlurmDeploymentReconciler) recordEvent(release *slurmv1.SlurmDeployment, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(release, eventType, reason, messageFmt, args...)
 }
 
 func (r *SlurmDeploymentReconciler) CreateNamespaceIfNotExist(ctx context.Context, namespace string) (ctrl.Result, error) {
@@ -332,10 +673,33 @@ func (r *SlurmDeploymentReconciler) RetrieveDeployInfo(ctx context.Context, name
 // SetupWithManager sets up the controller with the Manager.
 // Helper functions to check and remove string from a slice of strings.
 func (r *SlurmDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr)
+	if r.Defaults != nil {
+		controllerBuilder = controllerBuilder.WatchesRawSource(source.Channel(r.Defaults.Changes(),
+			handler.TypedEnqueueRequestsFromMapFunc(r.slurmDeploymentsForDefaults)))
+	}
+	return controllerBuilder.
 		For(&slurmv1.SlurmDeployment{}).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Pod{}).
+		Watches(&slurmv1.SlurmQOS{}, handler.EnqueueRequestsFromMapFunc(slurmDeploymentForQOS),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.slurmDeploymentForSlurmPod),
+			builder.WithPredicates(slurmPodScheduled)).
 		Complete(r)
 }
+
+// slurmDeploymentsForDefaults reconciles every SlurmDeployment once the operator defaults changed
+func (r *SlurmDeploymentReconciler) slurmDeploymentsForDefaults(ctx context.Context, _ *defaults.Defaults) []reconcile.Request {
+	releases := &slurmv1.SlurmDeploymentList{}
+	if err := r.List(ctx, releases); err != nil {
+		log.Printf("Failed to list SlurmDeployments after the operator defaults changed: %v", err)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(releases.Items))
+	for _, release := range releases.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: release.Namespace, Name: release.Name}})
+	}
+	return requests
+}
@@ -18,16 +18,30 @@ package controller
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
 )
 
 var _ = Describe("SlurmDeployment Controller", func() {
@@ -68,17 +82,812 @@ var _ = Describe("SlurmDeployment Controller", func() {
 		})
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
+			recorder := record.NewFakeRecorder(32)
 			controllerReconciler := &SlurmDeploymentReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+
+			By("recording the lifecycle events")
+			events := []string{}
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			Expect(events).To(ContainElement(HavePrefix(corev1.EventTypeNormal + " " + ReasonFinalizerAdded + " ")))
+		})
+	})
+})
+
+var _ = Describe("slurmd StatefulSet versions", func() {
+	sts := func(generation int64, resourceVersion string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Generation: generation, ResourceVersion: resourceVersion}}
+	}
+
+	It("restarts slurmctld only when the spec of a slurmd StatefulSet changed", func() {
+		version, changed := slurmdStsChanged("generation-3", sts(3, "1001"))
+		Expect(version).To(Equal("generation-3"))
+		Expect(changed).To(BeFalse(), "a status update keeps the generation")
+
+		version, changed = slurmdStsChanged(version, sts(4, "1002"))
+		Expect(version).To(Equal("generation-4"))
+		Expect(changed).To(BeTrue())
+	})
+
+	It("records the generation without a restart on install or after an operator upgrade", func() {
+		_, changed := slurmdStsChanged("", sts(1, "10"))
+		Expect(changed).To(BeFalse())
+		version, changed := slurmdStsChanged("48213", sts(2, "48290"))
+		Expect(version).To(Equal("generation-2"))
+		Expect(changed).To(BeFalse())
+	})
+})
+
+var _ = Describe("SlurmDeployment maintenance", func() {
+	const sinfoPartitions = "sinfo --noheader --format=%R|%a"
+
+	var (
+		executor   *fakePodExecutor
+		reconciler *SlurmDeploymentReconciler
+		release    *slurmv1.SlurmDeployment
+	)
+
+	BeforeEach(func() {
+		executor = &fakePodExecutor{outputs: map[string]string{
+			sinfoPartitions: "cpu|up\ncpu|up\ndebug|drain\n",
+		}}
+		reconciler = &SlurmDeploymentReconciler{Executor: executor, Recorder: record.NewFakeRecorder(32)}
+		release = &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"}},
+		}
+		release.Spec.Maintenance = slurmv1.MaintenanceSpec{Enabled: true, PartitionState: "DRAIN"}
+	})
+
+	It("restores the state every partition had before the maintenance", func() {
+		_, err := reconciler.ReconcileMaintenance(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(ContainElements(
+			"scontrol update PartitionName=cpu State=DRAIN",
+			"scontrol update PartitionName=debug State=DRAIN",
+		))
+		Expect(release.Status.Maintenance.Phase).To(Equal(slurmv1.MaintenancePhaseDrained))
+		Expect(release.Status.Maintenance.PriorStates).To(Equal(map[string]string{"cpu": "UP", "debug": "DRAIN"}))
+
+		By("keeping the recorded states once the partitions are drained")
+		executor.outputs[sinfoPartitions] = "cpu|drain\ndebug|drain\n"
+		_, err = reconciler.ReconcileMaintenance(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(release.Status.Maintenance.PriorStates).To(Equal(map[string]string{"cpu": "UP", "debug": "DRAIN"}))
+		executor.ran()
+
+		release.Spec.Maintenance.Enabled = false
+		_, err = reconciler.ReconcileMaintenance(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(Equal([]string{
+			"scontrol update PartitionName=cpu State=UP",
+			"scontrol update PartitionName=debug State=DRAIN",
+		}))
+		Expect(release.Status.Maintenance).To(BeNil())
+	})
+
+	It("resumes partitions recorded without a prior state", func() {
+		release.Spec.Maintenance.Enabled = false
+		release.Status.Maintenance = &slurmv1.MaintenanceStatus{Partitions: []string{"cpu"}}
+		_, err := reconciler.ReconcileMaintenance(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(Equal([]string{"scontrol update PartitionName=cpu State=UP"}))
+	})
+})
+
+var _ = Describe("helm upgrades", func() {
+	release := func(generation int64) *slurmv1.SlurmDeployment {
+		return &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: generation},
+			Status:     slurmv1.SlurmDeploymentStatus{ObservedGeneration: 2, AppliedValuesHash: "abc"},
+		}
+	}
+	revision := func(version int, status helmrelease.Status) *helmrelease.Release {
+		return &helmrelease.Release{Version: version, Info: &helmrelease.Info{Status: status}}
+	}
+
+	It("skips the upgrade while the deployed revision has the same generation and values", func() {
+		history := []*helmrelease.Release{revision(1, helmrelease.StatusSuperseded), revision(2, helmrelease.StatusDeployed)}
+		Expect(releaseUpToDate(release(2), history, "abc")).To(BeTrue())
+		Expect(releaseUpToDate(release(3), history, "abc")).To(BeFalse(), "the generation changed")
+		Expect(releaseUpToDate(release(2), history, "def")).To(BeFalse(), "the values changed")
+	})
+
+	It("upgrades again when the latest revision failed", func() {
+		history := []*helmrelease.Release{revision(2, helmrelease.StatusDeployed), revision(3, helmrelease.StatusFailed)}
+		Expect(releaseUpToDate(release(2), history, "abc")).To(BeFalse())
+	})
+})
+
+var _ = Describe("SlurmDeployment hibernation", func() {
+	var (
+		recorder *record.FakeRecorder
+		release  *slurmv1.SlurmDeployment
+	)
+
+	slurmctldSts := func(stateVolume corev1.VolumeSource) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-slurm-slurmctld", Namespace: "slurm"},
+			Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:         utils.SlurmctldContainerName,
+					VolumeMounts: []corev1.VolumeMount{{Name: "state", MountPath: utils.SlurmctldStateMountPath}},
+				}},
+				Volumes: []corev1.Volume{{Name: "state", VolumeSource: stateVolume}},
+			}}},
+		}
+	}
+
+	newReconciler := func(objects ...client.Object) *SlurmDeploymentReconciler {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objects, release)...).
+			WithStatusSubresource(release).Build()
+		return &SlurmDeploymentReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(32)
+		release = &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Spec: slurmv1.SlurmDeploymentSpec{
+				Chart:     slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"},
+				Hibernate: true,
+			},
+		}
+		release.Spec.Values.Mariadb.Primary.Persistence.Enabled = true
+	})
+
+	It("refuses to hibernate while MariaDB or the slurmctld state is not on a persistent volume", func() {
+		release.Spec.Values.Mariadb.Primary.Persistence.Enabled = false
+		reconciler := newReconciler(slurmctldSts(corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}))
+
+		handled, _, err := reconciler.ReconcileHibernation(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handled).To(BeFalse(), "the cluster keeps running")
+		Expect(release.Status.Hibernation).To(BeNil())
+		condition := meta.FindStatusCondition(release.Status.Conditions, slurmv1.ConditionHibernationBlocked)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("mariadb.primary.persistence"))
+		Expect(condition.Message).To(ContainSubstring("not stored on a persistent volume"))
+		Expect(recorder.Events).To(Receive(ContainSubstring(ReasonHibernationRefused)))
+	})
+
+	It("hibernates when every stateful component is on a persistent volume", func() {
+		reconciler := newReconciler(slurmctldSts(corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "cluster-slurm-slurmctld-state"},
+		}))
+
+		handled, _, err := reconciler.ReconcileHibernation(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handled).To(BeTrue())
+		Expect(release.Status.Hibernation).NotTo(BeNil())
+		Expect(meta.IsStatusConditionFalse(release.Status.Conditions, slurmv1.ConditionHibernationBlocked)).To(BeTrue())
+	})
+
+	It("refuses to hibernate while other releases report to its slurmdbd", func() {
+		team := &slurmv1.SlurmDeployment{ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "default"}}
+		team.Spec.Values.SlurmConfig.Accounting.SlurmdbdRef = &slurmv1.SlurmDeploymentReference{Name: "cluster"}
+		reconciler := newReconciler(team, slurmctldSts(corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "cluster-slurm-slurmctld-state"},
+		}))
+
+		handled, _, err := reconciler.ReconcileHibernation(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handled).To(BeFalse(), "the cluster keeps running")
+		Expect(release.Status.Hibernation).To(BeNil())
+		condition := meta.FindStatusCondition(release.Status.Conditions, slurmv1.ConditionHibernationBlocked)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal("SharedSlurmdbd"))
+		Expect(condition.Message).To(ContainSubstring("default/team"))
+		Expect(recorder.Events).To(Receive(ContainSubstring(ReasonHibernationRefused)))
+	})
+})
+
+var _ = Describe("SlurmDeployment topology", func() {
+	var (
+		executor   *fakePodExecutor
+		release    *slurmv1.SlurmDeployment
+		reconciler *SlurmDeploymentReconciler
+	)
+
+	readTopologyConf := "sh -c cat " + utils.TopologyConfPath + " 2>/dev/null || true"
+
+	BeforeEach(func() {
+		executor = &fakePodExecutor{outputs: map[string]string{}}
+		release = &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"}},
+		}
+		release.Spec.Values.SlurmConfig.Topology.Enabled = true
+		labels := map[string]string{"app.kubernetes.io/component": "slurmd-cpu"}
+		objects := []client.Object{
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{utils.DefaultTopologyZoneLabel: "zone-a"}}},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-slurm-slurmd-cpu-0", Namespace: "slurm", Labels: labels},
+				Spec:       corev1.PodSpec{NodeName: "node-a"},
+			},
+		}
+		for _, component := range []string{"slurmd-cpu", "slurmd-gpu"} {
+			objects = append(objects, &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: utils.ComponentName(release, component), Namespace: "slurm"},
+				Spec: appsv1.StatefulSetSpec{Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app.kubernetes.io/component": component},
+				}},
+			})
+		}
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		reconciler = &SlurmDeploymentReconciler{
+			Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+			Scheme:   scheme,
+			Recorder: record.NewFakeRecorder(32),
+			Executor: executor,
+		}
+	})
+
+	It("keeps topology.conf in a ConfigMap and reconfigures slurmctld once it sees the file", func() {
+		ctx := context.Background()
+		Expect(reconciler.ReconcileTopology(ctx, release)).To(Equal(topologyRetryInterval))
+		configMap := &corev1.ConfigMap{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Namespace: "slurm", Name: utils.TopologyConfMapName(release)}, configMap)).To(Succeed())
+		content := configMap.Data["topology.conf"]
+		Expect(content).To(Equal("SwitchName=zone-a Nodes=cluster-slurm-slurmd-cpu-0\n"))
+		Expect(executor.ran()).To(Equal([]string{readTopologyConf}), "no reconfigure before the kubelet synced the file")
+		Expect(release.Status.Topology.Hash).To(BeEmpty())
+
+		executor.outputs[readTopologyConf] = content
+		Expect(reconciler.ReconcileTopology(ctx, release)).To(BeZero())
+		Expect(executor.ran()).To(Equal([]string{readTopologyConf, "scontrol reconfigure"}))
+		Expect(release.Status.Topology.Hash).To(Equal(utils.TopologyConfHash(content)))
+
+		// a restarted slurmctld reads the same ConfigMap, nothing is pushed again
+		Expect(reconciler.ReconcileTopology(ctx, release)).To(BeZero())
+		Expect(executor.ran()).To(BeEmpty())
+	})
+
+	It("removes the ConfigMap once the topology is disabled", func() {
+		ctx := context.Background()
+		reconciler.ReconcileTopology(ctx, release)
+		release.Spec.Values.SlurmConfig.Topology.Enabled = false
+		Expect(reconciler.ReconcileTopology(ctx, release)).To(BeZero())
+		Expect(release.Status.Topology).To(BeNil())
+		err := reconciler.Get(ctx, types.NamespacedName{Namespace: "slurm", Name: utils.TopologyConfMapName(release)}, &corev1.ConfigMap{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+})
+
+var _ = Describe("SlurmDeployment dry run", func() {
+	It("removes the diff ConfigMap and the status once the SlurmDeployment applies again", func() {
+		ctx := context.Background()
+		release := &slurmv1.SlurmDeployment{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"}}
+		release.Status.DryRun = &slurmv1.DryRunStatus{DiffConfigMap: dryRunDiffName(release)}
+		diff := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: dryRunDiffName(release), Namespace: "default"},
+			Data:       map[string]string{"summary": "0 added, 0 removed, 1 changed"},
+		}
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		reconciler := &SlurmDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(release, diff).
+				WithStatusSubresource(release).Build(),
+			Scheme: scheme,
+		}
+
+		Expect(reconciler.DeleteDryRun(ctx, release)).To(Succeed())
+		err := reconciler.Get(ctx, types.NamespacedName{Namespace: "default", Name: diff.Name}, &corev1.ConfigMap{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		stored := &slurmv1.SlurmDeployment{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Namespace: "default", Name: "cluster"}, stored)).To(Succeed())
+		Expect(stored.Status.DryRun).To(BeNil())
+
+		// nothing is left to delete on the next pass
+		Expect(reconciler.DeleteDryRun(ctx, release)).To(Succeed())
+	})
+})
+
+var _ = Describe("SlurmDeployment preflight", func() {
+	var values *slurmv1.ValuesSpec
+
+	storageClass := func(name, provisioner string, annotations map[string]string) storagev1.StorageClass {
+		return storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}, Provisioner: provisioner}
+	}
+	defaultClass := map[string]string{"storageclass.kubernetes.io/is-default-class": "true"}
+
+	newReconciler := func(objects ...client.Object) *SlurmDeploymentReconciler {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		return &SlurmDeploymentReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(), Scheme: scheme}
+	}
+
+	claim := func(name string, modes ...corev1.PersistentVolumeAccessMode) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "slurm"},
+			Spec:       corev1.PersistentVolumeClaimSpec{AccessModes: modes},
+		}
+	}
+
+	BeforeEach(func() {
+		values = &slurmv1.ValuesSpec{}
+	})
+
+	Context("storage classes", func() {
+		It("resolves named classes and the default class for every provisioned volume", func() {
+			classes := []storagev1.StorageClass{storageClass("fast", "nfs.csi.k8s.io", nil), storageClass("standard", "nfs.csi.k8s.io", defaultClass)}
+			values.Persistence.Shared = slurmv1.PersistenceSharedSpec{Enabled: true}
+			values.Persistence.Volumes = []slurmv1.SharedVolumeSpec{
+				{Name: "home", StorageClass: "fast"},
+				{Name: "scratch", StorageClass: "missing"},
+				{Name: "datasets", StorageClass: "missing", ExistingClaim: "datasets"},
+			}
+			values.Mariadb.Enabled = true
+			values.Mariadb.Primary.Persistence = slurmv1.MariaDBPrimaryPersistenceSpec{Enabled: true, StorageClass: "fast"}
+			Expect(checkStorageClasses(classes, values)).To(Equal([]string{
+				"StorageClass missing of persistence.volumes[scratch] does not exist",
+			}))
+		})
+
+		It("reports volumes without a class when the cluster has no default class", func() {
+			classes := []storagev1.StorageClass{storageClass("fast", "nfs.csi.k8s.io", nil)}
+			values.Persistence.Shared = slurmv1.PersistenceSharedSpec{Enabled: true}
+			values.Mariadb.Enabled = true
+			values.Mariadb.Primary.Persistence.Enabled = true
+			Expect(checkStorageClasses(classes, values)).To(Equal([]string{
+				"persistence.shared has no storageClass and the cluster has no default StorageClass",
+				"mariadb.primary.persistence has no storageClass and the cluster has no default StorageClass",
+			}))
+		})
+	})
+
+	Context("storage mounted by many nodes", func() {
+		classes := []storagev1.StorageClass{
+			storageClass("nfs", "nfs.csi.k8s.io", defaultClass),
+			storageClass("ebs", "ebs.csi.aws.com", nil),
+			storageClass("ebs-multi-attach", "ebs.csi.aws.com", map[string]string{StorageClassReadWriteManyAnnotation: "true"}),
+			storageClass("nfs-single", "nfs.csi.k8s.io", map[string]string{StorageClassReadWriteManyAnnotation: "false"}),
+		}
+
+		DescribeTable("checkManyNodeStorage",
+			func(existingClaim string, accessModes []string, class string, readOnly bool, expected string) {
+				reconciler := newReconciler(
+					claim("shared-rwx", corev1.ReadWriteMany),
+					claim("shared-rwo", corev1.ReadWriteOnce),
+					claim("datasets-rox", corev1.ReadOnlyMany),
+				)
+				failure, err := reconciler.checkManyNodeStorage(context.Background(), "slurm", classes, "persistence.shared",
+					existingClaim, accessModes, class, readOnly)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failure).To(Equal(expected))
+			},
+			Entry("existing ReadWriteMany claim", "shared-rwx", nil, "ebs", false, ""),
+			Entry("existing ReadWriteOnce claim", "shared-rwo", nil, "", false,
+				"existing claim shared-rwo of persistence.shared is not ReadWriteMany"),
+			Entry("existing ReadOnlyMany claim mounted read-only", "datasets-rox", nil, "", true, ""),
+			Entry("existing ReadOnlyMany claim mounted read-write", "datasets-rox", nil, "", false,
+				"existing claim datasets-rox of persistence.shared is not ReadWriteMany"),
+			Entry("missing existing claim", "missing", nil, "", false,
+				"existing claim missing of persistence.shared does not exist"),
+			Entry("single node access modes", "", []string{"ReadWriteOnce"}, "nfs", false,
+				"persistence.shared.accessModes must include ReadWriteMany, the volume is mounted by every slurmd pod"),
+			Entry("default class of a shared file system", "", []string{"ReadWriteMany"}, "", false, ""),
+			Entry("provisioner of block volumes", "", nil, "ebs", false,
+				"StorageClass ebs uses provisioner ebs.csi.aws.com which does not support ReadWriteMany, set the "+
+					StorageClassReadWriteManyAnnotation+" annotation to override"),
+			Entry("annotation allowing a denied provisioner", "", nil, "ebs-multi-attach", false, ""),
+			Entry("annotation refusing a shared file system", "", nil, "nfs-single", false,
+				"StorageClass nfs-single is marked without ReadWriteMany support"),
+			Entry("missing class left to the StorageClassAvailable check", "", nil, "missing", false, ""),
+		)
+	})
+
+	Context("resource quotas", func() {
+		BeforeEach(func() {
+			values.SlurmdCPU.ReplicaCount = 2
+			values.SlurmdCPU.Resources = slurmv1.SlurmdResourceSpec{
+				Requests: &slurmv1.SlurmdResourceRequestSpec{Socket: 1, CorePerSocket: 2, ThreadPerCore: 1, Memory: "4Gi"},
+				Limits:   &slurmv1.SlurmdResourceLimitSpec{Socket: 1, CorePerSocket: 4, ThreadPerCore: 1, Memory: "8Gi"},
+			}
+			values.SlurmdGPU.ReplicaCount = 1
+			values.SlurmdGPU.Resources = slurmv1.SlurmdResourceSpec{
+				Requests: &slurmv1.SlurmdResourceRequestSpec{Socket: 1, CorePerSocket: 1, ThreadPerCore: 2, Memory: "2Gi",
+					EphemeralStorage: "10Gi"},
+			}
 		})
+
+		It("sums every slurmd pod under the names a ResourceQuota uses", func() {
+			totals, err := slurmdResourceTotals(values)
+			Expect(err).NotTo(HaveOccurred())
+			expected := map[corev1.ResourceName]string{
+				corev1.ResourceCPU:                      "6",
+				corev1.ResourceRequestsCPU:              "6",
+				corev1.ResourceMemory:                   "10Gi",
+				corev1.ResourceRequestsMemory:           "10Gi",
+				corev1.ResourceEphemeralStorage:         "10Gi",
+				corev1.ResourceRequestsEphemeralStorage: "10Gi",
+				corev1.ResourceLimitsCPU:                "8",
+				corev1.ResourceLimitsMemory:             "16Gi",
+			}
+			Expect(totals).To(HaveLen(len(expected)))
+			for name, quantity := range expected {
+				total := totals[name]
+				Expect(total.Cmp(resource.MustParse(quantity))).To(BeZero(), string(name))
+			}
+		})
+
+		It("reports a quantity that does not parse", func() {
+			values.SlurmdCPU.Resources.Requests.Memory = "lots"
+			_, err := slurmdResourceTotals(values)
+			Expect(err).To(MatchError(ContainSubstring(`slurmdCPU.resources.requests.memory "lots" is not a valid quantity`)))
+		})
+
+		It("compares the totals with what the ResourceQuota leaves", func() {
+			quota := &corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "slurm"},
+				Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+					corev1.ResourceRequestsCPU:  resource.MustParse("10"),
+					corev1.ResourceLimitsMemory: resource.MustParse("16Gi"),
+				}},
+				Status: corev1.ResourceQuotaStatus{Used: corev1.ResourceList{
+					corev1.ResourceRequestsCPU:  resource.MustParse("2"),
+					corev1.ResourceLimitsMemory: resource.MustParse("4Gi"),
+				}},
+			}
+			failures, err := newReconciler(quota).checkResourceQuotas(context.Background(), "slurm", values)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failures).To(Equal([]string{"ResourceQuota team leaves 12Gi of limits.memory but slurmd requests 16Gi"}))
+
+			failures, err = newReconciler(quota).checkResourceQuotas(context.Background(), "other", values)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failures).To(BeEmpty(), "quotas of other namespaces do not apply")
+		})
+	})
+})
+
+var _ = Describe("SlurmDeployment version upgrade", func() {
+	var (
+		recorder *record.FakeRecorder
+		release  *slurmv1.SlurmDeployment
+	)
+
+	images := func(tag string) map[string]slurmv1.ImageSpec {
+		values := slurmv1.ValuesSpec{}
+		for _, component := range []string{utils.SlurmComponentSlurmdbd, utils.SlurmComponentSlurmctld, utils.SlurmComponentSlurmdCPU,
+			utils.SlurmComponentSlurmdGPU, utils.SlurmComponentLogin} {
+			utils.SetSlurmComponentImage(&values, component, slurmv1.ImageSpec{Registry: "docker.io", Repository: "slurm/" + component, Tag: tag})
+		}
+		return utils.SlurmComponentImages(&values)
+	}
+
+	setImages := func(values *slurmv1.ValuesSpec, tags map[string]slurmv1.ImageSpec) {
+		for component, image := range tags {
+			utils.SetSlurmComponentImage(values, component, image)
+		}
+	}
+
+	statefulSet := func(name string, ready int32) *appsv1.StatefulSet {
+		replicas := int32(1)
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "slurm"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status:     appsv1.StatefulSetStatus{Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: ready},
+		}
+	}
+
+	newReconciler := func(executor *fakePodExecutor, objects ...client.Object) *SlurmDeploymentReconciler {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		reconciler := &SlurmDeploymentReconciler{
+			Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+			Scheme:   scheme,
+			Recorder: recorder,
+		}
+		if executor != nil {
+			reconciler.Executor = executor
+		}
+		return reconciler
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(32)
+		release = &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"}},
+			Status:     slurmv1.SlurmDeploymentStatus{AppliedImages: images("24.05.3"), SlurmVersion: "24.05.3"},
+		}
+		setImages(&release.Spec.Values, images("25.05.1"))
+	})
+
+	It("keeps the running images when the upgrade skips too many releases", func() {
+		release.Status.AppliedImages = images("23.02.7")
+		release.Status.SlurmVersion = "23.02.7"
+		values, wait, err := newReconciler(nil).PrepareVersionUpgrade(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(BeZero())
+		Expect(utils.SlurmComponentImages(values)).To(Equal(images("23.02.7")))
+		Expect(release.Status.VersionUpgrade.Phase).To(Equal(slurmv1.VersionUpgradePhaseRefused))
+		Expect(release.Status.VersionUpgrade.Message).To(ContainSubstring("4 releases behind"))
+		Expect(recorder.Events).To(Receive(ContainSubstring(ReasonVersionUpgradeRefused)))
+	})
+
+	It("rolls out slurmdbd first and holds back slurmctld and slurmd", func() {
+		values, wait, err := newReconciler(nil).PrepareVersionUpgrade(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(BeZero())
+		rendered := utils.SlurmComponentImages(values)
+		Expect(rendered[utils.SlurmComponentSlurmdbd].Tag).To(Equal("25.05.1"))
+		for _, component := range []string{utils.SlurmComponentSlurmctld, utils.SlurmComponentSlurmdCPU,
+			utils.SlurmComponentSlurmdGPU, utils.SlurmComponentLogin} {
+			Expect(rendered[component].Tag).To(Equal("24.05.3"), component)
+		}
+		upgrade := release.Status.VersionUpgrade
+		Expect(upgrade.Phase).To(Equal(slurmv1.VersionUpgradePhaseInProgress))
+		Expect(upgrade.FromVersion).To(Equal("24.05.3"))
+		Expect(upgrade.ToVersion).To(Equal("25.05.1"))
+		Expect(upgrade.Stage).To(Equal("slurmdbd"))
+		Expect(recorder.Events).To(Receive(ContainSubstring(ReasonVersionUpgradeStarted)))
+	})
+
+	It("waits for an unhealthy stage without a helm action", func() {
+		_, _, err := newReconciler(nil).PrepareVersionUpgrade(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		release.Status.AppliedImages[utils.SlurmComponentSlurmdbd] = images("25.05.1")[utils.SlurmComponentSlurmdbd]
+
+		values, wait, err := newReconciler(nil, statefulSet("cluster-slurm-slurmdbd", 0)).PrepareVersionUpgrade(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(BeNil())
+		Expect(wait).To(Equal(versionUpgradePollInterval))
+		Expect(release.Status.VersionUpgrade.Stage).To(Equal("slurmdbd"))
+		Expect(release.Status.VersionUpgrade.Message).To(ContainSubstring("Waiting for cluster-slurm-slurmdbd to roll out"))
+	})
+
+	It("moves on to slurmctld once slurmdbd is healthy and waits for slurmctld to respond", func() {
+		_, _, err := newReconciler(nil).PrepareVersionUpgrade(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		release.Status.AppliedImages[utils.SlurmComponentSlurmdbd] = images("25.05.1")[utils.SlurmComponentSlurmdbd]
+
+		values, wait, err := newReconciler(nil, statefulSet("cluster-slurm-slurmdbd", 1)).PrepareVersionUpgrade(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(BeZero())
+		rendered := utils.SlurmComponentImages(values)
+		Expect(rendered[utils.SlurmComponentSlurmctld].Tag).To(Equal("25.05.1"))
+		Expect(rendered[utils.SlurmComponentSlurmdCPU].Tag).To(Equal("24.05.3"))
+		Expect(release.Status.VersionUpgrade.Stage).To(Equal("slurmctld"))
+
+		release.Status.AppliedImages[utils.SlurmComponentSlurmctld] = images("25.05.1")[utils.SlurmComponentSlurmctld]
+		executor := &fakePodExecutor{outputs: map[string]string{}}
+		values, wait, err = newReconciler(executor).PrepareVersionUpgrade(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(BeNil())
+		Expect(wait).To(Equal(versionUpgradePollInterval))
+		Expect(release.Status.VersionUpgrade.Message).To(ContainSubstring("Waiting for slurmctld to respond"))
+	})
+
+	It("completes once every stage runs the new version", func() {
+		_, _, err := newReconciler(nil).PrepareVersionUpgrade(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		release.Status.AppliedImages = images("25.05.1")
+
+		executor := &fakePodExecutor{outputs: map[string]string{"scontrol ping": "Slurmctld(primary) at cluster-slurm-slurmctld-0 is UP"}}
+		values, wait, err := newReconciler(executor).PrepareVersionUpgrade(context.Background(), release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(BeZero())
+		Expect(utils.SlurmComponentImages(values)).To(Equal(images("25.05.1")))
+		upgrade := release.Status.VersionUpgrade
+		Expect(upgrade.Phase).To(Equal(slurmv1.VersionUpgradePhaseCompleted))
+		Expect(upgrade.Stage).To(BeEmpty())
+		Expect(upgrade.CompletedAt).NotTo(BeNil())
+		Expect(release.Status.SlurmVersion).To(Equal("25.05.1"))
+		Expect(recorder.Events).To(Receive(ContainSubstring(ReasonVersionUpgradeStarted)))
+		Expect(recorder.Events).To(Receive(ContainSubstring(ReasonVersionUpgradeStage)))
+		Expect(recorder.Events).To(Receive(ContainSubstring(ReasonVersionUpgradeStage)))
+		Expect(recorder.Events).To(Receive(ContainSubstring(ReasonVersionUpgradeCompleted)))
+	})
+})
+
+var _ = Describe("SlurmDeployment node features", func() {
+	const (
+		sinfoFeatures = "sinfo --noheader --Node --format=%N|%f"
+		instanceType  = "node.kubernetes.io/instance-type"
+	)
+
+	var (
+		executor   *fakePodExecutor
+		release    *slurmv1.SlurmDeployment
+		reconciler *SlurmDeploymentReconciler
+	)
+
+	BeforeEach(func() {
+		executor = &fakePodExecutor{outputs: map[string]string{}}
+		release = &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"}},
+		}
+		release.Spec.Values.SlurmdCPU.Features = []string{"cpu"}
+		release.Spec.Values.SlurmdCPU.FeaturesFromNodeLabels = []string{instanceType}
+		labels := map[string]string{"app.kubernetes.io/component": "slurmd-cpu"}
+		objects := []client.Object{
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{instanceType: "m5.xlarge"}}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b", Labels: map[string]string{instanceType: "c5.large"}}},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-slurm-slurmd-cpu-0", Namespace: "slurm", Labels: labels},
+				Spec:       corev1.PodSpec{NodeName: "node-a"},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-slurm-slurmd-cpu-1", Namespace: "slurm", Labels: labels},
+				Spec:       corev1.PodSpec{NodeName: "node-b"},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-slurm-slurmd-cpu-2", Namespace: "slurm", Labels: labels},
+			},
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: utils.ComponentName(release, "slurmd-cpu"), Namespace: "slurm"},
+				Spec: appsv1.StatefulSetSpec{Selector: &metav1.LabelSelector{
+					MatchLabels: labels,
+				}},
+			},
+		}
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		reconciler = &SlurmDeploymentReconciler{
+			Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+			Scheme:   scheme,
+			Recorder: record.NewFakeRecorder(32),
+			Executor: executor,
+		}
+	})
+
+	It("does nothing while no node group takes features from node labels", func() {
+		release.Spec.Values.SlurmdCPU.FeaturesFromNodeLabels = nil
+		Expect(reconciler.ReconcileNodeFeatures(context.Background(), release)).To(BeZero())
+		Expect(executor.ran()).To(BeEmpty())
+	})
+
+	It("sets the features of registered nodes that differ", func() {
+		executor.outputs[sinfoFeatures] = "cluster-slurm-slurmd-cpu-0|cpu\ncluster-slurm-slurmd-cpu-1|c5.large,cpu\n"
+		Expect(reconciler.ReconcileNodeFeatures(context.Background(), release)).To(BeZero())
+		Expect(executor.ran()).To(Equal([]string{
+			sinfoFeatures,
+			"scontrol update NodeName=cluster-slurm-slurmd-cpu-0 AvailableFeatures=cpu,m5.xlarge ActiveFeatures=cpu,m5.xlarge",
+		}), "node 1 is in sync, node 2 is not scheduled")
+	})
+
+	It("pushes the features again to a restarted slurmctld that forgot them", func() {
+		executor.outputs[sinfoFeatures] = "cluster-slurm-slurmd-cpu-0|cpu,m5.xlarge\ncluster-slurm-slurmd-cpu-1|c5.large,cpu\n"
+		Expect(reconciler.ReconcileNodeFeatures(context.Background(), release)).To(BeZero())
+		Expect(executor.ran()).To(Equal([]string{sinfoFeatures}))
+
+		executor.outputs[sinfoFeatures] = "cluster-slurm-slurmd-cpu-0|cpu\ncluster-slurm-slurmd-cpu-1|(null)\n"
+		Expect(reconciler.ReconcileNodeFeatures(context.Background(), release)).To(BeZero())
+		Expect(executor.ran()).To(Equal([]string{
+			sinfoFeatures,
+			"scontrol update NodeName=cluster-slurm-slurmd-cpu-0 AvailableFeatures=cpu,m5.xlarge ActiveFeatures=cpu,m5.xlarge",
+			"scontrol update NodeName=cluster-slurm-slurmd-cpu-1 AvailableFeatures=c5.large,cpu ActiveFeatures=c5.large,cpu",
+		}))
+	})
+
+	It("passes the pod events that need the features again", func() {
+		pending := &corev1.Pod{}
+		scheduled := &corev1.Pod{Spec: corev1.PodSpec{NodeName: "node-a"}}
+		ready := scheduled.DeepCopy()
+		ready.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		Expect(slurmPodScheduled.Update(event.UpdateEvent{ObjectOld: pending, ObjectNew: scheduled})).To(BeTrue())
+		Expect(slurmPodScheduled.Update(event.UpdateEvent{ObjectOld: scheduled, ObjectNew: ready})).To(BeTrue())
+		Expect(slurmPodScheduled.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: ready.DeepCopy()})).To(BeFalse())
+		Expect(slurmPodScheduled.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: scheduled})).To(BeFalse())
+	})
+})
+
+var _ = Describe("SlurmDeployment login endpoint", func() {
+	var (
+		release *slurmv1.SlurmDeployment
+		service *corev1.Service
+		pods    []client.Object
+	)
+
+	BeforeEach(func() {
+		release = &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"}},
+		}
+		release.Spec.Values.SlurmLogin.ReplicaCount = 1
+		release.Status.LoginEndpoint = "stale:22"
+		service = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-slurm-login", Namespace: "slurm"},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app.kubernetes.io/component": "login"},
+				Ports:    []corev1.ServicePort{{Name: "ssh", Port: utils.LoginSSHPort}},
+			},
+		}
+		pods = nil
+	})
+
+	resolve := func() time.Duration {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		objects := append([]client.Object{service}, pods...)
+		reconciler := &SlurmDeploymentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+			Scheme: scheme,
+		}
+		return reconciler.ReconcileLoginEndpoint(context.Background(), release)
+	}
+
+	loginPod := func(name string, phase corev1.PodPhase, hostIP string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "slurm", Labels: service.Spec.Selector},
+			Status:     corev1.PodStatus{Phase: phase, HostIP: hostIP},
+		}
+	}
+
+	It("uses the cluster DNS name of a ClusterIP Service", func() {
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+		Expect(resolve()).To(BeZero())
+		Expect(release.Status.LoginEndpoint).To(Equal("cluster-slurm-login.slurm.svc:22"))
+	})
+
+	It("uses the load balancer address of a LoadBalancer Service", func() {
+		service.Spec.Type = corev1.ServiceTypeLoadBalancer
+		service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{}, {Hostname: "login.example.com"}}
+		Expect(resolve()).To(BeZero())
+		Expect(release.Status.LoginEndpoint).To(Equal("login.example.com:22"))
+
+		service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "2001:db8::1", Hostname: "login.example.com"}}
+		Expect(resolve()).To(BeZero())
+		Expect(release.Status.LoginEndpoint).To(Equal("[2001:db8::1]:22"))
+	})
+
+	It("uses the node port on the host of a running login pod for a NodePort Service", func() {
+		service.Spec.Type = corev1.ServiceTypeNodePort
+		service.Spec.Ports[0].NodePort = 30022
+		pods = []client.Object{
+			loginPod("cluster-slurm-login-0", corev1.PodPending, ""),
+			loginPod("cluster-slurm-login-1", corev1.PodRunning, "10.0.0.7"),
+		}
+		Expect(resolve()).To(BeZero())
+		Expect(release.Status.LoginEndpoint).To(Equal("10.0.0.7:30022"))
+	})
+
+	It("waits for an address that is not assigned yet", func() {
+		service.Spec.Type = corev1.ServiceTypeLoadBalancer
+		Expect(resolve()).To(Equal(loginEndpointRetryInterval))
+		Expect(release.Status.LoginEndpoint).To(BeEmpty())
+
+		service.Spec.Type = corev1.ServiceTypeNodePort
+		service.Spec.Ports[0].NodePort = 30022
+		pods = []client.Object{loginPod("cluster-slurm-login-0", corev1.PodPending, "")}
+		release.Status.LoginEndpoint = "stale:22"
+		Expect(resolve()).To(Equal(loginEndpointRetryInterval))
+		Expect(release.Status.LoginEndpoint).To(BeEmpty())
+	})
+
+	It("clears the endpoint without login nodes or a login Service", func() {
+		release.Spec.Values.SlurmLogin.ReplicaCount = 0
+		Expect(resolve()).To(BeZero())
+		Expect(release.Status.LoginEndpoint).To(BeEmpty())
+
+		release.Spec.Values.SlurmLogin.ReplicaCount = 1
+		release.Status.LoginEndpoint = "stale:22"
+		service.Name = "other"
+		Expect(resolve()).To(Equal(loginEndpointRetryInterval))
+		Expect(release.Status.LoginEndpoint).To(BeEmpty())
 	})
 })
@@ -0,0 +1,137 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"log"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/postrender"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+const (
+	// ReasonDryRunRendered is recorded when a DryRun diff has been rendered
+	ReasonDryRunRendered = "DryRunRendered"
+
+	// maxDryRunDiffBytes keeps the diff ConfigMap well below the 1MiB object size limit
+	maxDryRunDiffBytes = 900 * 1024
+)
+
+// ReconcileDryRun renders the chart through a helm dry-run, diffs it against the live release manifest and
+// stores the full diff in a ConfigMap next to the SlurmDeployment. Nothing is applied to the cluster.
+func (r *SlurmDeploymentReconciler) ReconcileDryRun(ctx context.Context, actionConfig *action.Configuration,
+	release *slurmv1.SlurmDeployment, slurmChart *chart.Chart, chartValues map[string]interface{},
+	postRenderer postrender.PostRenderer, valuesHash string) (ctrl.Result, error) {
+	liveManifest := ""
+	var rendered *helmrelease.Release
+	var renderErr error
+	if live, getErr := action.NewGet(actionConfig).Run(release.Name); getErr == nil {
+		liveManifest = live.Manifest
+		upgradeClient := action.NewUpgrade(actionConfig)
+		upgradeClient.Namespace = release.Spec.Chart.Namespace
+		upgradeClient.DryRun = true
+		upgradeClient.PostRenderer = postRenderer
+		rendered, renderErr = upgradeClient.Run(release.Name, slurmChart, chartValues)
+	} else {
+		log.Printf("Cannot find release %s, rendering dry-run diff against an empty manifest: %v", release.Name, getErr)
+		installClient := action.NewInstall(actionConfig)
+		installClient.ReleaseName = release.Name
+		installClient.Namespace = release.Spec.Chart.Namespace
+		installClient.DryRun = true
+		installClient.PostRenderer = postRenderer
+		rendered, renderErr = installClient.Run(slurmChart, chartValues)
+	}
+	if renderErr != nil {
+		log.Printf("Failed to render dry-run of release %s: %v", release.Name, renderErr)
+		return ctrl.Result{}, renderErr
+	}
+
+	diff, diffErr := utils.DiffManifests(liveManifest, rendered.Manifest)
+	if diffErr != nil {
+		log.Printf("Failed to diff release %s: %v", release.Name, diffErr)
+		return ctrl.Result{}, diffErr
+	}
+
+	unified := diff.Unified
+	if len(unified) > maxDryRunDiffBytes {
+		unified = unified[:maxDryRunDiffBytes] + "\n... diff truncated ...\n"
+	}
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: dryRunDiffName(release), Namespace: release.Namespace}}
+	if _, applyErr := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Data = map[string]string{
+			"summary": diff.Summary(),
+			"diff":    unified,
+		}
+		return controllerutil.SetControllerReference(release, configMap, r.Scheme)
+	}); applyErr != nil {
+		log.Printf("Failed to store dry-run diff of %s: %v", release.Name, applyErr)
+		return ctrl.Result{}, applyErr
+	}
+
+	now := metav1.Now()
+	release.Status.DryRun = &slurmv1.DryRunStatus{
+		ObservedGeneration: release.Generation,
+		ValuesHash:         valuesHash,
+		Summary:            diff.Summary(),
+		Added:              diff.Added,
+		Removed:            diff.Removed,
+		Changed:            diff.Changed,
+		DiffConfigMap:      configMap.Name,
+		RenderedAt:         &now,
+	}
+	if updateStatusErr := r.Status().Update(ctx, release); updateStatusErr != nil {
+		log.Printf("Failed to update status: %v", updateStatusErr)
+		return ctrl.Result{}, updateStatusErr
+	}
+	r.recordEvent(release, corev1.EventTypeNormal, ReasonDryRunRendered, "Dry run against release %s: %s", release.Name, diff.Summary())
+	return ctrl.Result{}, nil
+}
+
+// DeleteDryRun removes the diff ConfigMap and the status of DryRun mode once the SlurmDeployment applies again
+func (r *SlurmDeploymentReconciler) DeleteDryRun(ctx context.Context, release *slurmv1.SlurmDeployment) error {
+	if release.Status.DryRun == nil {
+		return nil
+	}
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: dryRunDiffName(release), Namespace: release.Namespace}}
+	if err := r.Delete(ctx, configMap); err != nil && !apierrors.IsNotFound(err) {
+		log.Printf("Failed to delete dry-run diff %s: %v", configMap.Name, err)
+		return err
+	}
+	release.Status.DryRun = nil
+	if updateStatusErr := r.Status().Update(ctx, release); updateStatusErr != nil {
+		log.Printf("Failed to update status: %v", updateStatusErr)
+		return updateStatusErr
+	}
+	return nil
+}
+
+// dryRunDiffName is the name of the ConfigMap holding the diff rendered in DryRun mode
+func dryRunDiffName(release *slurmv1.SlurmDeployment) string {
+	return release.Name + "-dry-run-diff"
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;create;update;patch;delete
+
+var serviceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+
+const (
+	exporterMetricsPortName  = "metrics"
+	exporterConfChecksumName = "slurm.ay.dev/slurm-conf-checksum"
+)
+
+// ReconcileSlurmExporter creates or updates the exporter of a SlurmDeployment, or removes it when monitoring is disabled.
+// The exporter lives outside the helm release so that it is not touched when the operator restarts slurmctld.
+func (r *SlurmDeploymentReconciler) ReconcileSlurmExporter(ctx context.Context, release *slurmv1.SlurmDeployment) error {
+	exporter := release.Spec.Values.Monitoring.Exporter
+	if !exporter.Enabled {
+		return r.DeleteSlurmExporter(ctx, release)
+	}
+	utils.DefaultSlurmExporterSpec(&exporter)
+
+	name := utils.ComponentName(release, utils.SlurmExporterComponent)
+	namespace := release.Spec.Chart.Namespace
+	labels := exporterLabels(release)
+	slurmConf := utils.BuildExporterSlurmConf(release)
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name + "-slurm-conf", Namespace: namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Labels = labels
+		configMap.Data = map[string]string{"slurm.conf": slurmConf}
+		return nil
+	}); err != nil {
+		log.Printf("Failed to apply exporter configmap %s: %v", configMap.Name, err)
+		return err
+	}
+
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, deploy, func() error {
+		deploy.Labels = labels
+		deploy.Spec.Replicas = func(i int32) *int32 { return &i }(1)
+		deploy.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
+		deploy.Spec.Template.Labels = labels
+		deploy.Spec.Template.Annotations = map[string]string{
+			exporterConfChecksumName: fmt.Sprintf("%x", sha256.Sum256([]byte(slurmConf))),
+		}
+		deploy.Spec.Template.Spec = exporterPodSpec(release, &exporter, configMap.Name)
+		return nil
+	}); err != nil {
+		log.Printf("Failed to apply exporter deployment %s: %v", name, err)
+		return err
+	}
+
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
+		service.Labels = labels
+		service.Spec.Selector = labels
+		service.Spec.Ports = []corev1.ServicePort{{
+			Name:       exporterMetricsPortName,
+			Port:       exporter.Port,
+			TargetPort: intstr.FromString(exporterMetricsPortName),
+			Protocol:   corev1.ProtocolTCP,
+		}}
+		return nil
+	}); err != nil {
+		log.Printf("Failed to apply exporter service %s: %v", name, err)
+		return err
+	}
+
+	if !r.serviceMonitorAvailable() {
+		log.Printf("ServiceMonitor CRD not installed, skipping ServiceMonitor for %s", release.Name)
+		return nil
+	}
+	serviceMonitor := &unstructured.Unstructured{}
+	serviceMonitor.SetGroupVersionKind(serviceMonitorGVK)
+	serviceMonitor.SetName(name)
+	serviceMonitor.SetNamespace(namespace)
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, serviceMonitor, func() error {
+		monitorLabels := map[string]string{}
+		for key, value := range exporter.ServiceMonitorLabels {
+			monitorLabels[key] = value
+		}
+		for key, value := range labels {
+			monitorLabels[key] = value
+		}
+		serviceMonitor.SetLabels(monitorLabels)
+		return unstructured.SetNestedField(serviceMonitor.Object, map[string]interface{}{
+			"selector": map[string]interface{}{"matchLabels": stringMapToInterface(labels)},
+			"endpoints": []interface{}{map[string]interface{}{
+				"port":     exporterMetricsPortName,
+				"path":     "/metrics",
+				"interval": exporter.ScrapeInterval,
+			}},
+		}, "spec")
+	}); err != nil {
+		log.Printf("Failed to apply exporter ServiceMonitor %s: %v", name, err)
+		return err
+	}
+	return nil
+}
+
+// DeleteSlurmExporter removes every object created for the exporter of a SlurmDeployment
+func (r *SlurmDeploymentReconciler) DeleteSlurmExporter(ctx context.Context, release *slurmv1.SlurmDeployment) error {
+	name := utils.ComponentName(release, utils.SlurmExporterComponent)
+	namespace := release.Spec.Chart.Namespace
+	objects := []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name + "-slurm-conf", Namespace: namespace}},
+	}
+	if r.serviceMonitorAvailable() {
+		serviceMonitor := &unstructured.Unstructured{}
+		serviceMonitor.SetGroupVersionKind(serviceMonitorGVK)
+		serviceMonitor.SetName(name)
+		serviceMonitor.SetNamespace(namespace)
+		objects = append(objects, serviceMonitor)
+	}
+	for _, object := range objects {
+		if err := r.Delete(ctx, object); err != nil && !apierrors.IsNotFound(err) {
+			log.Printf("Failed to delete exporter object %s: %v", object.GetName(), err)
+			return err
+		}
+	}
+	return nil
+}
+
+// serviceMonitorAvailable tells whether the prometheus-operator ServiceMonitor CRD is installed
+func (r *SlurmDeploymentReconciler) serviceMonitorAvailable() bool {
+	_, err := r.RESTMapper().RESTMapping(serviceMonitorGVK.GroupKind(), serviceMonitorGVK.Version)
+	if err != nil && !meta.IsNoMatchError(err) {
+		log.Printf("Failed to look up ServiceMonitor CRD: %v", err)
+	}
+	return err == nil
+}
+
+func exporterPodSpec(release *slurmv1.SlurmDeployment, exporter *slurmv1.SlurmExporterSpec, configMapName string) corev1.PodSpec {
+	munged := release.Spec.Values.Munged.Image
+	mungeSocket := corev1.VolumeMount{Name: "munge-socket-file", MountPath: "/run/munge"}
+
+	exporterContainer := corev1.Container{
+		Name:            utils.SlurmExporterComponent,
+		Image:           utils.ImageReference(exporter.Image),
+		ImagePullPolicy: corev1.PullPolicy(exporter.Image.PullPolicy),
+		Args:            exporter.Args,
+		Env:             []corev1.EnvVar{{Name: "SLURM_CONF", Value: "/etc/slurm/slurm.conf"}},
+		Ports: []corev1.ContainerPort{{
+			Name:          exporterMetricsPortName,
+			ContainerPort: exporter.Port,
+			Protocol:      corev1.ProtocolTCP,
+		}},
+		VolumeMounts: []corev1.VolumeMount{
+			mungeSocket,
+			{Name: "slurm-conf-file", MountPath: "/etc/slurm/slurm.conf", SubPath: "slurm.conf"},
+		},
+	}
+
+	pullSecrets := []corev1.LocalObjectReference{}
+	for _, secret := range append(append([]string{}, exporter.Image.PullSecrets...), munged.PullSecrets...) {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: secret})
+	}
+
+	return corev1.PodSpec{
+		ImagePullSecrets: pullSecrets,
+		InitContainers: []corev1.Container{{
+			Name:            "init-permission",
+			Image:           utils.ImageReference(munged),
+			ImagePullPolicy: corev1.PullPolicy(munged.PullPolicy),
+			Command:         []string{"sh", "-c", "chmod 755 /run/munge && chown 1108:1108 /run/munge"},
+			VolumeMounts:    []corev1.VolumeMount{mungeSocket},
+		}},
+		Containers: []corev1.Container{
+			{
+				Name:            "munged",
+				Image:           utils.ImageReference(munged),
+				ImagePullPolicy: corev1.PullPolicy(munged.PullPolicy),
+				VolumeMounts:    []corev1.VolumeMount{mungeSocket},
+			},
+			exporterContainer,
+		},
+		NodeSelector: release.Spec.Values.Slurmctld.NodeSelector,
+		Volumes: []corev1.Volume{
+			{Name: "munge-socket-file", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			{Name: "slurm-conf-file", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: configMapName},
+			}}},
+		},
+	}
+}
+
+func exporterLabels(release *slurmv1.SlurmDeployment) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       "slurm-exporter",
+		"app.kubernetes.io/instance":   release.Name,
+		"app.kubernetes.io/component":  utils.SlurmExporterComponent,
+		"app.kubernetes.io/managed-by": "slurm-operator",
+	}
+}
+
+func stringMapToInterface(values map[string]string) map[string]interface{} {
+	converted := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		converted[key] = value
+	}
+	return converted
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+const (
+	ReasonNodeFeaturesUpdated = "NodeFeaturesUpdated"
+	ReasonNodeFeaturesFailed  = "NodeFeaturesFailed"
+
+	nodeFeaturesRetryInterval = 30 * time.Second
+)
+
+// nodeFeatureGroup is a slurmd component with the static and extended resource features of its node group
+type nodeFeatureGroup struct {
+	component  string
+	features   []string
+	fromLabels []string
+}
+
+func nodeFeatureGroups(release *slurmv1.SlurmDeployment) []nodeFeatureGroup {
+	values := &release.Spec.Values
+	return []nodeFeatureGroup{
+		{
+			component:  "slurmd-cpu",
+			features:   utils.SlurmdGroupFeatures(values.SlurmdCPU.Features, &values.SlurmdCPU.Resources),
+			fromLabels: values.SlurmdCPU.FeaturesFromNodeLabels,
+		},
+		{
+			component:  "slurmd-gpu",
+			features:   utils.SlurmdGroupFeatures(values.SlurmdGPU.Features, &values.SlurmdGPU.Resources),
+			fromLabels: values.SlurmdGPU.FeaturesFromNodeLabels,
+		},
+	}
+}
+
+// resolvesNodeFeatures tells whether a node group takes features from the labels of kubernetes nodes
+func resolvesNodeFeatures(release *slurmv1.SlurmDeployment) bool {
+	for _, group := range nodeFeatureGroups(release) {
+		if len(group.fromLabels) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ReconcileNodeFeatures adds the values of featuresFromNodeLabels to the static features of every slurm node backed
+// by a scheduled slurmd pod. slurmctld forgets features set through scontrol when it restarts, so the live features
+// are compared on every pass and pushed again as soon as a restarted slurmctld is ready. It returns how long to wait
+// before retrying a failure.
+func (r *SlurmDeploymentReconciler) ReconcileNodeFeatures(ctx context.Context, release *slurmv1.SlurmDeployment) time.Duration {
+	if !resolvesNodeFeatures(release) || r.Executor == nil {
+		return 0
+	}
+	current, queryErr := utils.QuerySlurmNodeFeatures(ctx, r.Executor, release)
+	if queryErr != nil {
+		log.Printf("Failed to query node features of %s: %v", release.Name, queryErr)
+		return nodeFeaturesRetryInterval
+	}
+
+	kubeNodes := map[string]*corev1.Node{}
+	for _, group := range nodeFeatureGroups(release) {
+		if len(group.fromLabels) == 0 {
+			continue
+		}
+		pods, podsErr := r.scheduledSlurmdPods(ctx, release, group.component, kubeNodes)
+		if podsErr != nil {
+			log.Printf("Failed to resolve the nodes of the %s pods of %s: %v", group.component, release.Name, podsErr)
+			return nodeFeaturesRetryInterval
+		}
+		for _, pod := range pods {
+			features, registered := current[pod.name]
+			if !registered {
+				// slurmd has not registered the node yet
+				continue
+			}
+			desired := utils.SlurmNodeFeatures(group.features, group.fromLabels, pod.node.Labels)
+			if utils.SameFeatures(features, desired) {
+				continue
+			}
+			log.Printf("Setting features of node %s of %s to %v", pod.name, release.Name, desired)
+			if updateErr := utils.SetSlurmNodeFeatures(ctx, r.Executor, release, pod.name, desired); updateErr != nil {
+				log.Printf("Failed to set features of node %s of %s: %v", pod.name, release.Name, updateErr)
+				r.recordEvent(release, corev1.EventTypeWarning, ReasonNodeFeaturesFailed, "Failed to set features of node %s: %v", pod.name, updateErr)
+				return nodeFeaturesRetryInterval
+			}
+			r.recordEvent(release, corev1.EventTypeNormal, ReasonNodeFeaturesUpdated, "Set features of node %s to %v", pod.name, desired)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+const (
+	ReasonHibernating = "Hibernating"
+	ReasonHibernated  = "Hibernated"
+	ReasonResuming    = "Resuming"
+	ReasonResumed     = "Resumed"
+	// ReasonHibernationRefused is recorded when a component keeps its data only while its pod runs
+	ReasonHibernationRefused = "HibernationRefused"
+
+	hibernationPollInterval = 10 * time.Second
+)
+
+// hibernationComponent is a workload scaled by hibernation, listed in the order it is stopped
+type hibernationComponent struct {
+	name       string
+	deployment bool
+	slurmctld  bool
+}
+
+// hibernationComponents lists the workloads of a release from the most dependent one to the least dependent one,
+// hibernation walks the list forwards and resume walks it backwards
+func hibernationComponents(release *slurmv1.SlurmDeployment) []hibernationComponent {
+	return []hibernationComponent{
+		{name: utils.ComponentName(release, utils.SlurmExporterComponent), deployment: true},
+		{name: utils.ComponentName(release, "login"), deployment: true},
+		{name: utils.ComponentName(release, "slurmd-cpu")},
+		{name: utils.ComponentName(release, "slurmd-gpu")},
+		{name: utils.ComponentName(release, "slurmctld"), slurmctld: true},
+		{name: utils.ComponentName(release, "slurmdbd")},
+		{name: fmt.Sprintf("%s-%s", release.Name, "mariadb")},
+	}
+}
+
+// ReconcileHibernation scales a release down or back up one component at a time. It returns handled=false once the
+// release is neither hibernated nor resuming, so the regular helm reconciliation can run.
+func (r *SlurmDeploymentReconciler) ReconcileHibernation(ctx context.Context, release *slurmv1.SlurmDeployment) (bool, ctrl.Result, error) {
+	status := release.Status.Hibernation
+	if !release.Spec.Hibernate {
+		meta.RemoveStatusCondition(&release.Status.Conditions, slurmv1.ConditionHibernationBlocked)
+	}
+	if !release.Spec.Hibernate && (status == nil || status.Phase == slurmv1.HibernationPhaseRunning) {
+		return false, ctrl.Result{}, nil
+	}
+
+	if release.Spec.Hibernate && (status == nil || status.Phase == slurmv1.HibernationPhaseRunning) {
+		if blocked, blockErr := r.hibernationBlocked(ctx, release); blockErr != nil {
+			return true, ctrl.Result{}, blockErr
+		} else if blocked {
+			// the cluster keeps running and the regular reconciliation goes on
+			return false, ctrl.Result{}, nil
+		}
+	}
+
+	var result ctrl.Result
+	var err error
+	if release.Spec.Hibernate {
+		result, err = r.hibernate(ctx, release)
+	} else {
+		result, err = r.resume(ctx, release)
+	}
+	if err != nil {
+		return true, ctrl.Result{}, err
+	}
+	release.Status.ClusterStatus = release.Status.Hibernation.Phase
+	if release.Status.Hibernation.Phase == slurmv1.HibernationPhaseRunning {
+		release.Status.ClusterStatus = ""
+	}
+	if updateStatusErr := r.Status().Update(ctx, release); updateStatusErr != nil {
+		log.Printf("Failed to update status: %v", updateStatusErr)
+		return true, ctrl.Result{}, updateStatusErr
+	}
+	return true, result, nil
+}
+
+// hibernationBlocked refuses to hibernate a release whose MariaDB or slurmctld state lives in the pod, scaling it to
+// zero would lose the accounting database or the job state, and a release whose slurmdbd other releases report to.
+// The refusal is kept in the HibernationBlocked condition.
+func (r *SlurmDeploymentReconciler) hibernationBlocked(ctx context.Context, release *slurmv1.SlurmDeployment) (bool, error) {
+	reasons := []string{}
+	if !usesSharedSlurmdbd(release) && !release.Spec.Values.Mariadb.Primary.Persistence.Enabled {
+		reasons = append(reasons, "mariadb.primary.persistence is disabled")
+	}
+	if _, _, claimErr := findSlurmctldStateClaim(ctx, r.Client, release); claimErr != nil {
+		if !apierrors.IsNotFound(claimErr) {
+			reasons = append(reasons, claimErr.Error())
+		}
+	}
+	var clients []string
+	if !usesSharedSlurmdbd(release) {
+		var listErr error
+		if clients, listErr = slurmdbdClients(ctx, r.Client, release); listErr != nil {
+			return false, listErr
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               slurmv1.ConditionHibernationBlocked,
+		Status:             metav1.ConditionFalse,
+		Reason:             "PersistentState",
+		Message:            "Every stateful component keeps its data on a persistent volume",
+		ObservedGeneration: release.Generation,
+	}
+	switch {
+	case len(reasons) > 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "EphemeralState"
+		condition.Message = "Hibernation would lose data: " + strings.Join(reasons, ", ")
+	case len(clients) > 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "SharedSlurmdbd"
+		condition.Message = "Hibernation would stop the slurmdbd SlurmDeployments " + strings.Join(clients, ", ") + " report to"
+	}
+	blocked := condition.Status == metav1.ConditionTrue
+	if meta.SetStatusCondition(&release.Status.Conditions, condition) {
+		if blocked {
+			log.Printf("Refusing to hibernate %s: %s", release.Name, condition.Message)
+			r.recordEvent(release, corev1.EventTypeWarning, ReasonHibernationRefused, "%s", condition.Message)
+		}
+		if updateStatusErr := r.Status().Update(ctx, release); updateStatusErr != nil {
+			log.Printf("Failed to update status: %v", updateStatusErr)
+			return false, updateStatusErr
+		}
+	}
+	return blocked, nil
+}
+
+func (r *SlurmDeploymentReconciler) hibernate(ctx context.Context, release *slurmv1.SlurmDeployment) (ctrl.Result, error) {
+	status := release.Status.Hibernation
+	if status == nil || (status.Phase != slurmv1.HibernationPhaseHibernating && status.Phase != slurmv1.HibernationPhaseHibernated) {
+		status = &slurmv1.HibernationStatus{Phase: slurmv1.HibernationPhaseHibernating, Replicas: map[string]int32{}}
+		release.Status.Hibernation = status
+		r.recordEvent(release, corev1.EventTypeNormal, ReasonHibernating, "Scaling every component to zero, volumes are kept")
+	}
+	if status.Replicas == nil {
+		status.Replicas = map[string]int32{}
+	}
+
+	for _, component := range hibernationComponents(release) {
+		desired, _, current, found, getErr := r.componentScale(ctx, release, component)
+		if getErr != nil {
+			return ctrl.Result{}, getErr
+		}
+		if !found {
+			continue
+		}
+		if desired > 0 {
+			if _, saved := status.Replicas[component.name]; !saved {
+				status.Replicas[component.name] = desired
+			}
+			if component.slurmctld && r.Executor != nil {
+				// make slurmctld write its state to the state save location before its pod goes away
+				if _, shutdownErr := utils.RunSlurmctldCommand(ctx, r.Executor, release, "scontrol", "shutdown", "slurmctld"); shutdownErr != nil {
+					log.Printf("Failed to shut down slurmctld of %s before hibernation: %v", release.Name, shutdownErr)
+				}
+			}
+			log.Printf("Hibernating %s: scaling %s from %d to 0", release.Name, component.name, desired)
+			if scaleErr := r.scaleComponent(ctx, release, component, 0); scaleErr != nil {
+				return ctrl.Result{}, scaleErr
+			}
+		}
+		if desired > 0 || current > 0 {
+			status.Phase = slurmv1.HibernationPhaseHibernating
+			status.Message = fmt.Sprintf("Waiting for %s to stop", component.name)
+			return ctrl.Result{RequeueAfter: hibernationPollInterval}, nil
+		}
+	}
+
+	if status.Phase != slurmv1.HibernationPhaseHibernated {
+		now := metav1.Now()
+		status.Phase = slurmv1.HibernationPhaseHibernated
+		status.HibernatedAt = &now
+		r.recordEvent(release, corev1.EventTypeNormal, ReasonHibernated, "Every component is scaled to zero")
+	}
+	status.Message = "All components stopped, volumes kept"
+	return ctrl.Result{}, nil
+}
+
+func (r *SlurmDeploymentReconciler) resume(ctx context.Context, release *slurmv1.SlurmDeployment) (ctrl.Result, error) {
+	status := release.Status.Hibernation
+	if status.Phase != slurmv1.HibernationPhaseResuming {
+		status.Phase = slurmv1.HibernationPhaseResuming
+		r.recordEvent(release, corev1.EventTypeNormal, ReasonResuming, "Restoring components in dependency order")
+	}
+
+	components := hibernationComponents(release)
+	for i := len(components) - 1; i >= 0; i-- {
+		component := components[i]
+		saved, wasRunning := status.Replicas[component.name]
+		if !wasRunning {
+			continue
+		}
+		desired, ready, _, found, getErr := r.componentScale(ctx, release, component)
+		if getErr != nil {
+			return ctrl.Result{}, getErr
+		}
+		if !found {
+			continue
+		}
+		if desired != saved {
+			log.Printf("Resuming %s: scaling %s to %d", release.Name, component.name, saved)
+			if scaleErr := r.scaleComponent(ctx, release, component, saved); scaleErr != nil {
+				return ctrl.Result{}, scaleErr
+			}
+		}
+		if desired != saved || ready < saved {
+			status.Message = fmt.Sprintf("Waiting for %s to become ready", component.name)
+			return ctrl.Result{RequeueAfter: hibernationPollInterval}, nil
+		}
+	}
+
+	now := metav1.Now()
+	status.Phase = slurmv1.HibernationPhaseRunning
+	status.ResumedAt = &now
+	status.Replicas = nil
+	status.Message = "All components restored"
+	r.recordEvent(release, corev1.EventTypeNormal, ReasonResumed, "Every component is running again")
+	// continue with the regular reconciliation
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// componentScale returns the desired, ready and current replicas of a component workload
+func (r *SlurmDeploymentReconciler) componentScale(ctx context.Context, release *slurmv1.SlurmDeployment,
+	component hibernationComponent) (int32, int32, int32, bool, error) {
+	key := types.NamespacedName{Namespace: release.Spec.Chart.Namespace, Name: component.name}
+	if component.deployment {
+		deploy := &appsv1.Deployment{}
+		if err := r.Get(ctx, key, deploy); err != nil {
+			if apierrors.IsNotFound(err) {
+				return 0, 0, 0, false, nil
+			}
+			return 0, 0, 0, false, err
+		}
+		return replicasOf(deploy.Spec.Replicas), deploy.Status.ReadyReplicas, deploy.Status.Replicas, true, nil
+	}
+	sts := &appsv1.StatefulSet{}
+	if err := r.Get(ctx, key, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, 0, 0, false, nil
+		}
+		return 0, 0, 0, false, err
+	}
+	return replicasOf(sts.Spec.Replicas), sts.Status.ReadyReplicas, sts.Status.Replicas, true, nil
+}
+
+func (r *SlurmDeploymentReconciler) scaleComponent(ctx context.Context, release *slurmv1.SlurmDeployment, component hibernationComponent, replicas int32) error {
+	key := types.NamespacedName{Namespace: release.Spec.Chart.Namespace, Name: component.name}
+	if component.deployment {
+		deploy := &appsv1.Deployment{}
+		if err := r.Get(ctx, key, deploy); err != nil {
+			return err
+		}
+		deploy.Spec.Replicas = &replicas
+		return r.Update(ctx, deploy)
+	}
+	sts := &appsv1.StatefulSet{}
+	if err := r.Get(ctx, key, sts); err != nil {
+		return err
+	}
+	sts.Spec.Replicas = &replicas
+	return r.Update(ctx, sts)
+}
+
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		// the API server defaults unset replicas to one
+		return 1
+	}
+	return *replicas
+}
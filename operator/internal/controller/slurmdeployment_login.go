@@ -0,0 +1,107 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+// loginEndpointRetryInterval polls for an address the load balancer or a login pod has not been given yet
+const loginEndpointRetryInterval = 15 * time.Second
+
+// ReconcileLoginEndpoint resolves the address SSH clients reach the login nodes at: the load balancer address of a
+// LoadBalancer Service, the node port on the host of a running login pod for a NodePort Service, and the cluster DNS
+// name otherwise. It only updates the status in memory and returns how long to wait before looking again.
+func (r *SlurmDeploymentReconciler) ReconcileLoginEndpoint(ctx context.Context, release *slurmv1.SlurmDeployment) time.Duration {
+	if release.Spec.Values.SlurmLogin.ReplicaCount == 0 {
+		release.Status.LoginEndpoint = ""
+		return 0
+	}
+	service := &corev1.Service{}
+	if getErr := r.Get(ctx, types.NamespacedName{Namespace: release.Spec.Chart.Namespace, Name: utils.LoginServiceName(release)}, service); getErr != nil {
+		log.Printf("Failed to get the login Service of %s: %v", release.Name, getErr)
+		release.Status.LoginEndpoint = ""
+		return loginEndpointRetryInterval
+	}
+
+	switch service.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			host := ingress.IP
+			if host == "" {
+				host = ingress.Hostname
+			}
+			if host != "" {
+				release.Status.LoginEndpoint = net.JoinHostPort(host, strconv.Itoa(utils.LoginSSHPort))
+				return 0
+			}
+		}
+		release.Status.LoginEndpoint = ""
+		return loginEndpointRetryInterval
+	case corev1.ServiceTypeNodePort:
+		hostIP, hostErr := r.loginPodHostIP(ctx, release, service)
+		if hostErr != nil || hostIP == "" {
+			if hostErr != nil {
+				log.Printf("Failed to list the login pods of %s: %v", release.Name, hostErr)
+			}
+			release.Status.LoginEndpoint = ""
+			return loginEndpointRetryInterval
+		}
+		for _, port := range service.Spec.Ports {
+			if port.Port == utils.LoginSSHPort && port.NodePort != 0 {
+				release.Status.LoginEndpoint = net.JoinHostPort(hostIP, strconv.Itoa(int(port.NodePort)))
+				return 0
+			}
+		}
+		release.Status.LoginEndpoint = ""
+		return loginEndpointRetryInterval
+	default:
+		release.Status.LoginEndpoint = fmt.Sprintf("%s.%s.svc:%d", service.Name, service.Namespace, utils.LoginSSHPort)
+		return 0
+	}
+}
+
+// loginPodHostIP returns the host IP of a running login pod, which serves the node port even with the Local
+// external traffic policy
+func (r *SlurmDeploymentReconciler) loginPodHostIP(ctx context.Context, release *slurmv1.SlurmDeployment, service *corev1.Service) (string, error) {
+	if len(service.Spec.Selector) == 0 {
+		return "", nil
+	}
+	pods := &corev1.PodList{}
+	if listErr := r.List(ctx, pods, client.InNamespace(release.Spec.Chart.Namespace),
+		client.MatchingLabels(service.Spec.Selector)); listErr != nil {
+		return "", listErr
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.Status.HostIP != "" && pod.DeletionTimestamp.IsZero() {
+			return pod.Status.HostIP, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+const (
+	// ClusterStatusSuspended is shown in status.clusterStatus while spec.suspend is set
+	ClusterStatusSuspended = "Suspended"
+	// ClusterStatusMaintenance is shown in status.clusterStatus while spec.maintenance is enabled
+	ClusterStatusMaintenance = "Maintenance"
+
+	ReasonSuspended          = "Suspended"
+	ReasonMaintenanceStarted = "MaintenanceStarted"
+	ReasonMaintenanceDrained = "MaintenanceDrained"
+	ReasonMaintenanceEnded   = "MaintenanceEnded"
+	ReasonMaintenanceFailed  = "MaintenanceFailed"
+
+	maintenancePollInterval   = 30 * time.Second
+	maintenanceResyncInterval = 5 * time.Minute
+	activeJobStates           = "RUNNING,COMPLETING,CONFIGURING"
+)
+
+// ReconcileMaintenance drains every partition while spec.maintenance is enabled and puts each one back in the
+// state it had before once it is disabled. It only updates the status in memory and returns how long to wait
+// before checking the jobs again.
+func (r *SlurmDeploymentReconciler) ReconcileMaintenance(ctx context.Context, release *slurmv1.SlurmDeployment) (time.Duration, error) {
+	spec := release.Spec.Maintenance
+	status := release.Status.Maintenance
+
+	if !spec.Enabled {
+		if status == nil {
+			return 0, nil
+		}
+		for _, partition := range status.Partitions {
+			// a partition recorded before the prior states were kept was UP
+			state := status.PriorStates[partition]
+			if state == "" {
+				state = "UP"
+			}
+			if stateErr := utils.SetSlurmPartitionState(ctx, r.Executor, release, partition, state); stateErr != nil {
+				log.Printf("Failed to restore partition %s of %s to %s: %v", partition, release.Name, state, stateErr)
+				r.recordEvent(release, corev1.EventTypeWarning, ReasonMaintenanceFailed, "Failed to restore partition %s to %s: %v", partition, state, stateErr)
+				return 0, stateErr
+			}
+		}
+		release.Status.Maintenance = nil
+		if release.Status.ClusterStatus == ClusterStatusMaintenance {
+			release.Status.ClusterStatus = ""
+		}
+		r.recordEvent(release, corev1.EventTypeNormal, ReasonMaintenanceEnded, "Partitions %v are back in their prior state", status.Partitions)
+		return 0, nil
+	}
+
+	if status == nil {
+		now := metav1.Now()
+		status = &slurmv1.MaintenanceStatus{Phase: slurmv1.MaintenancePhaseDraining, StartedAt: &now}
+		release.Status.Maintenance = status
+		r.recordEvent(release, corev1.EventTypeNormal, ReasonMaintenanceStarted, "Taking all partitions out of service")
+	}
+	release.Status.ClusterStatus = ClusterStatusMaintenance
+
+	partitionState := spec.PartitionState
+	if partitionState == "" {
+		partitionState = "DRAIN"
+	}
+	partitionStates, partitionErr := utils.QuerySlurmPartitionStates(ctx, r.Executor, release)
+	if partitionErr != nil {
+		// slurmctld may still be starting, keep polling
+		log.Printf("Failed to list partitions of %s: %v", release.Name, partitionErr)
+		status.Message = fmt.Sprintf("Cannot list partitions: %v", partitionErr)
+		return maintenancePollInterval, nil
+	}
+	partitions := make([]string, 0, len(partitionStates))
+	for partition := range partitionStates {
+		partitions = append(partitions, partition)
+	}
+	sort.Strings(partitions)
+	// partition states are reset by a slurmctld restart, so they are applied on every pass
+	for _, partition := range partitions {
+		// the state is only recorded the first time the partition is seen, later passes see the maintenance state
+		if _, recorded := status.PriorStates[partition]; !recorded && !utils.CheckIfExistInArray(status.Partitions, partition) {
+			if status.PriorStates == nil {
+				status.PriorStates = map[string]string{}
+			}
+			status.PriorStates[partition] = partitionStates[partition]
+		}
+		if stateErr := utils.SetSlurmPartitionState(ctx, r.Executor, release, partition, partitionState); stateErr != nil {
+			log.Printf("Failed to set partition %s of %s to %s: %v", partition, release.Name, partitionState, stateErr)
+			status.Message = fmt.Sprintf("Cannot set partition %s to %s: %v", partition, partitionState, stateErr)
+			return maintenancePollInterval, nil
+		}
+		if !utils.CheckIfExistInArray(status.Partitions, partition) {
+			status.Partitions = append(status.Partitions, partition)
+		}
+	}
+
+	runningJobs, jobsErr := utils.CountSlurmJobs(ctx, r.Executor, release, activeJobStates)
+	if jobsErr != nil {
+		log.Printf("Failed to count running jobs of %s: %v", release.Name, jobsErr)
+		status.Message = fmt.Sprintf("Cannot count running jobs: %v", jobsErr)
+		return maintenancePollInterval, nil
+	}
+	status.RunningJobs = runningJobs
+	if runningJobs > 0 {
+		status.Phase = slurmv1.MaintenancePhaseDraining
+		status.DrainedAt = nil
+		status.Message = fmt.Sprintf("Partitions set to %s, waiting for %d job(s) to finish", partitionState, runningJobs)
+		return maintenancePollInterval, nil
+	}
+
+	if status.Phase != slurmv1.MaintenancePhaseDrained {
+		now := metav1.Now()
+		status.Phase = slurmv1.MaintenancePhaseDrained
+		status.DrainedAt = &now
+		r.recordEvent(release, corev1.EventTypeNormal, ReasonMaintenanceDrained, "All partitions are %s and no jobs are running", partitionState)
+	}
+	status.Message = fmt.Sprintf("Partitions set to %s, no jobs running", partitionState)
+	return maintenanceResyncInterval, nil
+}
@@ -0,0 +1,410 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+const (
+	ClusterStatusPreflightFailed = "PreflightFailed"
+	ReasonPreflightFailed        = "PreflightFailed"
+	ReasonPreflightPassed        = "PreflightPassed"
+
+	// StorageClassReadWriteManyAnnotation set to "true" or "false" on a StorageClass overrides the provisioner lookup
+	StorageClassReadWriteManyAnnotation = "slurm.ay.dev/read-write-many"
+
+	preflightRequeue = 30 * time.Second
+)
+
+// readWriteOnceProvisioners are common provisioners whose volumes cannot be mounted by several nodes
+var readWriteOnceProvisioners = map[string]bool{
+	"kubernetes.io/aws-ebs":           true,
+	"ebs.csi.aws.com":                 true,
+	"kubernetes.io/gce-pd":            true,
+	"pd.csi.storage.gke.io":           true,
+	"kubernetes.io/azure-disk":        true,
+	"disk.csi.azure.com":              true,
+	"kubernetes.io/cinder":            true,
+	"cinder.csi.openstack.org":        true,
+	"rancher.io/local-path":           true,
+	"kubernetes.io/no-provisioner":    true,
+	"openebs.io/local":                true,
+	"topolvm.io":                      true,
+	"rbd.csi.ceph.com":                true,
+	"kubernetes.io/rbd":               true,
+	"diskplugin.csi.alibabacloud.com": true,
+}
+
+// preflightCheck is the outcome of one check, reported as a condition
+type preflightCheck struct {
+	conditionType string
+	failures      []string
+}
+
+// RunPreflight validates the storage classes, pull secrets and resource quotas a release depends on and reports
+// every check as a condition. It returns false when a check failed and the helm install must wait.
+func (r *SlurmDeploymentReconciler) RunPreflight(ctx context.Context, release *slurmv1.SlurmDeployment, values *slurmv1.ValuesSpec) (bool, error) {
+	namespace := release.Spec.Chart.Namespace
+	storageClasses := &storagev1.StorageClassList{}
+	if err := r.List(ctx, storageClasses); err != nil {
+		return false, err
+	}
+
+	checks := []preflightCheck{
+		{conditionType: slurmv1.ConditionStorageClassAvailable, failures: checkStorageClasses(storageClasses.Items, values)},
+	}
+	sharedFailures, sharedErr := r.checkSharedStorage(ctx, namespace, storageClasses.Items, values)
+	if sharedErr != nil {
+		return false, sharedErr
+	}
+	checks = append(checks, preflightCheck{conditionType: slurmv1.ConditionSharedStorageReadWriteMany, failures: sharedFailures})
+	secretFailures, secretErr := r.checkImagePullSecrets(ctx, namespace, values)
+	if secretErr != nil {
+		return false, secretErr
+	}
+	checks = append(checks, preflightCheck{conditionType: slurmv1.ConditionImagePullSecretsFound, failures: secretFailures})
+	quotaFailures, quotaErr := r.checkResourceQuotas(ctx, namespace, values)
+	if quotaErr != nil {
+		return false, quotaErr
+	}
+	checks = append(checks, preflightCheck{conditionType: slurmv1.ConditionResourceQuotaSufficient, failures: quotaFailures})
+
+	var failed []string
+	for _, check := range checks {
+		condition := metav1.Condition{
+			Type:               check.conditionType,
+			Status:             metav1.ConditionTrue,
+			Reason:             "Passed",
+			Message:            "Check passed",
+			ObservedGeneration: release.Generation,
+		}
+		if len(check.failures) > 0 {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "Failed"
+			condition.Message = strings.Join(check.failures, "; ")
+			failed = append(failed, check.conditionType)
+		}
+		meta.SetStatusCondition(&release.Status.Conditions, condition)
+	}
+
+	passed := metav1.Condition{
+		Type:               slurmv1.ConditionPreflightPassed,
+		Status:             metav1.ConditionTrue,
+		Reason:             "AllChecksPassed",
+		Message:            "Every pre-flight check passed",
+		ObservedGeneration: release.Generation,
+	}
+	if len(failed) > 0 {
+		passed.Status = metav1.ConditionFalse
+		passed.Reason = "ChecksFailed"
+		passed.Message = fmt.Sprintf("Failed checks: %s", strings.Join(failed, ", "))
+	}
+	previous := meta.FindStatusCondition(release.Status.Conditions, slurmv1.ConditionPreflightPassed)
+	if previous == nil || previous.Status != passed.Status || previous.Message != passed.Message {
+		if len(failed) > 0 {
+			log.Printf("Pre-flight checks of %s failed: %s", release.Name, passed.Message)
+			r.recordEvent(release, corev1.EventTypeWarning, ReasonPreflightFailed, "Skipping the helm install: %s", passed.Message)
+		} else {
+			r.recordEvent(release, corev1.EventTypeNormal, ReasonPreflightPassed, "%s", passed.Message)
+		}
+	}
+	meta.SetStatusCondition(&release.Status.Conditions, passed)
+
+	if len(failed) > 0 {
+		release.Status.ClusterStatus = ClusterStatusPreflightFailed
+		return false, nil
+	}
+	if release.Status.ClusterStatus == ClusterStatusPreflightFailed {
+		release.Status.ClusterStatus = ""
+	}
+	return true, nil
+}
+
+// checkStorageClasses verifies that every volume provisioned by the chart has a storage class to be created with
+func checkStorageClasses(classes []storagev1.StorageClass, values *slurmv1.ValuesSpec) []string {
+	var failures []string
+	if values.Persistence.Shared.Enabled && values.Persistence.Shared.ExistingClaim == "" {
+		if _, failure := findStorageClass(classes, values.Persistence.Shared.StorageClass, "persistence.shared"); failure != "" {
+			failures = append(failures, failure)
+		}
+	}
+	for _, volume := range values.Persistence.Volumes {
+		if volume.ExistingClaim != "" {
+			continue
+		}
+		if _, failure := findStorageClass(classes, volume.StorageClass, fmt.Sprintf("persistence.volumes[%s]", volume.Name)); failure != "" {
+			failures = append(failures, failure)
+		}
+	}
+	if values.Mariadb.Enabled && values.Mariadb.Primary.Persistence.Enabled {
+		if _, failure := findStorageClass(classes, values.Mariadb.Primary.Persistence.StorageClass, "mariadb.primary.persistence"); failure != "" {
+			failures = append(failures, failure)
+		}
+	}
+	return failures
+}
+
+// findStorageClass resolves a storage class name, an empty name selects the default storage class
+func findStorageClass(classes []storagev1.StorageClass, name, field string) (*storagev1.StorageClass, string) {
+	for i := range classes {
+		class := &classes[i]
+		if name != "" && class.Name == name {
+			return class, ""
+		}
+		if name == "" && class.Annotations["storageclass.kubernetes.io/is-default-class"] == "true" {
+			return class, ""
+		}
+	}
+	if name == "" {
+		return nil, fmt.Sprintf("%s has no storageClass and the cluster has no default StorageClass", field)
+	}
+	return nil, fmt.Sprintf("StorageClass %s of %s does not exist", name, field)
+}
+
+// checkSharedStorage verifies that the shared volumes mounted by every slurmd pod can be mounted by several nodes
+func (r *SlurmDeploymentReconciler) checkSharedStorage(ctx context.Context, namespace string, classes []storagev1.StorageClass,
+	values *slurmv1.ValuesSpec) ([]string, error) {
+	var failures []string
+	if shared := values.Persistence.Shared; shared.Enabled {
+		failure, err := r.checkManyNodeStorage(ctx, namespace, classes, "persistence.shared", shared.ExistingClaim,
+			shared.AccessModes, shared.StorageClass, false)
+		if err != nil {
+			return nil, err
+		}
+		if failure != "" {
+			failures = append(failures, failure)
+		}
+	}
+	for i := range values.Persistence.Volumes {
+		volume := &values.Persistence.Volumes[i]
+		accessModes := []string{}
+		for _, mode := range volume.AccessModes {
+			accessModes = append(accessModes, string(mode))
+		}
+		failure, err := r.checkManyNodeStorage(ctx, namespace, classes, fmt.Sprintf("persistence.volumes[%s]", volume.Name),
+			volume.ExistingClaim, accessModes, volume.StorageClass, volume.ReadOnly)
+		if err != nil {
+			return nil, err
+		}
+		if failure != "" {
+			failures = append(failures, failure)
+		}
+	}
+	return failures, nil
+}
+
+// checkManyNodeStorage verifies that a volume can be mounted ReadWriteMany, or ReadOnlyMany when it is mounted read-only.
+// It returns the failure of the check, empty when it passed.
+func (r *SlurmDeploymentReconciler) checkManyNodeStorage(ctx context.Context, namespace string, classes []storagev1.StorageClass,
+	field, existingClaim string, accessModes []string, storageClass string, readOnly bool) (string, error) {
+	manyNodes := func(mode string) bool {
+		return mode == string(corev1.ReadWriteMany) || (readOnly && mode == string(corev1.ReadOnlyMany))
+	}
+	required := "ReadWriteMany"
+	if readOnly {
+		required = "ReadWriteMany or ReadOnlyMany"
+	}
+	if existingClaim != "" {
+		claim := &corev1.PersistentVolumeClaim{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: existingClaim}, claim); err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Sprintf("existing claim %s of %s does not exist", existingClaim, field), nil
+			}
+			return "", err
+		}
+		for _, mode := range claim.Spec.AccessModes {
+			if manyNodes(string(mode)) {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("existing claim %s of %s is not %s", existingClaim, field, required), nil
+	}
+
+	if len(accessModes) > 0 {
+		supported := false
+		for _, mode := range accessModes {
+			supported = supported || manyNodes(mode)
+		}
+		if !supported {
+			return fmt.Sprintf("%s.accessModes must include %s, the volume is mounted by every slurmd pod", field, required), nil
+		}
+	}
+	class, _ := findStorageClass(classes, storageClass, field)
+	if class == nil {
+		// reported by the StorageClassAvailable check
+		return "", nil
+	}
+	if override, ok := class.Annotations[StorageClassReadWriteManyAnnotation]; ok {
+		if override == "true" {
+			return "", nil
+		}
+		return fmt.Sprintf("StorageClass %s is marked without ReadWriteMany support", class.Name), nil
+	}
+	if readWriteOnceProvisioners[class.Provisioner] {
+		return fmt.Sprintf("StorageClass %s uses provisioner %s which does not support ReadWriteMany, set the %s annotation to override",
+			class.Name, class.Provisioner, StorageClassReadWriteManyAnnotation), nil
+	}
+	return "", nil
+}
+
+// checkImagePullSecrets verifies that every pull secret referenced by the component images exists
+func (r *SlurmDeploymentReconciler) checkImagePullSecrets(ctx context.Context, namespace string, values *slurmv1.ValuesSpec) ([]string, error) {
+	referenced := map[string]bool{}
+	for _, image := range []slurmv1.ImageSpec{values.Munged.Image, values.Slurmctld.Image, values.SlurmdCPU.Image,
+		values.SlurmdGPU.Image, values.Slurmdbd.Image, values.SlurmLogin.Image} {
+		for _, name := range image.PullSecrets {
+			referenced[name] = true
+		}
+	}
+	var missing []string
+	for name := range referenced {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	sort.Strings(missing)
+	return []string{fmt.Sprintf("image pull secrets not found in namespace %s: %s", namespace, strings.Join(missing, ", "))}, nil
+}
+
+// checkResourceQuotas verifies that the slurmd pods fit into the remaining ResourceQuota of the chart namespace
+func (r *SlurmDeploymentReconciler) checkResourceQuotas(ctx context.Context, namespace string, values *slurmv1.ValuesSpec) ([]string, error) {
+	quotas := &corev1.ResourceQuotaList{}
+	if err := r.List(ctx, quotas, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	if len(quotas.Items) == 0 {
+		return nil, nil
+	}
+	requested, parseErr := slurmdResourceTotals(values)
+	if parseErr != nil {
+		return []string{parseErr.Error()}, nil
+	}
+
+	var failures []string
+	for _, quota := range quotas.Items {
+		hard := quota.Status.Hard
+		if len(hard) == 0 {
+			hard = quota.Spec.Hard
+		}
+		names := make([]string, 0, len(requested))
+		for name := range requested {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			resourceName := corev1.ResourceName(name)
+			limit, limited := hard[resourceName]
+			if !limited {
+				continue
+			}
+			available := limit.DeepCopy()
+			if used, ok := quota.Status.Used[resourceName]; ok {
+				available.Sub(used)
+			}
+			want := requested[resourceName]
+			if want.Cmp(available) > 0 {
+				failures = append(failures, fmt.Sprintf("ResourceQuota %s leaves %s of %s but slurmd requests %s",
+					quota.Name, available.String(), name, want.String()))
+			}
+		}
+	}
+	return failures, nil
+}
+
+// slurmdResourceTotals sums the requests and limits of every slurmd pod under the names a ResourceQuota uses
+func slurmdResourceTotals(values *slurmv1.ValuesSpec) (corev1.ResourceList, error) {
+	totals := corev1.ResourceList{}
+	add := func(names []corev1.ResourceName, quantity resource.Quantity) {
+		for _, name := range names {
+			total := totals[name]
+			total.Add(quantity)
+			totals[name] = total
+		}
+	}
+	type slurmdPool struct {
+		name      string
+		replicas  int32
+		resources slurmv1.SlurmdResourceSpec
+	}
+	for _, pool := range []slurmdPool{
+		{name: "slurmdCPU", replicas: values.SlurmdCPU.ReplicaCount, resources: values.SlurmdCPU.Resources},
+		{name: "slurmdGPU", replicas: values.SlurmdGPU.ReplicaCount, resources: values.SlurmdGPU.Resources},
+	} {
+		for i := int32(0); i < pool.replicas; i++ {
+			if requests := pool.resources.Requests; requests != nil {
+				cpu := resource.NewMilliQuantity(int64(requests.Socket*requests.CorePerSocket*requests.ThreadPerCore)*1000, resource.DecimalSI)
+				add([]corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceRequestsCPU}, *cpu)
+				if err := addQuantity(add, requests.Memory, pool.name+".resources.requests.memory",
+					corev1.ResourceMemory, corev1.ResourceRequestsMemory); err != nil {
+					return nil, err
+				}
+				if err := addQuantity(add, requests.EphemeralStorage, pool.name+".resources.requests.ephemeral-storage",
+					corev1.ResourceEphemeralStorage, corev1.ResourceRequestsEphemeralStorage); err != nil {
+					return nil, err
+				}
+			}
+			if limits := pool.resources.Limits; limits != nil {
+				cpu := resource.NewMilliQuantity(int64(limits.Socket*limits.CorePerSocket*limits.ThreadPerCore)*1000, resource.DecimalSI)
+				add([]corev1.ResourceName{corev1.ResourceLimitsCPU}, *cpu)
+				if err := addQuantity(add, limits.Memory, pool.name+".resources.limits.memory", corev1.ResourceLimitsMemory); err != nil {
+					return nil, err
+				}
+				if err := addQuantity(add, limits.EphemeralStorage, pool.name+".resources.limits.ephemeral-storage",
+					corev1.ResourceLimitsEphemeralStorage); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return totals, nil
+}
+
+func addQuantity(add func([]corev1.ResourceName, resource.Quantity), value, field string, names ...corev1.ResourceName) error {
+	if value == "" {
+		return nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return fmt.Errorf("%s %q is not a valid quantity: %v", field, value, err)
+	}
+	add(names, quantity)
+	return nil
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+const (
+	ReasonTopologyUpdated = "TopologyUpdated"
+	ReasonTopologyFailed  = "TopologyFailed"
+
+	// helmInstanceLabel carries the helm release name on every pod of the chart
+	helmInstanceLabel = "app.kubernetes.io/instance"
+	// topologyRetryInterval is short to reconfigure slurmctld soon after the kubelet synced topology.conf
+	topologyRetryInterval = 10 * time.Second
+)
+
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+
+// ReconcileTopology keeps the topology.conf generated from the nodes hosting slurmd pods in a ConfigMap that the
+// post-renderer mounts into slurmctld, and reconfigures slurmctld once the kubelet synced a new file. A restarted
+// slurmctld reads the file from the ConfigMap right away. It only updates the status in memory and returns how long
+// to wait before retrying a failure.
+func (r *SlurmDeploymentReconciler) ReconcileTopology(ctx context.Context, release *slurmv1.SlurmDeployment) time.Duration {
+	topology := &release.Spec.Values.SlurmConfig.Topology
+	if !topology.Enabled {
+		release.Status.Topology = nil
+		if deleteErr := r.DeleteTopologyConf(ctx, release); deleteErr != nil {
+			return topologyRetryInterval
+		}
+		return 0
+	}
+	status := release.Status.Topology
+	if status == nil {
+		status = &slurmv1.TopologyStatus{}
+		release.Status.Topology = status
+	}
+
+	nodes, nodesErr := r.slurmdTopologyNodes(ctx, release)
+	if nodesErr != nil {
+		log.Printf("Failed to resolve the nodes of the slurmd pods of %s: %v", release.Name, nodesErr)
+		status.Message = fmt.Sprintf("Cannot resolve slurmd nodes: %v", nodesErr)
+		return topologyRetryInterval
+	}
+	content, switches := utils.BuildTopologyConf(nodes)
+	if content == "" {
+		status.Message = "No slurmd pod is scheduled yet"
+		return 0
+	}
+	status.Nodes = int32(len(nodes))
+	status.Switches = int32(switches)
+
+	desired := utils.TopologyConfMap(release, content)
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
+	if _, applyErr := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Labels = desired.Labels
+		configMap.Data = desired.Data
+		return nil
+	}); applyErr != nil {
+		log.Printf("Failed to apply topology configmap %s: %v", configMap.Name, applyErr)
+		r.recordEvent(release, corev1.EventTypeWarning, ReasonTopologyFailed, "Failed to write topology.conf: %v", applyErr)
+		status.Message = fmt.Sprintf("Cannot write topology.conf: %v", applyErr)
+		return topologyRetryInterval
+	}
+	hash := utils.TopologyConfHash(content)
+	if status.Hash == hash {
+		status.Message = fmt.Sprintf("%d node(s) in %d switch(es)", len(nodes), switches)
+		return 0
+	}
+	if r.Executor == nil {
+		status.Message = "No pod executor configured"
+		return 0
+	}
+
+	// the kubelet refreshes the mounted ConfigMap within about a minute, slurmctld must not reread it before
+	current, readErr := utils.ReadTopologyConf(ctx, r.Executor, release)
+	if readErr != nil {
+		log.Printf("Failed to read topology.conf of %s: %v", release.Name, readErr)
+		status.Message = fmt.Sprintf("Cannot read topology.conf: %v", readErr)
+		return topologyRetryInterval
+	}
+	if current != content {
+		status.Message = "Waiting for slurmctld to see the new topology.conf"
+		return topologyRetryInterval
+	}
+	log.Printf("Reconfiguring slurmctld of %s for topology.conf with %d node(s) in %d switch(es)", release.Name, len(nodes), switches)
+	if _, reconfigureErr := utils.RunSlurmctldCommand(ctx, r.Executor, release, "scontrol", "reconfigure"); reconfigureErr != nil {
+		log.Printf("Failed to reconfigure slurmctld of %s: %v", release.Name, reconfigureErr)
+		r.recordEvent(release, corev1.EventTypeWarning, ReasonTopologyFailed, "Failed to reconfigure slurmctld after writing topology.conf: %v", reconfigureErr)
+		status.Message = fmt.Sprintf("Cannot reconfigure slurmctld: %v", reconfigureErr)
+		return topologyRetryInterval
+	}
+	now := metav1.Now()
+	status.Hash = hash
+	status.AppliedAt = &now
+	status.Message = fmt.Sprintf("%d node(s) in %d switch(es)", len(nodes), switches)
+	r.recordEvent(release, corev1.EventTypeNormal, ReasonTopologyUpdated, "Applied topology.conf with %d node(s) in %d switch(es)", len(nodes), switches)
+	return 0
+}
+
+// DeleteTopologyConf removes the topology ConfigMap, which is not part of the helm release
+func (r *SlurmDeploymentReconciler) DeleteTopologyConf(ctx context.Context, release *slurmv1.SlurmDeployment) error {
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      utils.TopologyConfMapName(release),
+		Namespace: release.Spec.Chart.Namespace,
+	}}
+	if err := r.Delete(ctx, configMap); err != nil && !apierrors.IsNotFound(err) {
+		log.Printf("Failed to delete topology configmap %s: %v", configMap.Name, err)
+		return err
+	}
+	return nil
+}
+
+// slurmdTopologyNodes pairs every scheduled slurmd pod, named like its slurm node, with the topology labels of
+// the kubernetes node it runs on
+func (r *SlurmDeploymentReconciler) slurmdTopologyNodes(ctx context.Context, release *slurmv1.SlurmDeployment) ([]utils.TopologyNode, error) {
+	topology := &release.Spec.Values.SlurmConfig.Topology
+	zoneLabel := utils.TopologyZoneLabel(topology)
+	kubeNodes := map[string]*corev1.Node{}
+	nodes := []utils.TopologyNode{}
+	for _, component := range []string{"slurmd-cpu", "slurmd-gpu"} {
+		pods, podsErr := r.scheduledSlurmdPods(ctx, release, component, kubeNodes)
+		if podsErr != nil {
+			return nil, podsErr
+		}
+		for _, pod := range pods {
+			node := utils.TopologyNode{Name: pod.name, Zone: pod.node.Labels[zoneLabel]}
+			if topology.RackLabel != "" {
+				node.Rack = pod.node.Labels[topology.RackLabel]
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// scheduledSlurmdPod is a slurmd pod, named like its slurm node, and the kubernetes node it runs on
+type scheduledSlurmdPod struct {
+	name string
+	node *corev1.Node
+}
+
+// scheduledSlurmdPods lists the slurmd pods of a component that are placed on a node, kubeNodes caches the nodes
+// across components
+func (r *SlurmDeploymentReconciler) scheduledSlurmdPods(ctx context.Context, release *slurmv1.SlurmDeployment, component string,
+	kubeNodes map[string]*corev1.Node) ([]scheduledSlurmdPod, error) {
+	sts, stsErr := r.RetrieveStatefulSetInfo(ctx, release.Spec.Chart.Namespace, utils.ComponentName(release, component))
+	if stsErr != nil {
+		return nil, stsErr
+	}
+	pods := &corev1.PodList{}
+	if listPodErr := r.List(ctx, pods, client.InNamespace(release.Spec.Chart.Namespace),
+		client.MatchingLabels(sts.Spec.Selector.MatchLabels)); listPodErr != nil {
+		return nil, listPodErr
+	}
+	scheduled := []scheduledSlurmdPod{}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		kubeNode, found := kubeNodes[pod.Spec.NodeName]
+		if !found {
+			kubeNode = &corev1.Node{}
+			if getNodeErr := r.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, kubeNode); getNodeErr != nil {
+				return nil, getNodeErr
+			}
+			kubeNodes[pod.Spec.NodeName] = kubeNode
+		}
+		scheduled = append(scheduled, scheduledSlurmdPod{name: pod.Name, node: kubeNode})
+	}
+	return scheduled, nil
+}
+
+// slurmDeploymentForSlurmPod maps a slurmd or slurmctld pod to the SlurmDeployment that generates topology.conf or
+// node features for it, slurmd pods change both and a new slurmctld pod has to get them again
+func (r *SlurmDeploymentReconciler) slurmDeploymentForSlurmPod(ctx context.Context, obj client.Object) []reconcile.Request {
+	instance := obj.GetLabels()[helmInstanceLabel]
+	if instance == "" {
+		return nil
+	}
+	releases := &slurmv1.SlurmDeploymentList{}
+	if listErr := r.List(ctx, releases); listErr != nil {
+		log.Printf("Failed to list SlurmDeployments for pod %s/%s: %v", obj.GetNamespace(), obj.GetName(), listErr)
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, release := range releases.Items {
+		if release.Name != instance || release.Spec.Chart.Namespace != obj.GetNamespace() ||
+			!(release.Spec.Values.SlurmConfig.Topology.Enabled || resolvesNodeFeatures(&release)) ||
+			!(strings.HasPrefix(obj.GetName(), utils.ComponentName(&release, "slurmd-")) ||
+				strings.HasPrefix(obj.GetName(), utils.ComponentName(&release, "slurmctld"))) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: release.Namespace,
+			Name:      release.Name,
+		}})
+	}
+	return requests
+}
+
+// slurmPodScheduled passes pod events that place a pod on a node or make it ready, the rest of the pod lifecycle
+// does not change the topology or the node features. A restarted slurmctld has forgotten the features pushed through
+// scontrol and a slurmd only registers its node once ready, both need the features again as soon as they are up.
+var slurmPodScheduled = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		pod, ok := e.Object.(*corev1.Pod)
+		return ok && pod.Spec.NodeName != ""
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldPod, oldOK := e.ObjectOld.(*corev1.Pod)
+		newPod, newOK := e.ObjectNew.(*corev1.Pod)
+		return oldOK && newOK && (oldPod.Spec.NodeName != newPod.Spec.NodeName || !podReady(oldPod) && podReady(newPod))
+	},
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// podReady tells whether the Ready condition of a pod is true
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+const (
+	ReasonVersionUpgradeStarted   = "VersionUpgradeStarted"
+	ReasonVersionUpgradeStage     = "VersionUpgradeStageCompleted"
+	ReasonVersionUpgradeCompleted = "VersionUpgradeCompleted"
+	ReasonVersionUpgradeRefused   = "VersionUpgradeRefused"
+
+	versionUpgradePollInterval = 15 * time.Second
+)
+
+// versionUpgradeStage is a group of components upgraded together
+type versionUpgradeStage struct {
+	name       string
+	components []string
+}
+
+// versionUpgradeStages follows the Slurm upgrade order: slurmdbd migrates the accounting schema first, slurmctld
+// converts its state next and the slurmd daemons and clients come last
+var versionUpgradeStages = []versionUpgradeStage{
+	{name: "slurmdbd", components: []string{utils.SlurmComponentSlurmdbd}},
+	{name: "slurmctld", components: []string{utils.SlurmComponentSlurmctld}},
+	{name: "slurmd", components: []string{utils.SlurmComponentSlurmdCPU, utils.SlurmComponentSlurmdGPU, utils.SlurmComponentLogin}},
+}
+
+// PrepareVersionUpgrade returns the values the next helm action renders. When the image tags change the Slurm version,
+// components of later stages keep their applied images until the earlier stages are healthy. A wait above zero means
+// the current stage is still rolling out and helm has to be skipped; the status is changed but not saved.
+func (r *SlurmDeploymentReconciler) PrepareVersionUpgrade(ctx context.Context, release *slurmv1.SlurmDeployment) (*slurmv1.ValuesSpec, time.Duration, error) {
+	values := release.Spec.Values
+	desired := utils.SlurmComponentImages(&values)
+	applied := release.Status.AppliedImages
+	toVersion, toFound, toErr := utils.SlurmImagesVersion(desired)
+	if len(applied) == 0 {
+		// first install, or a release created before the operator tracked the applied images
+		if toFound {
+			release.Status.SlurmVersion = toVersion.String()
+		}
+		return &values, 0, nil
+	}
+
+	upgrade := release.Status.VersionUpgrade
+	inProgress := upgrade != nil && upgrade.Phase == slurmv1.VersionUpgradePhaseInProgress
+	if inProgress {
+		if toErr != nil || !toFound || toVersion.String() != upgrade.ToVersion {
+			upgrade.Message = fmt.Sprintf("The upgrade to %s is in progress, set the image tags back to %s to continue",
+				upgrade.ToVersion, upgrade.ToVersion)
+			return stageValues(values, applied, -1), 0, nil
+		}
+	} else {
+		fromVersion, fromFound := r.runningSlurmVersion(release)
+		if toErr != nil {
+			r.refuseVersionUpgrade(release, release.Status.SlurmVersion, "", toErr.Error())
+			return stageValues(values, applied, -1), 0, nil
+		}
+		if !toFound || !fromFound || toVersion == fromVersion {
+			if toFound {
+				release.Status.SlurmVersion = toVersion.String()
+			}
+			if upgrade != nil && upgrade.Phase == slurmv1.VersionUpgradePhaseRefused {
+				release.Status.VersionUpgrade = nil
+			}
+			return &values, 0, nil
+		}
+		if checkErr := utils.CheckSlurmUpgrade(fromVersion, toVersion); checkErr != nil {
+			r.refuseVersionUpgrade(release, fromVersion.String(), toVersion.String(), checkErr.Error())
+			return stageValues(values, applied, -1), 0, nil
+		}
+
+		now := metav1.Now()
+		upgrade = &slurmv1.VersionUpgradeStatus{
+			Phase:       slurmv1.VersionUpgradePhaseInProgress,
+			FromVersion: fromVersion.String(),
+			ToVersion:   toVersion.String(),
+			Stage:       versionUpgradeStages[0].name,
+			StartedAt:   &now,
+		}
+		release.Status.VersionUpgrade = upgrade
+		log.Printf("Upgrading Slurm of %s from %s to %s", release.Name, upgrade.FromVersion, upgrade.ToVersion)
+		r.recordEvent(release, corev1.EventTypeNormal, ReasonVersionUpgradeStarted, "Upgrading Slurm from %s to %s, starting with %s",
+			upgrade.FromVersion, upgrade.ToVersion, upgrade.Stage)
+	}
+
+	for i := stageIndex(upgrade.Stage); i < len(versionUpgradeStages); i++ {
+		stage := versionUpgradeStages[i]
+		upgrade.Stage = stage.name
+		for _, component := range stage.components {
+			if !utils.SameImage(applied[component], desired[component]) {
+				upgrade.Message = fmt.Sprintf("Rolling out %s %s", stage.name, upgrade.ToVersion)
+				return stageValues(values, applied, i), 0, nil
+			}
+		}
+
+		healthy, message, healthErr := r.versionUpgradeStageHealthy(ctx, release, stage)
+		if healthErr != nil {
+			return nil, 0, healthErr
+		}
+		if !healthy {
+			upgrade.Message = message
+			return nil, versionUpgradePollInterval, nil
+		}
+		if i < len(versionUpgradeStages)-1 {
+			r.recordEvent(release, corev1.EventTypeNormal, ReasonVersionUpgradeStage, "%s runs Slurm %s", stage.name, upgrade.ToVersion)
+		}
+	}
+
+	now := metav1.Now()
+	upgrade.Phase = slurmv1.VersionUpgradePhaseCompleted
+	upgrade.Stage = ""
+	upgrade.Message = fmt.Sprintf("Every component runs Slurm %s", upgrade.ToVersion)
+	upgrade.CompletedAt = &now
+	release.Status.SlurmVersion = upgrade.ToVersion
+	log.Printf("Upgraded Slurm of %s to %s", release.Name, upgrade.ToVersion)
+	r.recordEvent(release, corev1.EventTypeNormal, ReasonVersionUpgradeCompleted, "Upgraded Slurm from %s to %s", upgrade.FromVersion, upgrade.ToVersion)
+	return &values, 0, nil
+}
+
+// runningSlurmVersion is the version recorded by the last completed rollout, or the one of the applied images
+func (r *SlurmDeploymentReconciler) runningSlurmVersion(release *slurmv1.SlurmDeployment) (utils.SlurmVersion, bool) {
+	if version, ok := utils.ParseSlurmVersion(release.Status.SlurmVersion); ok {
+		return version, true
+	}
+	version, found, err := utils.SlurmImagesVersion(release.Status.AppliedImages)
+	return version, found && err == nil
+}
+
+func (r *SlurmDeploymentReconciler) refuseVersionUpgrade(release *slurmv1.SlurmDeployment, from, to, message string) {
+	previous := release.Status.VersionUpgrade
+	if previous == nil || previous.Phase != slurmv1.VersionUpgradePhaseRefused || previous.Message != message {
+		log.Printf("Refusing Slurm version change of %s: %s", release.Name, message)
+		r.recordEvent(release, corev1.EventTypeWarning, ReasonVersionUpgradeRefused, "Keeping the running Slurm images: %s", message)
+	}
+	release.Status.VersionUpgrade = &slurmv1.VersionUpgradeStatus{
+		Phase:       slurmv1.VersionUpgradePhaseRefused,
+		FromVersion: from,
+		ToVersion:   to,
+		Message:     message,
+	}
+}
+
+// stageValues keeps the applied images for every stage after the given one, -1 keeps all of them
+func stageValues(values slurmv1.ValuesSpec, applied map[string]slurmv1.ImageSpec, stage int) *slurmv1.ValuesSpec {
+	for i := stage + 1; i < len(versionUpgradeStages); i++ {
+		for _, component := range versionUpgradeStages[i].components {
+			if image, ok := applied[component]; ok {
+				utils.SetSlurmComponentImage(&values, component, image)
+			}
+		}
+	}
+	return &values
+}
+
+func stageIndex(name string) int {
+	for i, stage := range versionUpgradeStages {
+		if stage.name == name {
+			return i
+		}
+	}
+	return 0
+}
+
+// versionUpgradeStageHealthy checks that the workloads of a stage finished rolling out and that the upgraded daemon
+// answers: slurmdbd only accepts connections once the accounting schema is migrated
+func (r *SlurmDeploymentReconciler) versionUpgradeStageHealthy(ctx context.Context, release *slurmv1.SlurmDeployment,
+	stage versionUpgradeStage) (bool, string, error) {
+	namespace := release.Spec.Chart.Namespace
+	switch stage.name {
+	case "slurmdbd", "slurmctld":
+		if done, message, err := r.statefulSetRolledOut(ctx, namespace, utils.ComponentName(release, stage.name)); err != nil || !done {
+			return false, message, err
+		}
+	case "slurmd":
+		for _, name := range []string{utils.ComponentName(release, "slurmd-cpu"), utils.ComponentName(release, "slurmd-gpu")} {
+			if done, message, err := r.statefulSetRolledOut(ctx, namespace, name); err != nil || !done {
+				return false, message, err
+			}
+		}
+		login := &appsv1.Deployment{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: utils.ComponentName(release, "login")}, login); err != nil {
+			if apierrors.IsNotFound(err) {
+				return true, "", nil
+			}
+			return false, "", err
+		}
+		replicas := replicasOf(login.Spec.Replicas)
+		if login.Status.ObservedGeneration < login.Generation || login.Status.UpdatedReplicas < replicas || login.Status.AvailableReplicas < replicas {
+			return false, fmt.Sprintf("Waiting for %s to roll out", login.Name), nil
+		}
+		return true, "", nil
+	}
+
+	if r.Executor == nil {
+		return true, "", nil
+	}
+	switch stage.name {
+	case "slurmdbd":
+		if _, err := utils.RunSlurmctldCommand(ctx, r.Executor, release, "sacctmgr", "--noheader", "--parsable2", "show", "cluster"); err != nil {
+			return false, fmt.Sprintf("Waiting for slurmdbd to finish the accounting schema migration: %v", err), nil
+		}
+	case "slurmctld":
+		output, err := utils.RunSlurmctldCommand(ctx, r.Executor, release, "scontrol", "ping")
+		if err != nil || !strings.Contains(output, "UP") {
+			return false, fmt.Sprintf("Waiting for slurmctld to respond: %s %v", strings.TrimSpace(output), err), nil
+		}
+	}
+	return true, "", nil
+}
+
+// statefulSetRolledOut reports whether every replica of a StatefulSet runs its latest revision and is ready
+func (r *SlurmDeploymentReconciler) statefulSetRolledOut(ctx context.Context, namespace, name string) (bool, string, error) {
+	sts := &appsv1.StatefulSet{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, "", nil
+		}
+		return false, "", err
+	}
+	replicas := replicasOf(sts.Spec.Replicas)
+	if sts.Status.ObservedGeneration < sts.Generation || sts.Status.UpdatedReplicas < replicas || sts.Status.ReadyReplicas < replicas ||
+		(sts.Status.UpdateRevision != "" && sts.Status.CurrentRevision != sts.Status.UpdateRevision) {
+		return false, fmt.Sprintf("Waiting for %s to roll out (%d/%d updated, %d ready)", name, sts.Status.UpdatedReplicas, replicas, sts.Status.ReadyReplicas), nil
+	}
+	return true, "", nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+const ReasonSharedVolumesFailed = "SharedVolumesFailed"
+
+// ReconcileSharedVolumes creates the PVCs of the shared volumes without an existing claim before the chart mounts
+// them. The PVCs are never deleted by the operator, removing a volume from the list or deleting the SlurmDeployment
+// keeps the data; only the requested size of a bound PVC is updated, and only to grow.
+func (r *SlurmDeploymentReconciler) ReconcileSharedVolumes(ctx context.Context, release *slurmv1.SlurmDeployment, values *slurmv1.ValuesSpec) error {
+	for i := range values.Persistence.Volumes {
+		volume := &values.Persistence.Volumes[i]
+		if volume.ExistingClaim != "" {
+			continue
+		}
+		size, err := resource.ParseQuantity(volume.Size)
+		if err != nil {
+			return fmt.Errorf("invalid size %q of shared volume %s: %v", volume.Size, volume.Name, err)
+		}
+
+		claim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.SharedVolumeClaimName(release, volume),
+			Namespace: release.Spec.Chart.Namespace,
+		}}
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, claim, func() error {
+			if claim.Labels == nil {
+				claim.Labels = map[string]string{}
+			}
+			for key, value := range sharedVolumeLabels(release, volume) {
+				claim.Labels[key] = value
+			}
+			if claim.CreationTimestamp.IsZero() {
+				claim.Spec.AccessModes = utils.SharedVolumeAccessModes(volume)
+				if volume.StorageClass != "" {
+					claim.Spec.StorageClassName = &volume.StorageClass
+				}
+			}
+			if current, found := claim.Spec.Resources.Requests[corev1.ResourceStorage]; !found || size.Cmp(current) > 0 {
+				claim.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: size}
+			}
+			return nil
+		}); err != nil {
+			log.Printf("Failed to apply shared volume claim %s: %v", claim.Name, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func sharedVolumeLabels(release *slurmv1.SlurmDeployment, volume *slurmv1.SharedVolumeSpec) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       "slurm-shared-volume",
+		"app.kubernetes.io/instance":   release.Name,
+		"app.kubernetes.io/component":  volume.Name,
+		"app.kubernetes.io/managed-by": "slurm-operator",
+	}
+}
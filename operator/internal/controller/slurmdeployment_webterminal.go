@@ -0,0 +1,174 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+	"github.com/AaronYang0628/slurm-on-k8s/internal/webterminal"
+)
+
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;create;update;patch;delete
+
+const (
+	ReasonWebTerminalFailed = "WebTerminalFailed"
+
+	webTerminalComponent = "web-terminal"
+	webTerminalPortName  = "http"
+)
+
+// ReconcileWebTerminal routes the web terminal of a SlurmDeployment to the terminal server of the operator, or removes
+// the route when the terminal is disabled. The terminal is served by the operator itself, so the release namespace
+// only gets an ExternalName Service pointing at the operator and, when configured, an Ingress in front of it.
+func (r *SlurmDeploymentReconciler) ReconcileWebTerminal(ctx context.Context, release *slurmv1.SlurmDeployment) error {
+	terminal := release.Spec.Values.SlurmLogin.WebTerminal
+	if !terminal.Enabled {
+		release.Status.WebTerminalURL = ""
+		return r.DeleteWebTerminal(ctx, release)
+	}
+	if r.WebTerminalService == "" {
+		release.Status.WebTerminalURL = ""
+		r.recordEvent(release, corev1.EventTypeWarning, ReasonWebTerminalFailed,
+			"The web terminal is enabled but the operator does not serve web terminals, see --web-terminal-bind-address")
+		return r.DeleteWebTerminal(ctx, release)
+	}
+	host, portValue, err := net.SplitHostPort(r.WebTerminalService)
+	if err != nil {
+		return fmt.Errorf("invalid web terminal service %q: %v", r.WebTerminalService, err)
+	}
+	port, err := strconv.Atoi(portValue)
+	if err != nil {
+		return fmt.Errorf("invalid web terminal service port %q: %v", portValue, err)
+	}
+
+	name := utils.ComponentName(release, webTerminalComponent)
+	namespace := release.Spec.Chart.Namespace
+	labels := webTerminalLabels(release)
+	path := webterminal.PathPrefix + release.Namespace + "/" + release.Name
+
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
+		service.Labels = labels
+		service.Spec.Type = corev1.ServiceTypeExternalName
+		service.Spec.ExternalName = host
+		service.Spec.Ports = []corev1.ServicePort{{
+			Name:     webTerminalPortName,
+			Port:     int32(port),
+			Protocol: corev1.ProtocolTCP,
+		}}
+		return nil
+	}); err != nil {
+		log.Printf("Failed to apply web terminal service %s: %v", name, err)
+		return err
+	}
+
+	if terminal.Ingress == nil {
+		if err := r.deleteWebTerminalObject(ctx, &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}); err != nil {
+			return err
+		}
+		release.Status.WebTerminalURL = fmt.Sprintf("http://%s.%s.svc:%d%s/", name, namespace, port, path)
+		return nil
+	}
+
+	ingress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, ingress, func() error {
+		ingress.Labels = labels
+		ingress.Annotations = terminal.Ingress.Annotations
+		ingress.Spec = webTerminalIngressSpec(terminal.Ingress, name, int32(port), path)
+		return nil
+	}); err != nil {
+		log.Printf("Failed to apply web terminal ingress %s: %v", name, err)
+		return err
+	}
+	scheme := "http"
+	if terminal.Ingress.TLSSecretName != "" {
+		scheme = "https"
+	}
+	release.Status.WebTerminalURL = fmt.Sprintf("%s://%s%s/", scheme, terminal.Ingress.Host, path)
+	return nil
+}
+
+// DeleteWebTerminal removes the Service and Ingress created for the web terminal of a SlurmDeployment
+func (r *SlurmDeploymentReconciler) DeleteWebTerminal(ctx context.Context, release *slurmv1.SlurmDeployment) error {
+	name := utils.ComponentName(release, webTerminalComponent)
+	namespace := release.Spec.Chart.Namespace
+	for _, object := range []client.Object{
+		&networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+	} {
+		if err := r.deleteWebTerminalObject(ctx, object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *SlurmDeploymentReconciler) deleteWebTerminalObject(ctx context.Context, object client.Object) error {
+	if err := r.Delete(ctx, object); err != nil && !apierrors.IsNotFound(err) {
+		log.Printf("Failed to delete web terminal object %s: %v", object.GetName(), err)
+		return err
+	}
+	return nil
+}
+
+// webTerminalIngressSpec routes the terminal path of the host to the web terminal Service. The backend port is given
+// by number, ingress controllers cannot look up the named ports of ExternalName Services.
+func webTerminalIngressSpec(spec *slurmv1.WebTerminalIngressSpec, serviceName string, port int32, path string) networkingv1.IngressSpec {
+	pathType := networkingv1.PathTypePrefix
+	ingressSpec := networkingv1.IngressSpec{
+		IngressClassName: spec.ClassName,
+		Rules: []networkingv1.IngressRule{{
+			Host: spec.Host,
+			IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{{
+					Path:     path,
+					PathType: &pathType,
+					Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+						Name: serviceName,
+						Port: networkingv1.ServiceBackendPort{Number: port},
+					}},
+				}},
+			}},
+		}},
+	}
+	if spec.TLSSecretName != "" {
+		ingressSpec.TLS = []networkingv1.IngressTLS{{Hosts: []string{spec.Host}, SecretName: spec.TLSSecretName}}
+	}
+	return ingressSpec
+}
+
+func webTerminalLabels(release *slurmv1.SlurmDeployment) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       "slurm-web-terminal",
+		"app.kubernetes.io/instance":   release.Name,
+		"app.kubernetes.io/component":  webTerminalComponent,
+		"app.kubernetes.io/managed-by": "slurm-operator",
+	}
+}
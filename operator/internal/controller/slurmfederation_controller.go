@@ -0,0 +1,260 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+// SlurmFederationReconciler reconciles a SlurmFederation object
+type SlurmFederationReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Executor utils.PodExecutor
+}
+
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmfederations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmfederations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmfederations/finalizers,verbs=update
+
+// Reconcile creates the federation of a SlurmFederation through sacctmgr, keeps its cluster list equal to the
+// members and reports how the siblings see each other.
+func (r *SlurmFederationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	federation := &slurmv1.SlurmFederation{}
+	if findFederationErr := r.Get(ctx, req.NamespacedName, federation); findFederationErr != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(findFederationErr)
+	}
+	federationName := federation.Spec.FederationName
+	if federationName == "" {
+		federationName = federation.Name
+	}
+
+	members, unresolved, findMembersErr := r.findFederationMembers(ctx, federation)
+	if findMembersErr != nil {
+		return ctrl.Result{}, findMembersErr
+	}
+
+	if !federation.ObjectMeta.DeletionTimestamp.IsZero() {
+		if utils.CheckIfExistInArray(federation.ObjectMeta.Finalizers, SlurmAccountingFinalizer) {
+			if len(members) > 0 {
+				log.Printf("Deleting slurm federation %s", federationName)
+				if _, deleteErr := utils.RunSacctmgr(ctx, r.Executor, members[0], "delete", "federation", federationName); deleteErr != nil && !utils.IsSacctmgrNothingChanged(deleteErr) {
+					log.Printf("Failed to delete slurm federation %s: %v", federationName, deleteErr)
+					return ctrl.Result{}, deleteErr
+				}
+			} else {
+				log.Printf("No member of federation %s is left, skipping sacctmgr cleanup", federationName)
+			}
+			federation.ObjectMeta.Finalizers = utils.SplitHeadArray(federation.ObjectMeta.Finalizers, SlurmAccountingFinalizer)
+			if updateErr := r.Update(ctx, federation); updateErr != nil {
+				return ctrl.Result{}, updateErr
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if len(unresolved) > 0 {
+		return r.updateFederationStatus(ctx, federation, AccountingPhasePending, strings.Join(unresolved, "; "), nil, 30*time.Second)
+	}
+	clusters := []string{}
+	for _, member := range members {
+		if member.Status.ClusterName == "" {
+			return r.updateFederationStatus(ctx, federation, AccountingPhasePending,
+				fmt.Sprintf("SlurmDeployment %s is not installed yet", member.Name), nil, 30*time.Second)
+		}
+		clusters = append(clusters, member.Status.ClusterName)
+	}
+	if sharedErr := r.checkSharedSlurmdbd(ctx, members); sharedErr != nil {
+		return r.updateFederationStatus(ctx, federation, AccountingPhaseError, sharedErr.Error(), nil, 0)
+	}
+
+	if !utils.CheckIfExistInArray(federation.ObjectMeta.Finalizers, SlurmAccountingFinalizer) {
+		log.Printf("Adding finalizer to SlurmFederation %s", federation.Name)
+		federation.ObjectMeta.Finalizers = append(federation.ObjectMeta.Finalizers, SlurmAccountingFinalizer)
+		if updateErr := r.Update(ctx, federation); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// every member reports to the same slurmdbd, so any slurmctld can change the federation
+	executor := members[0]
+	sort.Strings(clusters)
+	rows, queryErr := utils.QuerySacctmgr(ctx, r.Executor, executor, "federation", []string{"where", "name=" + federationName}, []string{"Federation", "Cluster"})
+	if queryErr != nil {
+		log.Printf("Failed to query slurm federation %s: %v", federationName, queryErr)
+		_, _ = r.updateFederationStatus(ctx, federation, AccountingPhaseError, queryErr.Error(), federation.Status.Members, 0)
+		return ctrl.Result{}, queryErr
+	}
+	current := []string{}
+	for _, row := range rows {
+		if row["Cluster"] != "" {
+			current = append(current, row["Cluster"])
+		}
+	}
+	sort.Strings(current)
+	clusterList := "clusters=" + strings.Join(clusters, ",")
+	if len(rows) == 0 {
+		log.Printf("Creating slurm federation %s with clusters %v", federationName, clusters)
+		if _, addErr := utils.RunSacctmgr(ctx, r.Executor, executor, "add", "federation", federationName, clusterList); addErr != nil && !utils.IsSacctmgrNothingChanged(addErr) {
+			_, _ = r.updateFederationStatus(ctx, federation, AccountingPhaseError, addErr.Error(), nil, 0)
+			return ctrl.Result{}, addErr
+		}
+	} else if strings.Join(current, ",") != strings.Join(clusters, ",") {
+		log.Printf("Slurm federation %s has clusters %v, setting %v", federationName, current, clusters)
+		if _, modifyErr := utils.RunSacctmgr(ctx, r.Executor, executor, "modify", "federation", "where", "name="+federationName, "set", clusterList); modifyErr != nil && !utils.IsSacctmgrNothingChanged(modifyErr) {
+			_, _ = r.updateFederationStatus(ctx, federation, AccountingPhaseError, modifyErr.Error(), federation.Status.Members, 0)
+			return ctrl.Result{}, modifyErr
+		}
+	}
+
+	memberStatus, connected := r.querySiblings(ctx, federation, members)
+	message := fmt.Sprintf("Federation %s has %d member(s)", federationName, len(members))
+	requeueAfter := accountingResyncInterval
+	if connected < len(members) {
+		message = fmt.Sprintf("Federation %s has %d of %d member(s) connected", federationName, connected, len(members))
+		requeueAfter = time.Minute
+	}
+	return r.updateFederationStatus(ctx, federation, AccountingPhaseSynced, message, memberStatus, requeueAfter)
+}
+
+// findFederationMembers resolves the members of a federation and explains why the others cannot be resolved, they
+// either do not exist or do not allow references from the namespace of the federation
+func (r *SlurmFederationReconciler) findFederationMembers(ctx context.Context, federation *slurmv1.SlurmFederation) ([]*slurmv1.SlurmDeployment, []string, error) {
+	members := []*slurmv1.SlurmDeployment{}
+	unresolved := []string{}
+	for _, member := range federation.Spec.Members {
+		ref := slurmv1.SlurmDeploymentReference{Name: member.Name, Namespace: member.Namespace}
+		release, findReleaseErr := findSlurmDeployment(ctx, r.Client, federation.Namespace, ref)
+		if findReleaseErr != nil {
+			if apierrors.IsNotFound(findReleaseErr) {
+				unresolved = append(unresolved, fmt.Sprintf("SlurmDeployment %s not found", slurmDeploymentKey(federation.Namespace, ref)))
+				continue
+			}
+			if errors.Is(findReleaseErr, errReferenceNotAllowed) {
+				unresolved = append(unresolved, findReleaseErr.Error())
+				continue
+			}
+			return nil, nil, findReleaseErr
+		}
+		members = append(members, release)
+	}
+	return members, unresolved, nil
+}
+
+// checkSharedSlurmdbd verifies that every member reports to the same slurmdbd, federations live in one accounting
+// database. A slurmdbdRef is resolved to the slurmdbd of the referenced release, in whatever namespace it lives.
+func (r *SlurmFederationReconciler) checkSharedSlurmdbd(ctx context.Context, members []*slurmv1.SlurmDeployment) error {
+	hosts := map[string][]string{}
+	for _, member := range members {
+		host := slurmdbdServiceHost(member)
+		accounting := member.Spec.Values.SlurmConfig.Accounting
+		if accounting.SlurmdbdRef != nil {
+			owner, findOwnerErr := findSlurmDeployment(ctx, r.Client, member.Namespace, *accounting.SlurmdbdRef)
+			if findOwnerErr != nil {
+				return fmt.Errorf("failed to find the slurmdbd of %s/%s: %v", member.Namespace, member.Name, findOwnerErr)
+			}
+			host = slurmdbdServiceHost(owner)
+		} else if accounting.StorageHost != "" {
+			host = accounting.StorageHost
+		}
+		host = normalizeSlurmdbdHost(host)
+		hosts[host] = append(hosts[host], member.Namespace+"/"+member.Name)
+	}
+	if len(hosts) > 1 {
+		groups := []string{}
+		for host, names := range hosts {
+			groups = append(groups, fmt.Sprintf("%s uses %s", strings.Join(names, ", "), host))
+		}
+		sort.Strings(groups)
+		return fmt.Errorf("members do not share one slurmdbd: %s", strings.Join(groups, "; "))
+	}
+	return nil
+}
+
+// querySiblings reports every member as seen by the first member that answers `scontrol show federation`
+func (r *SlurmFederationReconciler) querySiblings(ctx context.Context, federation *slurmv1.SlurmFederation,
+	members []*slurmv1.SlurmDeployment) ([]slurmv1.SlurmFederationMemberStatus, int) {
+	var siblings map[string]utils.FederationSibling
+	for _, member := range members {
+		output, showErr := utils.RunSlurmctldCommand(ctx, r.Executor, member, "scontrol", "show", "federation")
+		if showErr != nil {
+			log.Printf("Failed to show the federation of %s: %v", member.Name, showErr)
+			continue
+		}
+		siblings = utils.ParseFederationStatus(output)
+		break
+	}
+
+	statuses := []slurmv1.SlurmFederationMemberStatus{}
+	connected := 0
+	for _, member := range members {
+		status := slurmv1.SlurmFederationMemberStatus{
+			Name:        member.Name,
+			Namespace:   member.Namespace,
+			ClusterName: member.Status.ClusterName,
+			FedState:    "Unknown",
+		}
+		if sibling, found := siblings[member.Status.ClusterName]; found {
+			status.FedState = sibling.FedState
+			status.Connected = sibling.Connected
+			status.Synced = sibling.Synced
+		}
+		if status.Connected {
+			connected++
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, connected
+}
+
+func (r *SlurmFederationReconciler) updateFederationStatus(ctx context.Context, federation *slurmv1.SlurmFederation, phase, message string,
+	members []slurmv1.SlurmFederationMemberStatus, requeueAfter time.Duration) (ctrl.Result, error) {
+	now := metav1.Now()
+	federation.Status.Phase = phase
+	federation.Status.Message = message
+	federation.Status.Members = members
+	federation.Status.ObservedGeneration = federation.Generation
+	federation.Status.LastSyncTime = &now
+	if updateStatusErr := r.Status().Update(ctx, federation); updateStatusErr != nil {
+		log.Printf("Failed to update SlurmFederation %s status: %v", federation.Name, updateStatusErr)
+		return ctrl.Result{}, updateStatusErr
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SlurmFederationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&slurmv1.SlurmFederation{}).
+		Complete(r)
+}
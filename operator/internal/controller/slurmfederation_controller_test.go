@@ -0,0 +1,149 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+var _ = Describe("SlurmFederation Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		slurmfederation := &slurmv1.SlurmFederation{}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind SlurmFederation")
+			err := k8sClient.Get(ctx, typeNamespacedName, slurmfederation)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &slurmv1.SlurmFederation{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: slurmv1.SlurmFederationSpec{
+						Members: []slurmv1.SlurmFederationMember{{Name: "missing-deployment"}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &slurmv1.SlurmFederation{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance SlurmFederation")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+		It("should wait for the missing members", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := &SlurmFederationReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &slurmv1.SlurmFederation{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Phase).To(Equal(AccountingPhasePending))
+			Expect(resource.Status.Message).To(ContainSubstring("default/missing-deployment"))
+		})
+	})
+})
+
+var _ = Describe("SlurmFederation shared slurmdbd", func() {
+	newRelease := func(namespace, name string) *slurmv1.SlurmDeployment {
+		return &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: namespace}},
+		}
+	}
+
+	var (
+		accounting *slurmv1.SlurmDeployment
+		reconciler *SlurmFederationReconciler
+	)
+
+	BeforeEach(func() {
+		accounting = newRelease("accounting", "central")
+		accounting.Spec.AllowedReferenceNamespaces = []string{"team-east"}
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		reconciler = &SlurmFederationReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(accounting).Build(),
+			Scheme: scheme,
+		}
+	})
+
+	It("resolves slurmdbdRef across namespaces and a storageHost naming the same slurmdbd", func() {
+		east := newRelease("team-east", "east")
+		east.Spec.Values.SlurmConfig.Accounting.SlurmdbdRef = &slurmv1.SlurmDeploymentReference{Name: "central", Namespace: "accounting"}
+		west := newRelease("team-west", "west")
+		west.Spec.Values.SlurmConfig.Accounting.StorageHost =
+			"central-slurm-slurmdbd-0.central-slurm-slurmdbd-headless.accounting.svc.cluster.local."
+		Expect(reconciler.checkSharedSlurmdbd(context.Background(), []*slurmv1.SlurmDeployment{accounting, east, west})).To(Succeed())
+	})
+
+	It("reports the members using another slurmdbd", func() {
+		east := newRelease("team-east", "east")
+		east.Spec.Values.SlurmConfig.Accounting.SlurmdbdRef = &slurmv1.SlurmDeploymentReference{Name: "central", Namespace: "accounting"}
+		west := newRelease("team-west", "west")
+		err := reconciler.checkSharedSlurmdbd(context.Background(), []*slurmv1.SlurmDeployment{east, west})
+		Expect(err).To(MatchError("members do not share one slurmdbd: " +
+			"team-east/east uses central-slurm-slurmdbd-0.central-slurm-slurmdbd-headless.accounting.svc; " +
+			"team-west/west uses west-slurm-slurmdbd-0.west-slurm-slurmdbd-headless.team-west.svc"))
+	})
+
+	It("fails on a slurmdbdRef from a namespace the slurmdbd does not allow", func() {
+		north := newRelease("team-north", "north")
+		north.Spec.Values.SlurmConfig.Accounting.SlurmdbdRef = &slurmv1.SlurmDeploymentReference{Name: "central", Namespace: "accounting"}
+		err := reconciler.checkSharedSlurmdbd(context.Background(), []*slurmv1.SlurmDeployment{accounting, north})
+		Expect(err).To(MatchError(ContainSubstring("refuses references from namespace team-north")))
+	})
+
+	It("fails on a slurmdbdRef without the namespace of the slurmdbd", func() {
+		east := newRelease("team-east", "east")
+		east.Spec.Values.SlurmConfig.Accounting.SlurmdbdRef = &slurmv1.SlurmDeploymentReference{Name: "central"}
+		Expect(reconciler.checkSharedSlurmdbd(context.Background(), []*slurmv1.SlurmDeployment{east})).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,237 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+// SlurmQOSReconciler reconciles a SlurmQOS object
+type SlurmQOSReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Executor utils.PodExecutor
+}
+
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmqoses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmqoses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmqoses/finalizers,verbs=update
+
+// Reconcile applies a SlurmQOS to the accounting database of its SlurmDeployment through sacctmgr,
+// reporting every field that drifted from the spec before correcting it.
+func (r *SlurmQOSReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	qos := &slurmv1.SlurmQOS{}
+	if findQOSErr := r.Get(ctx, req.NamespacedName, qos); findQOSErr != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(findQOSErr)
+	}
+	qosName := qos.Spec.QOSName
+	if qosName == "" {
+		qosName = qos.Name
+	}
+
+	release, findReleaseErr := findSlurmDeployment(ctx, r.Client, qos.Namespace, qos.Spec.DeploymentRef)
+
+	if !qos.ObjectMeta.DeletionTimestamp.IsZero() {
+		if utils.CheckIfExistInArray(qos.ObjectMeta.Finalizers, SlurmAccountingFinalizer) {
+			if findReleaseErr == nil {
+				otherClusters, queryErr := r.qosUsedByOtherClusters(ctx, release, qosName)
+				if queryErr != nil {
+					log.Printf("Failed to query the associations of slurm qos %s: %v", qosName, queryErr)
+					return ctrl.Result{}, queryErr
+				}
+				if len(otherClusters) > 0 {
+					log.Printf("Keeping slurm qos %s, still used by cluster(s) %v of the shared slurmdbd", qosName, otherClusters)
+				} else {
+					log.Printf("Deleting slurm qos %s from SlurmDeployment %s", qosName, release.Name)
+					if _, deleteErr := utils.RunSacctmgr(ctx, r.Executor, release, "delete", "qos", "name="+qosName); deleteErr != nil && !utils.IsSacctmgrNothingChanged(deleteErr) {
+						log.Printf("Failed to delete slurm qos %s: %v", qosName, deleteErr)
+						return ctrl.Result{}, deleteErr
+					}
+				}
+			} else {
+				log.Printf("SlurmDeployment %s of qos %s is gone, skipping sacctmgr cleanup", qos.Spec.DeploymentRef.Name, qosName)
+			}
+			qos.ObjectMeta.Finalizers = utils.SplitHeadArray(qos.ObjectMeta.Finalizers, SlurmAccountingFinalizer)
+			if updateErr := r.Update(ctx, qos); updateErr != nil {
+				return ctrl.Result{}, updateErr
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if findReleaseErr != nil {
+		if apierrors.IsNotFound(findReleaseErr) {
+			return r.updateQOSStatus(ctx, qos, AccountingPhasePending,
+				fmt.Sprintf("SlurmDeployment %s not found", qos.Spec.DeploymentRef.Name), nil, 30*time.Second)
+		}
+		if errors.Is(findReleaseErr, errReferenceNotAllowed) {
+			return r.updateQOSStatus(ctx, qos, AccountingPhasePending, findReleaseErr.Error(), nil, 30*time.Second)
+		}
+		return ctrl.Result{}, findReleaseErr
+	}
+
+	if !utils.CheckIfExistInArray(qos.ObjectMeta.Finalizers, SlurmAccountingFinalizer) {
+		log.Printf("Adding finalizer to SlurmQOS %s", qos.Name)
+		qos.ObjectMeta.Finalizers = append(qos.ObjectMeta.Finalizers, SlurmAccountingFinalizer)
+		if updateErr := r.Update(ctx, qos); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	desired := utils.DesiredQOS(&qos.Spec)
+	observed, queryErr := utils.QuerySacctmgr(ctx, r.Executor, release, "qos", []string{"where", "name=" + qosName}, utils.QOSFields)
+	if queryErr != nil {
+		log.Printf("Failed to query slurm qos %s: %v", qosName, queryErr)
+		_, _ = r.updateQOSStatus(ctx, qos, AccountingPhaseError, queryErr.Error(), qos.Status.Drift, 0)
+		return ctrl.Result{}, queryErr
+	}
+
+	var drift []string
+	if len(observed) == 0 {
+		log.Printf("Creating slurm qos %s in SlurmDeployment %s", qosName, release.Name)
+		args := append([]string{"add", "qos", qosName}, utils.QOSSetArgs(desired)...)
+		if _, addErr := utils.RunSacctmgr(ctx, r.Executor, release, args...); addErr != nil && !utils.IsSacctmgrNothingChanged(addErr) {
+			_, _ = r.updateQOSStatus(ctx, qos, AccountingPhaseError, addErr.Error(), nil, 0)
+			return ctrl.Result{}, addErr
+		}
+	} else if drift = utils.DiffSacctmgrFields(desired, observed[0]); len(drift) > 0 {
+		log.Printf("Slurm qos %s drifted from spec: %v", qosName, drift)
+		args := append([]string{"modify", "qos", "where", "name=" + qosName, "set"}, utils.QOSSetArgs(desired)...)
+		if _, modifyErr := utils.RunSacctmgr(ctx, r.Executor, release, args...); modifyErr != nil && !utils.IsSacctmgrNothingChanged(modifyErr) {
+			_, _ = r.updateQOSStatus(ctx, qos, AccountingPhaseError, modifyErr.Error(), drift, 0)
+			return ctrl.Result{}, modifyErr
+		}
+	}
+
+	message := fmt.Sprintf("QoS %s applied", qosName)
+	if len(drift) > 0 {
+		message = fmt.Sprintf("QoS %s corrected %d drifted field(s)", qosName, len(drift))
+	}
+	if !release.Spec.Values.SlurmConfig.Scheduling.EnforceQOS {
+		message += fmt.Sprintf(", not enforced until scheduling.enforceQOS is set on SlurmDeployment %s", release.Name)
+	}
+	return r.updateQOSStatus(ctx, qos, AccountingPhaseSynced, message, drift, accountingResyncInterval)
+}
+
+// qosUsedByOtherClusters lists the other clusters of a shared slurmdbd with associations using a QoS, QoS records
+// are not scoped to a cluster so they must outlive the SlurmQOS of one cluster
+func (r *SlurmQOSReconciler) qosUsedByOtherClusters(ctx context.Context, release *slurmv1.SlurmDeployment, qosName string) ([]string, error) {
+	associations, err := utils.QuerySacctmgr(ctx, r.Executor, release, "association", []string{"where", "qos=" + qosName}, []string{"Cluster"})
+	if err != nil {
+		return nil, err
+	}
+	clusters := []string{}
+	for _, association := range associations {
+		cluster := association["Cluster"]
+		if cluster != release.Status.ClusterName && !utils.CheckIfExistInArray(clusters, cluster) {
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters, nil
+}
+
+func (r *SlurmQOSReconciler) updateQOSStatus(ctx context.Context, qos *slurmv1.SlurmQOS, phase, message string, drift []string, requeueAfter time.Duration) (ctrl.Result, error) {
+	now := metav1.Now()
+	qos.Status.Phase = phase
+	qos.Status.Message = message
+	qos.Status.Drift = drift
+	qos.Status.ObservedGeneration = qos.Generation
+	qos.Status.LastSyncTime = &now
+	if updateStatusErr := r.Status().Update(ctx, qos); updateStatusErr != nil {
+		log.Printf("Failed to update SlurmQOS %s status: %v", qos.Name, updateStatusErr)
+		return ctrl.Result{}, updateStatusErr
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// applyQOSSchedulingDefaults fills the empty scheduling settings of a SlurmDeployment with enforceQOS so that
+// slurm.conf enables the priority and preemption plugins its SlurmQOS objects rely on.
+func applyQOSSchedulingDefaults(ctx context.Context, c client.Client, release *slurmv1.SlurmDeployment) error {
+	if !release.Spec.Values.SlurmConfig.Scheduling.EnforceQOS {
+		return nil
+	}
+	qosList := &slurmv1.SlurmQOSList{}
+	if listErr := c.List(ctx, qosList); listErr != nil {
+		log.Printf("Failed to list SlurmQOS for SlurmDeployment %s: %v", release.Name, listErr)
+		return listErr
+	}
+
+	referenced, preempting := false, false
+	for _, qos := range qosList.Items {
+		if !refersTo(qos.Namespace, qos.Spec.DeploymentRef, release) || !qos.ObjectMeta.DeletionTimestamp.IsZero() {
+			continue
+		}
+		referenced = true
+		if len(qos.Spec.Preempt) > 0 || (qos.Spec.PreemptMode != "" && qos.Spec.PreemptMode != "OFF") {
+			preempting = true
+		}
+	}
+	if !referenced {
+		return nil
+	}
+
+	scheduling := &release.Spec.Values.SlurmConfig.Scheduling
+	if scheduling.PriorityType == "" {
+		scheduling.PriorityType = "priority/multifactor"
+	}
+	if scheduling.PriorityWeightQOS == 0 {
+		scheduling.PriorityWeightQOS = 10000
+	}
+	if scheduling.AccountingStorageEnforce == "" {
+		scheduling.AccountingStorageEnforce = "associations,limits,qos"
+	}
+	if preempting {
+		if scheduling.PreemptType == "" {
+			scheduling.PreemptType = "preempt/qos"
+		}
+		if scheduling.PreemptMode == "" {
+			scheduling.PreemptMode = "REQUEUE"
+		}
+	}
+	return nil
+}
+
+// slurmDeploymentForQOS maps a SlurmQOS to the SlurmDeployment whose slurm.conf depends on it
+func slurmDeploymentForQOS(_ context.Context, obj client.Object) []reconcile.Request {
+	qos, ok := obj.(*slurmv1.SlurmQOS)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: slurmDeploymentKey(qos.Namespace, qos.Spec.DeploymentRef)}}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SlurmQOSReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&slurmv1.SlurmQOS{}).
+		Complete(r)
+}
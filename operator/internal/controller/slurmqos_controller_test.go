@@ -0,0 +1,235 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+var _ = Describe("SlurmQOS Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		slurmqos := &slurmv1.SlurmQOS{}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind SlurmQOS")
+			err := k8sClient.Get(ctx, typeNamespacedName, slurmqos)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &slurmv1.SlurmQOS{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: slurmv1.SlurmQOSSpec{
+						DeploymentRef: slurmv1.SlurmDeploymentReference{Name: "missing-deployment"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &slurmv1.SlurmQOS{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance SlurmQOS")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+		It("should wait for the referenced SlurmDeployment", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := &SlurmQOSReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &slurmv1.SlurmQOS{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Phase).To(Equal(AccountingPhasePending))
+		})
+	})
+})
+
+var _ = Describe("SlurmQOS sacctmgr commands", func() {
+	const showQOS = "sacctmgr --immediate --noheader --parsable2 show qos where name=high " +
+		"format=Name,Descr,Priority,PreemptMode,Preempt,MaxTRESPU,MaxWall,GrpTRES,UsageFactor,Flags"
+
+	var (
+		executor   *fakePodExecutor
+		reconciler *SlurmQOSReconciler
+		request    reconcile.Request
+	)
+
+	BeforeEach(func() {
+		executor = &fakePodExecutor{outputs: map[string]string{}}
+		priority := int32(100)
+		release := &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"}},
+			Status:     slurmv1.SlurmDeploymentStatus{ClusterName: "lab"},
+		}
+		release.Spec.Values.SlurmConfig.Scheduling.EnforceQOS = true
+		qos := &slurmv1.SlurmQOS{
+			ObjectMeta: metav1.ObjectMeta{Name: "high", Namespace: "default", Finalizers: []string{SlurmAccountingFinalizer}},
+			Spec: slurmv1.SlurmQOSSpec{
+				DeploymentRef:  slurmv1.SlurmDeploymentReference{Name: "cluster"},
+				Priority:       &priority,
+				MaxTRESPerUser: "mem=16G,cpu=8",
+				MaxWall:        "48:00:00",
+				GrpTRES:        "gres/gpu=8",
+			},
+		}
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		reconciler = &SlurmQOSReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(release, qos).
+				WithStatusSubresource(qos).Build(),
+			Scheme:   scheme,
+			Executor: executor,
+		}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "high"}}
+	})
+
+	It("adds a missing QoS", func() {
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(Equal([]string{
+			showQOS,
+			"sacctmgr --immediate --noheader --parsable2 add qos high GrpTRES=gres/gpu=8 MaxTRESPerUser=mem=16G,cpu=8 MaxWall=48:00:00 Priority=100",
+		}))
+	})
+
+	It("leaves a QoS in sync untouched whatever format sacctmgr prints its limits in", func() {
+		executor.outputs[showQOS] = "high||100|cluster||cpu=8,mem=16384M|2-00:00:00|gres/gpu=8|1.000000|\n"
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(Equal([]string{showQOS}))
+
+		qos := &slurmv1.SlurmQOS{}
+		Expect(reconciler.Get(context.Background(), request.NamespacedName, qos)).To(Succeed())
+		Expect(qos.Status.Phase).To(Equal(AccountingPhaseSynced))
+		Expect(qos.Status.Drift).To(BeEmpty())
+	})
+
+	It("modifies a drifted QoS and reports the drift", func() {
+		executor.outputs[showQOS] = "high||100|cluster||cpu=8,mem=8G|1-00:00:00|gres/gpu=8|1.000000|\n"
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(Equal([]string{
+			showQOS,
+			"sacctmgr --immediate --noheader --parsable2 modify qos where name=high set " +
+				"GrpTRES=gres/gpu=8 MaxTRESPerUser=mem=16G,cpu=8 MaxWall=48:00:00 Priority=100",
+		}))
+
+		qos := &slurmv1.SlurmQOS{}
+		Expect(reconciler.Get(context.Background(), request.NamespacedName, qos)).To(Succeed())
+		Expect(qos.Status.Phase).To(Equal(AccountingPhaseSynced))
+		Expect(qos.Status.Drift).To(Equal([]string{
+			`MaxTRESPU: want "mem=16G,cpu=8", got "cpu=8,mem=8G"`,
+			`MaxWall: want "48:00:00", got "1-00:00:00"`,
+		}))
+	})
+})
+
+var _ = Describe("SlurmQOS scheduling defaults", func() {
+	var release *slurmv1.SlurmDeployment
+
+	applyDefaults := func(qosList ...*slurmv1.SlurmQOS) {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		builder := fake.NewClientBuilder().WithScheme(scheme)
+		for _, qos := range qosList {
+			builder = builder.WithObjects(qos)
+		}
+		Expect(applyQOSSchedulingDefaults(context.Background(), builder.Build(), release)).To(Succeed())
+	}
+
+	newQOS := func(name string, preempt ...string) *slurmv1.SlurmQOS {
+		return &slurmv1.SlurmQOS{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: slurmv1.SlurmQOSSpec{
+				DeploymentRef: slurmv1.SlurmDeploymentReference{Name: "cluster"},
+				Preempt:       preempt,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		release = &slurmv1.SlurmDeployment{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"}}
+		release.Spec.Values.SlurmConfig.Scheduling.EnforceQOS = true
+	})
+
+	It("leaves slurm.conf alone without enforceQOS", func() {
+		release.Spec.Values.SlurmConfig.Scheduling.EnforceQOS = false
+		applyDefaults(newQOS("high", "normal"))
+		Expect(release.Spec.Values.SlurmConfig.Scheduling).To(Equal(slurmv1.SchedulingSpec{}))
+	})
+
+	It("leaves slurm.conf alone while no SlurmQOS references the SlurmDeployment", func() {
+		other := newQOS("high", "normal")
+		other.Spec.DeploymentRef.Name = "other"
+		applyDefaults(other)
+		Expect(release.Spec.Values.SlurmConfig.Scheduling).To(Equal(slurmv1.SchedulingSpec{EnforceQOS: true}))
+	})
+
+	It("enables QoS priorities and limits", func() {
+		applyDefaults(newQOS("normal"))
+		scheduling := release.Spec.Values.SlurmConfig.Scheduling
+		Expect(scheduling.PriorityType).To(Equal("priority/multifactor"))
+		Expect(scheduling.PriorityWeightQOS).To(Equal(int32(10000)))
+		Expect(scheduling.AccountingStorageEnforce).To(Equal("associations,limits,qos"))
+		Expect(scheduling.PreemptType).To(BeEmpty())
+		Expect(scheduling.PreemptMode).To(BeEmpty())
+	})
+
+	It("enables preemption for a preempting QoS and keeps the settings of the SlurmDeployment", func() {
+		release.Spec.Values.SlurmConfig.Scheduling.PriorityWeightQOS = 500
+		release.Spec.Values.SlurmConfig.Scheduling.PreemptMode = "CANCEL"
+		applyDefaults(newQOS("normal"), newQOS("high", "normal"))
+		scheduling := release.Spec.Values.SlurmConfig.Scheduling
+		Expect(scheduling.PriorityWeightQOS).To(Equal(int32(500)))
+		Expect(scheduling.PreemptType).To(Equal("preempt/qos"))
+		Expect(scheduling.PreemptMode).To(Equal("CANCEL"))
+	})
+})
@@ -0,0 +1,279 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+const restorePollInterval = 10 * time.Second
+
+// SlurmRestoreReconciler reconciles a SlurmRestore object
+type SlurmRestoreReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmrestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmrestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmrestores/finalizers,verbs=update
+
+// Reconcile restores a backup into a SlurmDeployment: slurmctld is stopped, a job writes the state and the
+// accounting database back, then slurmctld is started again. The SlurmDeployment controller leaves the release
+// alone while a restore is in progress. A release sharing the slurmdbd of another release only gets its slurmctld
+// state back, the shared accounting database is left alone.
+func (r *SlurmRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	restore := &slurmv1.SlurmRestore{}
+	if findRestoreErr := r.Get(ctx, req.NamespacedName, restore); findRestoreErr != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(findRestoreErr)
+	}
+	if !restore.ObjectMeta.DeletionTimestamp.IsZero() || restoreFinished(restore) {
+		return ctrl.Result{}, nil
+	}
+
+	release, findReleaseErr := findSlurmDeployment(ctx, r.Client, restore.Namespace, restore.Spec.DeploymentRef)
+	if findReleaseErr != nil {
+		if apierrors.IsNotFound(findReleaseErr) {
+			return r.updateRestoreStatus(ctx, restore, slurmv1.RestorePhasePending,
+				fmt.Sprintf("SlurmDeployment %s not found", restore.Spec.DeploymentRef.Name), 30*time.Second)
+		}
+		if errors.Is(findReleaseErr, errReferenceNotAllowed) {
+			return r.updateRestoreStatus(ctx, restore, slurmv1.RestorePhasePending, findReleaseErr.Error(), 30*time.Second)
+		}
+		return ctrl.Result{}, findReleaseErr
+	}
+	backup := &slurmv1.SlurmBackup{}
+	if findBackupErr := r.Get(ctx, types.NamespacedName{Namespace: restore.Namespace, Name: restore.Spec.BackupRef}, backup); findBackupErr != nil {
+		if apierrors.IsNotFound(findBackupErr) {
+			return r.updateRestoreStatus(ctx, restore, slurmv1.RestorePhasePending,
+				fmt.Sprintf("SlurmBackup %s not found", restore.Spec.BackupRef), 30*time.Second)
+		}
+		return ctrl.Result{}, findBackupErr
+	}
+
+	if restore.Status.BackupID == "" {
+		restore.Status.BackupID = restore.Spec.BackupID
+		if restore.Status.BackupID == "" {
+			restore.Status.BackupID = backup.Status.LastSuccessfulBackup
+		}
+		if restore.Status.BackupID == "" {
+			return r.updateRestoreStatus(ctx, restore, slurmv1.RestorePhasePending,
+				fmt.Sprintf("SlurmBackup %s has no successful backup yet", backup.Name), time.Minute)
+		}
+	}
+
+	slurmctld := types.NamespacedName{Namespace: release.Spec.Chart.Namespace, Name: utils.ComponentName(release, "slurmctld")}
+	switch restore.Status.Phase {
+	case "", slurmv1.RestorePhasePending:
+		blocked, blockErr := restoreBlocked(ctx, r.Client, release, backup, restore.Status.BackupID)
+		if blockErr != nil {
+			return ctrl.Result{}, blockErr
+		}
+		if blocked != "" {
+			return r.updateRestoreStatus(ctx, restore, slurmv1.RestorePhasePending, blocked, 30*time.Second)
+		}
+		if !usesSharedSlurmdbd(release) {
+			mariadb := &appsv1.StatefulSet{}
+			mariadbKey := types.NamespacedName{Namespace: release.Spec.Chart.Namespace, Name: fmt.Sprintf("%s-mariadb", release.Name)}
+			if getErr := r.Get(ctx, mariadbKey, mariadb); getErr != nil || mariadb.Status.ReadyReplicas == 0 {
+				return r.updateRestoreStatus(ctx, restore, slurmv1.RestorePhasePending, "Waiting for the accounting database to become ready", restorePollInterval)
+			}
+		}
+		if _, getErr := r.scaleStatefulSet(ctx, slurmctld, 0); getErr != nil {
+			return r.updateRestoreStatus(ctx, restore, slurmv1.RestorePhasePending, "Waiting for slurmctld to be deployed", restorePollInterval)
+		}
+		now := metav1.Now()
+		restore.Status.StartTime = &now
+		return r.updateRestoreStatus(ctx, restore, slurmv1.RestorePhaseScalingDown, "Stopping slurmctld", restorePollInterval)
+
+	case slurmv1.RestorePhaseScalingDown:
+		sts, getErr := r.scaleStatefulSet(ctx, slurmctld, 0)
+		if getErr != nil {
+			return ctrl.Result{}, getErr
+		}
+		if sts.Status.Replicas > 0 {
+			return ctrl.Result{RequeueAfter: restorePollInterval}, nil
+		}
+		job, buildErr := r.buildRestoreJob(ctx, release, backup, restore)
+		if buildErr != nil {
+			return r.failRestore(ctx, restore, slurmctld, buildErr.Error())
+		}
+		if createErr := r.Create(ctx, job); createErr != nil && !apierrors.IsAlreadyExists(createErr) {
+			return ctrl.Result{}, createErr
+		}
+		log.Printf("Started restore job %s for SlurmDeployment %s", job.Name, release.Name)
+		restore.Status.JobName = job.Name
+		return r.updateRestoreStatus(ctx, restore, slurmv1.RestorePhaseRestoring,
+			fmt.Sprintf("Restoring backup %s", restore.Status.BackupID), restorePollInterval)
+
+	case slurmv1.RestorePhaseRestoring:
+		job := &batchv1.Job{}
+		if getJobErr := r.Get(ctx, types.NamespacedName{Namespace: release.Spec.Chart.Namespace, Name: restore.Status.JobName}, job); getJobErr != nil {
+			if apierrors.IsNotFound(getJobErr) {
+				return r.failRestore(ctx, restore, slurmctld, fmt.Sprintf("restore job %s disappeared", restore.Status.JobName))
+			}
+			return ctrl.Result{}, getJobErr
+		}
+		switch jobPhase(job) {
+		case slurmv1.BackupPhaseFailed:
+			return r.failRestore(ctx, restore, slurmctld, fmt.Sprintf("restore job %s failed", job.Name))
+		case slurmv1.BackupPhaseRunning:
+			return ctrl.Result{RequeueAfter: restorePollInterval}, nil
+		}
+		if _, scaleErr := r.scaleStatefulSet(ctx, slurmctld, 1); scaleErr != nil {
+			return ctrl.Result{}, scaleErr
+		}
+		return r.updateRestoreStatus(ctx, restore, slurmv1.RestorePhaseScalingUp, "Starting slurmctld", restorePollInterval)
+
+	case slurmv1.RestorePhaseScalingUp:
+		sts, getErr := r.scaleStatefulSet(ctx, slurmctld, 1)
+		if getErr != nil {
+			return ctrl.Result{}, getErr
+		}
+		if sts.Status.ReadyReplicas < 1 {
+			return ctrl.Result{RequeueAfter: restorePollInterval}, nil
+		}
+		now := metav1.Now()
+		restore.Status.CompletionTime = &now
+		log.Printf("Restored backup %s into SlurmDeployment %s", restore.Status.BackupID, release.Name)
+		return r.updateRestoreStatus(ctx, restore, slurmv1.RestorePhaseCompleted,
+			fmt.Sprintf("Backup %s restored", restore.Status.BackupID), 0)
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *SlurmRestoreReconciler) buildRestoreJob(ctx context.Context, release *slurmv1.SlurmDeployment,
+	backup *slurmv1.SlurmBackup, restore *slurmv1.SlurmRestore) (*batchv1.Job, error) {
+	if (backup.Spec.Target.PVC == nil) == (backup.Spec.Target.S3 == nil) {
+		return nil, fmt.Errorf("SlurmBackup %s must set exactly one of target.pvc and target.s3", backup.Name)
+	}
+	stateClaim, _, claimErr := findSlurmctldStateClaim(ctx, r.Client, release)
+	if claimErr != nil {
+		return nil, claimErr
+	}
+	options := backupJobOptions(release, backup, restore.Status.BackupID)
+	options.Name = jobName(restore.Name, "restore", restore.Status.BackupID)
+	options.Labels = map[string]string{restoreLabel: restore.Name, backupNamespaceLabel: restore.Namespace}
+	options.StateClaim = stateClaim
+	options.SkipDatabase = usesSharedSlurmdbd(release)
+	return utils.BuildRestoreJob(options), nil
+}
+
+// restoreBlocked explains why a backup cannot be restored into a release yet: slurmctld refuses state saved under
+// another ClusterName, and loading the accounting database would overwrite the accounting of the other releases
+// reporting to the slurmdbd of the release
+func restoreBlocked(ctx context.Context, c client.Client, release *slurmv1.SlurmDeployment, backup *slurmv1.SlurmBackup, backupID string) (string, error) {
+	for _, record := range backup.Status.Backups {
+		if record.ID == backupID && record.ClusterName != "" && release.Status.ClusterName != "" && record.ClusterName != release.Status.ClusterName {
+			return fmt.Sprintf("Backup %s was taken of cluster %s, set spec.values.configuration.clusterName of SlurmDeployment %s to %s",
+				backupID, record.ClusterName, release.Name, record.ClusterName), nil
+		}
+	}
+	if usesSharedSlurmdbd(release) {
+		return "", nil
+	}
+	clients, listErr := slurmdbdClients(ctx, c, release)
+	if listErr != nil {
+		return "", listErr
+	}
+	if len(clients) > 0 {
+		return fmt.Sprintf("Restoring the accounting database of %s would overwrite the accounting of SlurmDeployments %s",
+			release.Name, strings.Join(clients, ", ")), nil
+	}
+	return "", nil
+}
+
+// failRestore starts slurmctld again so a failed restore does not leave the cluster down
+func (r *SlurmRestoreReconciler) failRestore(ctx context.Context, restore *slurmv1.SlurmRestore, slurmctld types.NamespacedName, message string) (ctrl.Result, error) {
+	if _, scaleErr := r.scaleStatefulSet(ctx, slurmctld, 1); scaleErr != nil {
+		log.Printf("Failed to start slurmctld %s after a failed restore: %v", slurmctld.Name, scaleErr)
+	}
+	now := metav1.Now()
+	restore.Status.CompletionTime = &now
+	return r.updateRestoreStatus(ctx, restore, slurmv1.RestorePhaseFailed, message, 0)
+}
+
+// scaleStatefulSet sets the replicas of a StatefulSet when they differ and returns it
+func (r *SlurmRestoreReconciler) scaleStatefulSet(ctx context.Context, key types.NamespacedName, replicas int32) (*appsv1.StatefulSet, error) {
+	sts := &appsv1.StatefulSet{}
+	if err := r.Get(ctx, key, sts); err != nil {
+		return nil, err
+	}
+	if replicasOf(sts.Spec.Replicas) != replicas {
+		sts.Spec.Replicas = &replicas
+		if err := r.Update(ctx, sts); err != nil {
+			return nil, err
+		}
+	}
+	return sts, nil
+}
+
+func (r *SlurmRestoreReconciler) updateRestoreStatus(ctx context.Context, restore *slurmv1.SlurmRestore, phase, message string, requeueAfter time.Duration) (ctrl.Result, error) {
+	restore.Status.Phase = phase
+	restore.Status.Message = message
+	if updateStatusErr := r.Status().Update(ctx, restore); updateStatusErr != nil {
+		log.Printf("Failed to update SlurmRestore %s status: %v", restore.Name, updateStatusErr)
+		return ctrl.Result{}, updateStatusErr
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+func restoreFinished(restore *slurmv1.SlurmRestore) bool {
+	return restore.Status.Phase == slurmv1.RestorePhaseCompleted || restore.Status.Phase == slurmv1.RestorePhaseFailed
+}
+
+// restoreInProgress tells whether a SlurmRestore is writing into the release, helm must not scale slurmctld back up meanwhile
+func restoreInProgress(ctx context.Context, c client.Client, release *slurmv1.SlurmDeployment) (bool, error) {
+	restores := &slurmv1.SlurmRestoreList{}
+	if err := c.List(ctx, restores); err != nil {
+		return false, err
+	}
+	for i := range restores.Items {
+		restore := &restores.Items[i]
+		if !refersTo(restore.Namespace, restore.Spec.DeploymentRef, release) || restoreFinished(restore) {
+			continue
+		}
+		if restore.Status.Phase != "" && restore.Status.Phase != slurmv1.RestorePhasePending {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SlurmRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&slurmv1.SlurmRestore{}).
+		Complete(r)
+}
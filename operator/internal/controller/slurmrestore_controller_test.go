@@ -0,0 +1,237 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+var _ = Describe("SlurmRestore Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		slurmrestore := &slurmv1.SlurmRestore{}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind SlurmRestore")
+			err := k8sClient.Get(ctx, typeNamespacedName, slurmrestore)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &slurmv1.SlurmRestore{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: slurmv1.SlurmRestoreSpec{
+						DeploymentRef: slurmv1.SlurmDeploymentReference{Name: "missing-deployment"},
+						BackupRef:     "missing-backup",
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &slurmv1.SlurmRestore{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance SlurmRestore")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+		It("should wait for the referenced SlurmDeployment", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := &SlurmRestoreReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &slurmv1.SlurmRestore{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Phase).To(Equal(slurmv1.RestorePhasePending))
+		})
+	})
+})
+
+var _ = Describe("SlurmRestore references", func() {
+	It("only holds back a SlurmDeployment for restores from namespaces it allows", func() {
+		ctx := context.Background()
+		release := &slurmv1.SlurmDeployment{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"}}
+		foreign := &slurmv1.SlurmRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "overwrite", Namespace: "tenant"},
+			Spec: slurmv1.SlurmRestoreSpec{
+				DeploymentRef: slurmv1.SlurmDeploymentReference{Name: "cluster", Namespace: "default"},
+				BackupRef:     "nightly",
+			},
+			Status: slurmv1.SlurmRestoreStatus{Phase: slurmv1.RestorePhaseScalingDown},
+		}
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(release, foreign).WithStatusSubresource(foreign).Build()
+		reconciler := &SlurmRestoreReconciler{Client: c, Scheme: scheme}
+
+		restoring, err := restoreInProgress(ctx, c, release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restoring).To(BeFalse())
+
+		foreign.Status = slurmv1.SlurmRestoreStatus{}
+		Expect(c.Status().Update(ctx, foreign)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "tenant", Name: "overwrite"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, types.NamespacedName{Namespace: "tenant", Name: "overwrite"}, foreign)).To(Succeed())
+		Expect(foreign.Status.Phase).To(Equal(slurmv1.RestorePhasePending))
+		Expect(foreign.Status.Message).To(ContainSubstring("refuses references from namespace tenant"))
+
+		foreign.Status.Phase = slurmv1.RestorePhaseScalingDown
+		Expect(c.Status().Update(ctx, foreign)).To(Succeed())
+		release.Spec.AllowedReferenceNamespaces = []string{"tenant"}
+		restoring, err = restoreInProgress(ctx, c, release)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restoring).To(BeTrue())
+	})
+})
+
+var _ = Describe("SlurmRestore of a shared slurmdbd", func() {
+	var (
+		ctx     context.Context
+		owner   *slurmv1.SlurmDeployment
+		team    *slurmv1.SlurmDeployment
+		backup  *slurmv1.SlurmBackup
+		restore *slurmv1.SlurmRestore
+	)
+
+	slurmctldSts := func(release string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: release + "-slurm-slurmctld", Namespace: "slurm"},
+			Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:         utils.SlurmctldContainerName,
+					VolumeMounts: []corev1.VolumeMount{{Name: "state", MountPath: utils.SlurmctldStateMountPath}},
+				}},
+				Volumes: []corev1.Volume{{Name: "state", VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: release + "-state"},
+				}}},
+			}}},
+		}
+	}
+
+	reconcileRestore := func(objects ...client.Object) (*SlurmRestoreReconciler, *slurmv1.SlurmRestore) {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objects, owner, team, backup, restore)...).
+			WithStatusSubresource(restore).Build()
+		reconciler := &SlurmRestoreReconciler{Client: c, Scheme: scheme}
+		key := types.NamespacedName{Namespace: restore.Namespace, Name: restore.Name}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		result := &slurmv1.SlurmRestore{}
+		Expect(c.Get(ctx, key, result)).To(Succeed())
+		return reconciler, result
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		owner = &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "accounting", Namespace: "default"},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"}},
+			Status:     slurmv1.SlurmDeploymentStatus{ClusterName: "accounting"},
+		}
+		team = &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "default"},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"}},
+			Status:     slurmv1.SlurmDeploymentStatus{ClusterName: "team"},
+		}
+		team.Spec.Values.SlurmConfig.Accounting.SlurmdbdRef = &slurmv1.SlurmDeploymentReference{Name: "accounting"}
+		backup = &slurmv1.SlurmBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+			Spec: slurmv1.SlurmBackupSpec{
+				DeploymentRef: slurmv1.SlurmDeploymentReference{Name: "accounting"},
+				Target:        slurmv1.BackupTargetSpec{PVC: &slurmv1.BackupPVCTargetSpec{ClaimName: "backups"}},
+			},
+			Status: slurmv1.SlurmBackupStatus{Backups: []slurmv1.BackupRecord{
+				{ID: "20250102-030000", Phase: slurmv1.BackupPhaseSucceeded, ClusterName: "accounting"},
+			}},
+		}
+		restore = &slurmv1.SlurmRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: "default"},
+			Spec: slurmv1.SlurmRestoreSpec{
+				DeploymentRef: slurmv1.SlurmDeploymentReference{Name: "accounting"},
+				BackupRef:     "nightly",
+				BackupID:      "20250102-030000",
+			},
+		}
+	})
+
+	It("refuses to reload the accounting database other releases report to", func() {
+		_, result := reconcileRestore(slurmctldSts("accounting"))
+		Expect(result.Status.Phase).To(Equal(slurmv1.RestorePhasePending))
+		Expect(result.Status.Message).To(ContainSubstring("would overwrite the accounting of SlurmDeployments default/team"))
+	})
+
+	It("refuses a backup taken under another ClusterName", func() {
+		team.Spec.Values.SlurmConfig.Accounting.SlurmdbdRef = nil
+		backup.Status.Backups[0].ClusterName = "old-cluster"
+		_, result := reconcileRestore(slurmctldSts("accounting"))
+		Expect(result.Status.Phase).To(Equal(slurmv1.RestorePhasePending))
+		Expect(result.Status.Message).To(ContainSubstring("set spec.values.configuration.clusterName of SlurmDeployment accounting to old-cluster"))
+	})
+
+	It("restores only the slurmctld state of a release sharing the slurmdbd", func() {
+		restore.Spec.DeploymentRef.Name = "team"
+		backup.Status.Backups[0].ClusterName = "team"
+		reconciler, result := reconcileRestore(slurmctldSts("team"))
+		Expect(result.Status.Phase).To(Equal(slurmv1.RestorePhaseScalingDown), "no MariaDB is waited for")
+
+		job, err := reconciler.buildRestoreJob(ctx, team, backup, result)
+		Expect(err).NotTo(HaveOccurred())
+		container := job.Spec.Template.Spec.Containers[0]
+		Expect(container.Args[0]).To(ContainSubstring("slurmctld-state.tar.gz"))
+		Expect(container.Args[0]).NotTo(ContainSubstring("mysql"))
+		for _, env := range container.Env {
+			Expect(env.Name).NotTo(HavePrefix("DB_"))
+		}
+	})
+})
@@ -0,0 +1,230 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+// SlurmUserReconciler reconciles a SlurmUser object
+type SlurmUserReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Executor utils.PodExecutor
+}
+
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmusers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmusers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=slurm.ay.dev,resources=slurmusers/finalizers,verbs=update
+
+// Reconcile applies a SlurmUser and its account associations through sacctmgr, removing associations
+// that are no longer listed in the spec and reporting every field that drifted.
+func (r *SlurmUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	user := &slurmv1.SlurmUser{}
+	if findUserErr := r.Get(ctx, req.NamespacedName, user); findUserErr != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(findUserErr)
+	}
+	userName := user.Spec.UserName
+	if userName == "" {
+		userName = user.Name
+	}
+
+	release, findReleaseErr := findSlurmDeployment(ctx, r.Client, user.Namespace, user.Spec.DeploymentRef)
+
+	if !user.ObjectMeta.DeletionTimestamp.IsZero() {
+		if utils.CheckIfExistInArray(user.ObjectMeta.Finalizers, SlurmAccountingFinalizer) {
+			if findReleaseErr == nil && utils.SacctmgrCluster(release) != "" {
+				log.Printf("Deleting slurm user %s from SlurmDeployment %s", userName, release.Name)
+				if _, deleteErr := utils.RunSacctmgr(ctx, r.Executor, release, "delete", "user", "name="+userName,
+					utils.SacctmgrCluster(release)); deleteErr != nil && !utils.IsSacctmgrNothingChanged(deleteErr) {
+					log.Printf("Failed to delete slurm user %s: %v", userName, deleteErr)
+					return ctrl.Result{}, deleteErr
+				}
+			} else {
+				log.Printf("SlurmDeployment %s of user %s is gone, skipping sacctmgr cleanup", user.Spec.DeploymentRef.Name, userName)
+			}
+			user.ObjectMeta.Finalizers = utils.SplitHeadArray(user.ObjectMeta.Finalizers, SlurmAccountingFinalizer)
+			if updateErr := r.Update(ctx, user); updateErr != nil {
+				return ctrl.Result{}, updateErr
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if findReleaseErr != nil {
+		if apierrors.IsNotFound(findReleaseErr) {
+			return r.updateUserStatus(ctx, user, AccountingPhasePending,
+				fmt.Sprintf("SlurmDeployment %s not found", user.Spec.DeploymentRef.Name), nil, nil, 30*time.Second)
+		}
+		if errors.Is(findReleaseErr, errReferenceNotAllowed) {
+			return r.updateUserStatus(ctx, user, AccountingPhasePending, findReleaseErr.Error(), nil, nil, 30*time.Second)
+		}
+		return ctrl.Result{}, findReleaseErr
+	}
+	if utils.SacctmgrCluster(release) == "" {
+		return r.updateUserStatus(ctx, user, AccountingPhasePending,
+			fmt.Sprintf("SlurmDeployment %s has no ClusterName yet", release.Name), nil, nil, 30*time.Second)
+	}
+
+	if !utils.CheckIfExistInArray(user.ObjectMeta.Finalizers, SlurmAccountingFinalizer) {
+		log.Printf("Adding finalizer to SlurmUser %s", user.Name)
+		user.ObjectMeta.Finalizers = append(user.ObjectMeta.Finalizers, SlurmAccountingFinalizer)
+		if updateErr := r.Update(ctx, user); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	accounts := []string{user.Spec.DefaultAccount}
+	for _, account := range user.Spec.Accounts {
+		if !utils.CheckIfExistInArray(accounts, account) {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Strings(accounts)
+	desired := utils.DesiredAssociation("", user.Spec.Fairshare, user.Spec.Limits, user.Spec.DefaultQOS, user.Spec.QOS)
+
+	drift, applyErr := r.applyUser(ctx, release, user, userName, accounts, desired)
+	if applyErr != nil {
+		log.Printf("Failed to apply slurm user %s: %v", userName, applyErr)
+		_, _ = r.updateUserStatus(ctx, user, AccountingPhaseError, applyErr.Error(), drift, user.Status.Accounts, 0)
+		return ctrl.Result{}, applyErr
+	}
+
+	message := fmt.Sprintf("User %s applied to %d account(s)", userName, len(accounts))
+	if len(drift) > 0 {
+		message = fmt.Sprintf("User %s corrected %d drifted field(s)", userName, len(drift))
+	}
+	return r.updateUserStatus(ctx, user, AccountingPhaseSynced, message, drift, accounts, accountingResyncInterval)
+}
+
+// applyUser creates or corrects the user record and one association per account, returning the drift it found
+func (r *SlurmUserReconciler) applyUser(ctx context.Context, release *slurmv1.SlurmDeployment, user *slurmv1.SlurmUser,
+	userName string, accounts []string, desired map[string]string) ([]string, error) {
+	adminLevel := user.Spec.AdminLevel
+	if adminLevel == "" {
+		adminLevel = "None"
+	}
+	cluster := utils.SacctmgrCluster(release)
+
+	users, err := utils.QuerySacctmgr(ctx, r.Executor, release, "user", []string{"where", "name=" + userName}, []string{"User", "DefaultAccount", "AdminLevel"})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		log.Printf("Creating slurm user %s in SlurmDeployment %s", userName, release.Name)
+		args := []string{"add", "user", userName, cluster, "DefaultAccount=" + user.Spec.DefaultAccount,
+			"Account=" + strings.Join(accounts, ","), "AdminLevel=" + adminLevel}
+		if _, addErr := utils.RunSacctmgr(ctx, r.Executor, release, append(args, utils.AssociationSetArgs(desired)...)...); addErr != nil && !utils.IsSacctmgrNothingChanged(addErr) {
+			return nil, addErr
+		}
+		return nil, nil
+	}
+
+	drift := []string{}
+	userRecordDrifted := !strings.EqualFold(users[0]["DefaultAccount"], user.Spec.DefaultAccount) ||
+		!strings.EqualFold(users[0]["AdminLevel"], adminLevel)
+	if userRecordDrifted {
+		drift = append(drift, fmt.Sprintf("DefaultAccount/AdminLevel: want %q/%q, got %q/%q",
+			user.Spec.DefaultAccount, adminLevel, users[0]["DefaultAccount"], users[0]["AdminLevel"]))
+	}
+
+	associations, err := utils.QuerySacctmgr(ctx, r.Executor, release, "association", []string{"where", "user=" + userName, cluster}, utils.AssociationFields)
+	if err != nil {
+		return drift, err
+	}
+	existing := map[string]map[string]string{}
+	for _, association := range associations {
+		existing[association["Account"]] = association
+	}
+
+	for _, account := range accounts {
+		association, found := existing[account]
+		if !found {
+			drift = append(drift, fmt.Sprintf("Account %s: association missing", account))
+			args := append([]string{"add", "user", userName, cluster, "Account=" + account}, utils.AssociationSetArgs(desired)...)
+			if _, addErr := utils.RunSacctmgr(ctx, r.Executor, release, args...); addErr != nil && !utils.IsSacctmgrNothingChanged(addErr) {
+				return drift, addErr
+			}
+			continue
+		}
+		if associationDrift := utils.DiffSacctmgrFields(desired, association); len(associationDrift) > 0 {
+			for _, item := range associationDrift {
+				drift = append(drift, fmt.Sprintf("Account %s: %s", account, item))
+			}
+			args := append([]string{"modify", "user", "where", "name=" + userName, "account=" + account, cluster, "set"}, utils.AssociationSetArgs(desired)...)
+			if _, modifyErr := utils.RunSacctmgr(ctx, r.Executor, release, args...); modifyErr != nil && !utils.IsSacctmgrNothingChanged(modifyErr) {
+				return drift, modifyErr
+			}
+		}
+	}
+
+	// the default account has to be in place before stale associations can be removed
+	if userRecordDrifted {
+		if _, modifyErr := utils.RunSacctmgr(ctx, r.Executor, release, "modify", "user", "where", "name="+userName, cluster, "set",
+			"DefaultAccount="+user.Spec.DefaultAccount, "AdminLevel="+adminLevel); modifyErr != nil && !utils.IsSacctmgrNothingChanged(modifyErr) {
+			return drift, modifyErr
+		}
+	}
+
+	for account := range existing {
+		if !utils.CheckIfExistInArray(accounts, account) {
+			drift = append(drift, fmt.Sprintf("Account %s: association not in spec", account))
+			if _, deleteErr := utils.RunSacctmgr(ctx, r.Executor, release, "delete", "user", "where", "name="+userName, "account="+account, cluster); deleteErr != nil && !utils.IsSacctmgrNothingChanged(deleteErr) {
+				return drift, deleteErr
+			}
+		}
+	}
+	return drift, nil
+}
+
+func (r *SlurmUserReconciler) updateUserStatus(ctx context.Context, user *slurmv1.SlurmUser, phase, message string, drift, accounts []string, requeueAfter time.Duration) (ctrl.Result, error) {
+	now := metav1.Now()
+	user.Status.Phase = phase
+	user.Status.Message = message
+	user.Status.Drift = drift
+	user.Status.Accounts = accounts
+	user.Status.ObservedGeneration = user.Generation
+	user.Status.LastSyncTime = &now
+	if updateStatusErr := r.Status().Update(ctx, user); updateStatusErr != nil {
+		log.Printf("Failed to update SlurmUser %s status: %v", user.Name, updateStatusErr)
+		return ctrl.Result{}, updateStatusErr
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SlurmUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&slurmv1.SlurmUser{}).
+		Complete(r)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+var _ = Describe("SlurmUser Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		slurmuser := &slurmv1.SlurmUser{}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind SlurmUser")
+			err := k8sClient.Get(ctx, typeNamespacedName, slurmuser)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &slurmv1.SlurmUser{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: slurmv1.SlurmUserSpec{
+						DeploymentRef: slurmv1.SlurmDeploymentReference{Name: "missing-deployment"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &slurmv1.SlurmUser{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance SlurmUser")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+		It("should wait for the referenced SlurmDeployment", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := &SlurmUserReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &slurmv1.SlurmUser{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Phase).To(Equal(AccountingPhasePending))
+		})
+	})
+})
+
+var _ = Describe("SlurmUser sacctmgr commands", func() {
+	const (
+		sacctmgr        = "sacctmgr --immediate --noheader --parsable2 "
+		showUser        = sacctmgr + "show user where name=alice format=User,DefaultAccount,AdminLevel"
+		showAssociation = sacctmgr + "show association where user=alice cluster=lab " +
+			"format=Account,User,ParentName,Share,MaxJobs,MaxSubmit,MaxWall,MaxTRES,GrpTRES,DefaultQOS,QOS"
+	)
+
+	var (
+		executor   *fakePodExecutor
+		reconciler *SlurmUserReconciler
+		request    reconcile.Request
+	)
+
+	BeforeEach(func() {
+		executor = &fakePodExecutor{outputs: map[string]string{}}
+		release := &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"}},
+			Status:     slurmv1.SlurmDeploymentStatus{ClusterName: "lab"},
+		}
+		user := &slurmv1.SlurmUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "default", Finalizers: []string{SlurmAccountingFinalizer}},
+			Spec: slurmv1.SlurmUserSpec{
+				DeploymentRef:  slurmv1.SlurmDeploymentReference{Name: "cluster"},
+				DefaultAccount: "physics",
+				Accounts:       []string{"chemistry"},
+				Fairshare:      1,
+			},
+		}
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		reconciler = &SlurmUserReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(release, user).
+				WithStatusSubresource(user).Build(),
+			Scheme:   scheme,
+			Executor: executor,
+		}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "alice"}}
+	})
+
+	It("adds a missing user with all its accounts on the cluster of its SlurmDeployment", func() {
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(Equal([]string{
+			showUser,
+			sacctmgr + "add user alice cluster=lab DefaultAccount=physics Account=chemistry,physics AdminLevel=None Fairshare=1",
+		}))
+	})
+
+	It("corrects the associations and the default account in order", func() {
+		executor.outputs[showUser] = "alice|biology|None\n"
+		executor.outputs[showAssociation] = "physics|alice|physics|3|||||||\nbiology|alice|biology|1|||||||\n"
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(Equal([]string{
+			showUser,
+			showAssociation,
+			sacctmgr + "add user alice cluster=lab Account=chemistry Fairshare=1",
+			sacctmgr + "modify user where name=alice account=physics cluster=lab set Fairshare=1",
+			sacctmgr + "modify user where name=alice cluster=lab set DefaultAccount=physics AdminLevel=None",
+			sacctmgr + "delete user where name=alice account=biology cluster=lab",
+		}))
+
+		user := &slurmv1.SlurmUser{}
+		Expect(reconciler.Get(context.Background(), request.NamespacedName, user)).To(Succeed())
+		Expect(user.Status.Phase).To(Equal(AccountingPhaseSynced))
+		Expect(user.Status.Accounts).To(Equal([]string{"chemistry", "physics"}))
+	})
+
+	It("deletes the user from its cluster only", func() {
+		ctx := context.Background()
+		user := &slurmv1.SlurmUser{}
+		Expect(reconciler.Get(ctx, request.NamespacedName, user)).To(Succeed())
+		Expect(reconciler.Delete(ctx, user)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executor.ran()).To(Equal([]string{sacctmgr + "delete user name=alice cluster=lab"}))
+		Expect(errors.IsNotFound(reconciler.Get(ctx, request.NamespacedName, user))).To(BeTrue())
+	})
+})
@@ -20,6 +20,8 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -33,6 +35,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	slurmv2 "github.com/AaronYang0628/slurm-on-k8s/api/v2"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -61,6 +64,8 @@ var _ = BeforeSuite(func() {
 	var err error
 	err = slurmv1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
+	err = slurmv2.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:scheme
 
@@ -114,3 +119,28 @@ func getFirstFoundEnvTestBinaryDir() string {
 	}
 	return ""
 }
+
+// fakePodExecutor answers commands with the output registered for the joined command line and records every command
+// it was asked to run
+type fakePodExecutor struct {
+	mu       sync.Mutex
+	outputs  map[string]string
+	commands []string
+}
+
+func (e *fakePodExecutor) Exec(_ context.Context, _, _, _ string, command []string) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	line := strings.Join(command, " ")
+	e.commands = append(e.commands, line)
+	return e.outputs[line], nil
+}
+
+// ran returns the commands run since the last call
+func (e *fakePodExecutor) ran() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	commands := e.commands
+	e.commands = nil
+	return commands
+}
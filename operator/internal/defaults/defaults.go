@@ -0,0 +1,231 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package defaults holds the operator-wide defaults of SlurmDeployments. Platform teams keep the images, the image
+// mirror, the chart source, the resources and the storage classes in one ConfigMap or file instead of repeating them
+// in every SlurmDeployment, and upgrade them fleet-wide by changing that one place.
+package defaults
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+// ConfigMapKey is the key of the defaults ConfigMap that holds the defaults as YAML
+const ConfigMapKey = "defaults.yaml"
+
+// MigratedAnnotation marks a SlurmDeployment whose values persisted from the former CRD defaults have been cleared
+const MigratedAnnotation = "slurm.ay.dev/crd-defaults-migrated"
+
+// the values a SlurmDeployment got from the CRD defaults before the operator defaults existed
+const (
+	builtinImageRegistry   = "localhost"
+	builtinImageRepository = "data-and-computing"
+	builtinImageTag        = "latest"
+	builtinResourcesPreset = "nano"
+)
+
+// Defaults fill the fields a SlurmDeployment leaves empty, a field set in the SlurmDeployment always wins
+type Defaults struct {
+	Images ImageDefaults `json:"images,omitempty"`
+	// ImageMirror is the registry mirror every image of the chart is pulled through
+	ImageMirror string        `json:"imageMirror,omitempty"`
+	Chart       ChartDefaults `json:"chart,omitempty"`
+	// ResourcesPreset is the resources preset of the chart, e.g. nano or small
+	ResourcesPreset string               `json:"resourcesPreset,omitempty"`
+	Resources       ResourceDefaults     `json:"resources,omitempty"`
+	StorageClasses  StorageClassDefaults `json:"storageClasses,omitempty"`
+}
+
+// ImageDefaults are the images of the components, filled field by field so a SlurmDeployment can e.g. only pin a tag
+type ImageDefaults struct {
+	Munged    slurmv1.ImageSpec `json:"munged,omitempty"`
+	Slurmctld slurmv1.ImageSpec `json:"slurmctld,omitempty"`
+	// Slurmd is the image of both the cpu and the gpu slurmd groups
+	Slurmd   slurmv1.ImageSpec `json:"slurmd,omitempty"`
+	Slurmdbd slurmv1.ImageSpec `json:"slurmdbd,omitempty"`
+	Login    slurmv1.ImageSpec `json:"login,omitempty"`
+	Exporter slurmv1.ImageSpec `json:"exporter,omitempty"`
+}
+
+// ChartDefaults are the coordinates of the chart, its name is part of every workload name and stays in the
+// SlurmDeployment
+type ChartDefaults struct {
+	Repository string `json:"repository,omitempty"`
+	Version    string `json:"version,omitempty"`
+}
+
+// ResourceDefaults are the requests and limits of the components, each used when a SlurmDeployment leaves it unset
+type ResourceDefaults struct {
+	Slurmctld *slurmv1.ResourceSpec `json:"slurmctld,omitempty"`
+	Login     *slurmv1.ResourceSpec `json:"login,omitempty"`
+	// Slurmd applies to both the cpu and the gpu slurmd groups
+	Slurmd *slurmv1.SlurmdResourceSpec `json:"slurmd,omitempty"`
+}
+
+// StorageClassDefaults are the storage classes of the volumes provisioned for a release, volumes bound to an
+// existing claim are left alone
+type StorageClassDefaults struct {
+	// Shared is the storage class of persistence.shared
+	Shared string `json:"shared,omitempty"`
+	// Volumes is the storage class of the PVCs created for persistence.volumes
+	Volumes string `json:"volumes,omitempty"`
+	// Mariadb is the storage class of the MariaDB primary
+	Mariadb string `json:"mariadb,omitempty"`
+}
+
+// Parse reads defaults written as YAML, unknown fields are refused so a typo does not silently drop a default
+func Parse(data []byte) (*Defaults, error) {
+	defaults := &Defaults{}
+	if err := yaml.UnmarshalStrict(data, defaults); err != nil {
+		return nil, fmt.Errorf("failed to parse the operator defaults: %v", err)
+	}
+	return defaults, nil
+}
+
+// Apply fills the empty fields of a SlurmDeployment from the defaults, which may be nil, then the still empty image
+// fields and resources preset from the values the CRD used to default them to. It only changes the object in memory,
+// so a change of the defaults reaches every SlurmDeployment that does not set the field itself.
+func Apply(release *slurmv1.SlurmDeployment, defaults *Defaults) {
+	if defaults == nil {
+		defaults = &Defaults{}
+	}
+	chart := &release.Spec.Chart
+	chart.Repository = defaultString(chart.Repository, defaults.Chart.Repository)
+	chart.Version = defaultString(chart.Version, defaults.Chart.Version)
+
+	values := &release.Spec.Values
+	values.ImageMirror.Mirror.Registry = defaultString(values.ImageMirror.Mirror.Registry, defaults.ImageMirror)
+	values.ResourcesPreset = defaultString(values.ResourcesPreset, defaults.ResourcesPreset, builtinResourcesPreset)
+
+	builtin := slurmv1.ImageSpec{Registry: builtinImageRegistry, Repository: builtinImageRepository, Tag: builtinImageTag}
+	defaultImage(&values.Munged.Image, defaults.Images.Munged, builtin)
+	defaultImage(&values.Slurmctld.Image, defaults.Images.Slurmctld, builtin)
+	defaultImage(&values.SlurmdCPU.Image, defaults.Images.Slurmd, builtin)
+	defaultImage(&values.SlurmdGPU.Image, defaults.Images.Slurmd, builtin)
+	defaultImage(&values.Slurmdbd.Image, defaults.Images.Slurmdbd, builtin)
+	defaultImage(&values.SlurmLogin.Image, defaults.Images.Login, builtin)
+	// the exporter has its own fallback image, see utils.DefaultSlurmExporterSpec
+	defaultImage(&values.Monitoring.Exporter.Image, defaults.Images.Exporter, slurmv1.ImageSpec{})
+
+	if values.Slurmctld.Resources == nil && defaults.Resources.Slurmctld != nil {
+		values.Slurmctld.Resources = &slurmv1.ResourceSpec{}
+	}
+	if values.Slurmctld.Resources != nil {
+		defaultResources(values.Slurmctld.Resources, defaults.Resources.Slurmctld)
+	}
+	defaultResources(&values.SlurmLogin.Resources, defaults.Resources.Login)
+	defaultSlurmdResources(&values.SlurmdCPU.Resources, defaults.Resources.Slurmd)
+	defaultSlurmdResources(&values.SlurmdGPU.Resources, defaults.Resources.Slurmd)
+
+	shared := &values.Persistence.Shared
+	if shared.ExistingClaim == "" {
+		shared.StorageClass = defaultString(shared.StorageClass, defaults.StorageClasses.Shared)
+	}
+	for i := range values.Persistence.Volumes {
+		volume := &values.Persistence.Volumes[i]
+		if volume.ExistingClaim == "" {
+			volume.StorageClass = defaultString(volume.StorageClass, defaults.StorageClasses.Volumes)
+		}
+	}
+	mariadb := &values.Mariadb.Primary.Persistence
+	mariadb.StorageClass = defaultString(mariadb.StorageClass, defaults.StorageClasses.Mariadb)
+}
+
+// Migrate clears the image registries, repositories and tags and the resources preset of a SlurmDeployment that
+// still hold the values the CRD used to default them to, so that SlurmDeployments created before the operator
+// defaults follow them too. A value set on purpose cannot be told apart from a persisted CRD default, so it runs once
+// per SlurmDeployment and marks it with MigratedAnnotation. A SlurmDeployment never reconciled before was created
+// without the CRD defaults and is only marked. It returns whether the object changed and has to be saved.
+func Migrate(release *slurmv1.SlurmDeployment) bool {
+	if _, migrated := release.Annotations[MigratedAnnotation]; migrated {
+		return false
+	}
+	if release.Annotations == nil {
+		release.Annotations = map[string]string{}
+	}
+	release.Annotations[MigratedAnnotation] = "true"
+	if release.Status.ObservedGeneration == 0 {
+		return true
+	}
+	values := &release.Spec.Values
+	for _, image := range []*slurmv1.ImageSpec{&values.Munged.Image, &values.Slurmctld.Image, &values.SlurmdCPU.Image,
+		&values.SlurmdGPU.Image, &values.Slurmdbd.Image, &values.SlurmLogin.Image} {
+		image.Registry = clearBuiltin(image.Registry, builtinImageRegistry)
+		image.Repository = clearBuiltin(image.Repository, builtinImageRepository)
+		image.Tag = clearBuiltin(image.Tag, builtinImageTag)
+	}
+	values.ResourcesPreset = clearBuiltin(values.ResourcesPreset, builtinResourcesPreset)
+	return true
+}
+
+// clearBuiltin empties a value equal to the former CRD default
+func clearBuiltin(value, builtin string) string {
+	if value == builtin {
+		return ""
+	}
+	return value
+}
+
+// defaultString returns the first non-empty value
+func defaultString(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// defaultImage fills the empty fields of an image, first from the defaults and then from the fallback
+func defaultImage(image *slurmv1.ImageSpec, defaults, fallback slurmv1.ImageSpec) {
+	image.Registry = defaultString(image.Registry, defaults.Registry, fallback.Registry)
+	image.Repository = defaultString(image.Repository, defaults.Repository, fallback.Repository)
+	image.Tag = defaultString(image.Tag, defaults.Tag, fallback.Tag)
+	image.PullPolicy = defaultString(image.PullPolicy, defaults.PullPolicy, fallback.PullPolicy)
+	if len(image.PullSecrets) == 0 && len(defaults.PullSecrets) > 0 {
+		image.PullSecrets = append([]string{}, defaults.PullSecrets...)
+	}
+}
+
+// defaultResources sets the requests and the limits left unset
+func defaultResources(resources, defaults *slurmv1.ResourceSpec) {
+	if defaults == nil {
+		return
+	}
+	if resources.Requests == nil && defaults.Requests != nil {
+		resources.Requests = defaults.Requests.DeepCopy()
+	}
+	if resources.Limits == nil && defaults.Limits != nil {
+		resources.Limits = defaults.Limits.DeepCopy()
+	}
+}
+
+// defaultSlurmdResources sets the requests and the limits of a slurmd group left unset
+func defaultSlurmdResources(resources, defaults *slurmv1.SlurmdResourceSpec) {
+	if defaults == nil {
+		return
+	}
+	if resources.Requests == nil && defaults.Requests != nil {
+		resources.Requests = defaults.Requests.DeepCopy()
+	}
+	if resources.Limits == nil && defaults.Limits != nil {
+		resources.Limits = defaults.Limits.DeepCopy()
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+const testDefaults = `
+images:
+  slurmctld:
+    registry: mirror.example.com
+    repository: data-and-computing/slurm-slurmctld
+    tag: "25.05"
+  slurmd:
+    registry: mirror.example.com
+    repository: data-and-computing/slurm-slurmd
+    tag: "25.05"
+    pullSecrets: [regcred]
+imageMirror: mirror.example.com
+chart:
+  repository: https://charts.example.com
+  version: 1.2.3
+resourcesPreset: small
+resources:
+  login:
+    requests:
+      cpu: 250m
+      memory: 512Mi
+      ephemeral-storage: 1Gi
+storageClasses:
+  shared: cephfs
+  volumes: cephfs
+  mariadb: rbd
+`
+
+var _ = Describe("Operator defaults", func() {
+	var release *slurmv1.SlurmDeployment
+
+	BeforeEach(func() {
+		release = &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "lab", Namespace: "default"},
+			Spec: slurmv1.SlurmDeploymentSpec{
+				Chart: slurmv1.ChartSpec{Name: "slurm-cluster"},
+			},
+		}
+	})
+
+	It("refuses unknown fields", func() {
+		_, err := Parse([]byte("image:\n  slurmctld: {}\n"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fills the fields the SlurmDeployment leaves empty", func() {
+		defaults, err := Parse([]byte(testDefaults))
+		Expect(err).NotTo(HaveOccurred())
+		release.Spec.Values.SlurmdGPU.Image.Tag = "24.11"
+		release.Spec.Values.Persistence.Volumes = []slurmv1.SharedVolumeSpec{
+			{Name: "home", MountPath: "/home"},
+			{Name: "data", MountPath: "/data", ExistingClaim: "datasets"},
+		}
+
+		Apply(release, defaults)
+		values := release.Spec.Values
+		Expect(release.Spec.Chart.Repository).To(Equal("https://charts.example.com"))
+		Expect(release.Spec.Chart.Version).To(Equal("1.2.3"))
+		Expect(values.ImageMirror.Mirror.Registry).To(Equal("mirror.example.com"))
+		Expect(values.ResourcesPreset).To(Equal("small"))
+		Expect(values.Slurmctld.Image).To(Equal(slurmv1.ImageSpec{
+			Registry: "mirror.example.com", Repository: "data-and-computing/slurm-slurmctld", Tag: "25.05"}))
+		Expect(values.SlurmdCPU.Image.Tag).To(Equal("25.05"))
+		Expect(values.SlurmdCPU.Image.PullSecrets).To(Equal([]string{"regcred"}))
+		Expect(values.SlurmdGPU.Image.Tag).To(Equal("24.11"))
+		Expect(values.SlurmdGPU.Image.Repository).To(Equal("data-and-computing/slurm-slurmd"))
+		Expect(values.SlurmLogin.Resources.Requests.CPU).To(Equal("250m"))
+		Expect(values.SlurmLogin.Resources.Limits).To(BeNil())
+		Expect(values.Slurmctld.Resources).To(BeNil())
+		Expect(values.Persistence.Shared.StorageClass).To(Equal("cephfs"))
+		Expect(values.Persistence.Volumes[0].StorageClass).To(Equal("cephfs"))
+		Expect(values.Persistence.Volumes[1].StorageClass).To(BeEmpty())
+		Expect(values.Mariadb.Primary.Persistence.StorageClass).To(Equal("rbd"))
+	})
+
+	It("falls back to the former CRD defaults without operator defaults", func() {
+		release.Spec.Values.Munged.Image.Tag = "25.05"
+		Apply(release, nil)
+		Expect(release.Spec.Values.Munged.Image).To(Equal(slurmv1.ImageSpec{
+			Registry: "localhost", Repository: "data-and-computing", Tag: "25.05"}))
+		Expect(release.Spec.Values.ResourcesPreset).To(Equal("nano"))
+		Expect(release.Spec.Values.Monitoring.Exporter.Image.Repository).To(BeEmpty())
+	})
+
+	It("clears the former CRD defaults once", func() {
+		release.Spec.Values.Munged.Image = slurmv1.ImageSpec{Registry: "localhost", Repository: "data-and-computing", Tag: "latest"}
+		release.Spec.Values.Slurmctld.Image = slurmv1.ImageSpec{Registry: "localhost", Repository: "data-and-computing", Tag: "24.11",
+			PullPolicy: "IfNotPresent"}
+		release.Spec.Values.SlurmLogin.Image = slurmv1.ImageSpec{Registry: "harbor.example.com", Repository: "hpc/login", Tag: "latest"}
+		release.Spec.Values.ResourcesPreset = "nano"
+		release.Status.ObservedGeneration = 3
+
+		Expect(Migrate(release)).To(BeTrue())
+		values := release.Spec.Values
+		Expect(values.Munged.Image).To(Equal(slurmv1.ImageSpec{}))
+		Expect(values.Slurmctld.Image).To(Equal(slurmv1.ImageSpec{Tag: "24.11", PullPolicy: "IfNotPresent"}))
+		Expect(values.SlurmLogin.Image).To(Equal(slurmv1.ImageSpec{Registry: "harbor.example.com", Repository: "hpc/login"}))
+		Expect(values.ResourcesPreset).To(BeEmpty())
+		Expect(release.Annotations).To(HaveKeyWithValue(MigratedAnnotation, "true"))
+
+		// a value set after the migration is kept, even when it equals a former CRD default
+		release.Spec.Values.Munged.Image.Tag = "latest"
+		Expect(Migrate(release)).To(BeFalse())
+		Expect(release.Spec.Values.Munged.Image.Tag).To(Equal("latest"))
+	})
+
+	It("only marks a SlurmDeployment that was never reconciled", func() {
+		release.Spec.Values.Munged.Image = slurmv1.ImageSpec{Registry: "localhost", Repository: "data-and-computing", Tag: "latest"}
+		release.Spec.Values.ResourcesPreset = "nano"
+
+		Expect(Migrate(release)).To(BeTrue())
+		Expect(release.Spec.Values.Munged.Image).To(Equal(slurmv1.ImageSpec{Registry: "localhost", Repository: "data-and-computing", Tag: "latest"}))
+		Expect(release.Spec.Values.ResourcesPreset).To(Equal("nano"))
+		Expect(release.Annotations).To(HaveKeyWithValue(MigratedAnnotation, "true"))
+
+		release.Status.ObservedGeneration = 1
+		Expect(Migrate(release)).To(BeFalse())
+		Expect(release.Spec.Values.ResourcesPreset).To(Equal("nano"))
+	})
+
+	Context("Store", func() {
+		It("announces a change only when the defaults differ", func() {
+			store := NewStore()
+			first, err := Parse([]byte(testDefaults))
+			Expect(err).NotTo(HaveOccurred())
+			second, err := Parse([]byte(testDefaults))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(store.Set(first)).To(BeTrue())
+			Expect(store.Set(second)).To(BeFalse())
+			Expect(store.Changes()).To(HaveLen(1))
+			Expect(store.Get().Chart.Version).To(Equal("1.2.3"))
+		})
+
+		It("loads a ConfigMap and runs without defaults when it is missing", func() {
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "slurm-operator-defaults", Namespace: "slurm-operator-system"},
+				Data:       map[string]string{ConfigMapKey: testDefaults},
+			}
+			reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+
+			store := NewStore()
+			Expect(store.LoadConfigMap(context.Background(), reader, client.ObjectKeyFromObject(configMap))).To(Succeed())
+			Expect(store.Get().ResourcesPreset).To(Equal("small"))
+
+			missing := types.NamespacedName{Namespace: "slurm-operator-system", Name: "missing"}
+			Expect(store.LoadConfigMap(context.Background(), reader, missing)).To(Succeed())
+			Expect(store.Get()).To(BeNil())
+		})
+
+		It("reloads a changed file and keeps the defaults of an invalid one", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "defaults.yaml")
+			Expect(os.WriteFile(path, []byte(testDefaults), 0o600)).To(Succeed())
+			store := NewStore()
+			Expect(store.LoadFile(path)).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() { _ = store.WatchFile(path, 10*time.Millisecond)(ctx) }()
+
+			Expect(os.WriteFile(path, []byte("resourcesPreset: large\n"), 0o600)).To(Succeed())
+			Eventually(func() string { return store.Get().ResourcesPreset }).Should(Equal("large"))
+
+			Expect(os.WriteFile(path, []byte("resourcesPreset: [\n"), 0o600)).To(Succeed())
+			Consistently(func() string { return store.Get().ResourcesPreset }, 100*time.Millisecond).Should(Equal("large"))
+		})
+	})
+})
@@ -0,0 +1,157 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// Store keeps the current operator defaults and announces every change of them, so that the SlurmDeployments using
+// them are reconciled again
+type Store struct {
+	mu       sync.RWMutex
+	defaults *Defaults
+	changes  chan event.TypedGenericEvent[*Defaults]
+}
+
+// NewStore creates a Store without defaults
+func NewStore() *Store {
+	return &Store{changes: make(chan event.TypedGenericEvent[*Defaults], 1)}
+}
+
+// Get returns the current defaults, nil when there are none. It must not be modified.
+func (s *Store) Get() *Defaults {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.defaults
+}
+
+// Set replaces the defaults and announces the change, it returns false when the defaults did not change
+func (s *Store) Set(defaults *Defaults) bool {
+	s.mu.Lock()
+	changed := !equality.Semantic.DeepEqual(s.defaults, defaults)
+	s.defaults = defaults
+	s.mu.Unlock()
+	if !changed {
+		return false
+	}
+	// a change still queued already makes every SlurmDeployment read the latest defaults
+	select {
+	case s.changes <- event.TypedGenericEvent[*Defaults]{Object: defaults}:
+	default:
+	}
+	return true
+}
+
+// Changes delivers an event after the defaults changed
+func (s *Store) Changes() <-chan event.TypedGenericEvent[*Defaults] {
+	return s.changes
+}
+
+// FromConfigMap parses the defaults of a ConfigMap, a ConfigMap without the defaults key has no defaults
+func FromConfigMap(configMap *corev1.ConfigMap) (*Defaults, error) {
+	data, found := configMap.Data[ConfigMapKey]
+	if !found {
+		return nil, nil
+	}
+	return Parse([]byte(data))
+}
+
+// LoadConfigMap reads the defaults from a ConfigMap, a missing ConfigMap leaves the Store without defaults
+func (s *Store) LoadConfigMap(ctx context.Context, reader client.Reader, key types.NamespacedName) error {
+	configMap := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, key, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Printf("Defaults ConfigMap %s not found, running without operator defaults", key)
+			s.Set(nil)
+			return nil
+		}
+		return fmt.Errorf("failed to get the defaults ConfigMap %s: %v", key, err)
+	}
+	defaults, err := FromConfigMap(configMap)
+	if err != nil {
+		return fmt.Errorf("ConfigMap %s: %v", key, err)
+	}
+	s.Set(defaults)
+	return nil
+}
+
+// LoadFile reads the defaults from a YAML file
+func (s *Store) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the defaults file: %v", err)
+	}
+	defaults, err := Parse(data)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	s.Set(defaults)
+	return nil
+}
+
+// WatchFile re-reads the defaults file whenever its content changes, e.g. once the kubelet updated a mounted
+// ConfigMap. A file that cannot be read or parsed keeps the previous defaults.
+func (s *Store) WatchFile(path string, interval time.Duration) manager.RunnableFunc {
+	return func(ctx context.Context) error {
+		// the first read compares against the defaults loaded at startup, Set ignores them when unchanged
+		var last []byte
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				log.Printf("Failed to read the defaults file %s, keeping the previous defaults: %v", path, err)
+				continue
+			}
+			if bytes.Equal(data, last) {
+				continue
+			}
+			last = data
+			defaults, err := Parse(data)
+			if err != nil {
+				log.Printf("Keeping the previous defaults, %s: %v", path, err)
+				continue
+			}
+			if s.Set(defaults) {
+				log.Printf("Reloaded the operator defaults from %s", path)
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics holds the custom Prometheus metrics the operator exports through the
+// controller-runtime metrics server.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	HelmActionInstall   = "install"
+	HelmActionUpgrade   = "upgrade"
+	HelmActionUninstall = "uninstall"
+
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+var (
+	// ComponentReadyReplicas is the number of ready replicas of each component of a SlurmDeployment
+	ComponentReadyReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "slurm_operator_component_ready_replicas",
+		Help: "Number of ready replicas of a SlurmDeployment component",
+	}, []string{"namespace", "name", "component"})
+
+	// ComponentDesiredReplicas is the number of desired replicas of each component of a SlurmDeployment
+	ComponentDesiredReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "slurm_operator_component_desired_replicas",
+		Help: "Number of desired replicas of a SlurmDeployment component",
+	}, []string{"namespace", "name", "component"})
+
+	// HelmActionsTotal counts helm install, upgrade and uninstall runs by result
+	HelmActionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "slurm_operator_helm_actions_total",
+		Help: "Number of helm actions run for a SlurmDeployment, by action and result",
+	}, []string{"namespace", "name", "action", "result"})
+
+	// HelmActionDuration observes how long helm install, upgrade and uninstall runs take
+	HelmActionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "slurm_operator_helm_action_duration_seconds",
+		Help:    "Duration of helm actions run for a SlurmDeployment",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"namespace", "name", "action"})
+
+	// ChartDownloadDuration observes the latency of downloading and loading the slurm chart
+	ChartDownloadDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "slurm_operator_chart_download_duration_seconds",
+		Help:    "Latency of downloading and loading a helm chart",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+	}, []string{"chart", "version", "result"})
+
+	// SlurmctldRestartsTotal counts slurmctld pod deletions triggered by slurmd StatefulSet changes
+	SlurmctldRestartsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "slurm_operator_slurmctld_restarts_total",
+		Help: "Number of slurmctld restarts triggered by the operator",
+	}, []string{"namespace", "name"})
+
+	// SlurmNodes is the number of slurm nodes in each state as reported by sinfo
+	SlurmNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "slurm_operator_slurm_nodes",
+		Help: "Number of slurm nodes of a SlurmDeployment by state",
+	}, []string{"namespace", "name", "state"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		ComponentReadyReplicas,
+		ComponentDesiredReplicas,
+		HelmActionsTotal,
+		HelmActionDuration,
+		ChartDownloadDuration,
+		SlurmctldRestartsTotal,
+		SlurmNodes,
+	)
+}
+
+// SetComponentReplicas records the ready and desired replicas of a component
+func SetComponentReplicas(namespace, name, component string, ready, desired int32) {
+	ComponentReadyReplicas.WithLabelValues(namespace, name, component).Set(float64(ready))
+	ComponentDesiredReplicas.WithLabelValues(namespace, name, component).Set(float64(desired))
+}
+
+// ObserveHelmAction records the duration and result of a helm action started at start
+func ObserveHelmAction(namespace, name, action string, start time.Time, err error) {
+	HelmActionDuration.WithLabelValues(namespace, name, action).Observe(time.Since(start).Seconds())
+	HelmActionsTotal.WithLabelValues(namespace, name, action, resultOf(err == nil)).Inc()
+}
+
+// ObserveChartDownload records the latency of a chart download started at start
+func ObserveChartDownload(chart, version string, start time.Time, succeeded bool) {
+	ChartDownloadDuration.WithLabelValues(chart, version, resultOf(succeeded)).Observe(time.Since(start).Seconds())
+}
+
+// SetSlurmNodeStates replaces the node state counts of a SlurmDeployment
+func SetSlurmNodeStates(namespace, name string, counts map[string]int) {
+	SlurmNodes.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "name": name})
+	for state, count := range counts {
+		SlurmNodes.WithLabelValues(namespace, name, state).Set(float64(count))
+	}
+}
+
+// ForgetSlurmDeployment drops the gauges of a deleted SlurmDeployment, counters are kept so the
+// uninstall itself stays visible
+func ForgetSlurmDeployment(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	ComponentReadyReplicas.DeletePartialMatch(labels)
+	ComponentDesiredReplicas.DeletePartialMatch(labels)
+	SlurmNodes.DeletePartialMatch(labels)
+}
+
+func resultOf(succeeded bool) string {
+	if succeeded {
+		return resultSuccess
+	}
+	return resultFailure
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Metrics", func() {
+	It("replaces the slurm node states of a SlurmDeployment", func() {
+		SetSlurmNodeStates("slurm", "states", map[string]int{"idle": 2, "down": 1})
+		SetSlurmNodeStates("slurm", "other", map[string]int{"down": 4})
+		SetSlurmNodeStates("slurm", "states", map[string]int{"idle": 1, "allocated": 2})
+
+		Expect(testutil.ToFloat64(SlurmNodes.WithLabelValues("slurm", "states", "idle"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(SlurmNodes.WithLabelValues("slurm", "states", "allocated"))).To(Equal(2.0))
+		Expect(SlurmNodes.DeleteLabelValues("slurm", "states", "down")).To(BeFalse(), "stale states are dropped")
+		Expect(testutil.ToFloat64(SlurmNodes.WithLabelValues("slurm", "other", "down"))).To(Equal(4.0))
+	})
+
+	It("counts helm actions by result", func() {
+		start := time.Now()
+		ObserveHelmAction("slurm", "helm", HelmActionInstall, start, nil)
+		ObserveHelmAction("slurm", "helm", HelmActionUpgrade, start, errors.New("timed out"))
+		ObserveHelmAction("slurm", "helm", HelmActionUpgrade, start, nil)
+		ObserveHelmAction("slurm", "helm", HelmActionUpgrade, start, errors.New("timed out"))
+
+		Expect(testutil.ToFloat64(HelmActionsTotal.WithLabelValues("slurm", "helm", HelmActionInstall, resultSuccess))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(HelmActionsTotal.WithLabelValues("slurm", "helm", HelmActionUpgrade, resultSuccess))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(HelmActionsTotal.WithLabelValues("slurm", "helm", HelmActionUpgrade, resultFailure))).To(Equal(2.0))
+		Expect(testutil.CollectAndCount(HelmActionDuration)).To(Equal(2), "durations are observed per action")
+	})
+
+	It("forgets the gauges of a deleted SlurmDeployment and keeps its counters", func() {
+		SetComponentReplicas("slurm", "deleted", "slurmctld", 1, 1)
+		SetComponentReplicas("slurm", "kept", "slurmctld", 0, 1)
+		SetSlurmNodeStates("slurm", "deleted", map[string]int{"idle": 3})
+		SlurmctldRestartsTotal.WithLabelValues("slurm", "deleted").Inc()
+
+		ForgetSlurmDeployment("slurm", "deleted")
+
+		Expect(ComponentReadyReplicas.DeleteLabelValues("slurm", "deleted", "slurmctld")).To(BeFalse())
+		Expect(ComponentDesiredReplicas.DeleteLabelValues("slurm", "deleted", "slurmctld")).To(BeFalse())
+		Expect(SlurmNodes.DeleteLabelValues("slurm", "deleted", "idle")).To(BeFalse())
+		Expect(testutil.ToFloat64(ComponentDesiredReplicas.WithLabelValues("slurm", "kept", "slurmctld"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(SlurmctldRestartsTotal.WithLabelValues("slurm", "deleted"))).To(Equal(1.0))
+	})
+})
@@ -0,0 +1,339 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/postrender"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+const (
+	// mungedContainerName is the name of the munged container the chart adds to the pods authenticating with munge
+	mungedContainerName   = "munged"
+	slurmdContainerName   = "slurmd"
+	slurmdbdContainerName = "slurmdbd"
+)
+
+// podTemplateWorkloadKinds are the rendered kinds carrying a pod template under spec.template
+var podTemplateWorkloadKinds = []string{"Deployment", "StatefulSet", "DaemonSet"}
+
+// releasePostRenderer strategic-merges the shared volumes, the health probes and the podTemplate overrides of a
+// SlurmDeployment onto the workloads rendered by the chart, and the login service settings onto the login Service
+type releasePostRenderer struct {
+	release *slurmv1.SlurmDeployment
+	// volumes are mounted on the workloads of sharedVolumes, which maps a workload name to its component
+	volumes       []slurmv1.SharedVolumeSpec
+	sharedVolumes map[string]string
+	// munged is merged first onto every pod template running munged
+	munged [][]byte
+	// workloads maps a workload name to the patches of its component, in order
+	workloads map[string][][]byte
+	// services maps a Service name to its patch
+	services map[string][]byte
+	// documents are appended to the rendered manifests, e.g. the gres.conf ConfigMap
+	documents [][]byte
+	// topology mounts the topology.conf kept by the operator into slurmctld
+	topology bool
+}
+
+// podTemplatePatches lists the partial pod templates of a component, the probes come first so that the
+// podTemplate override can replace them
+type podTemplatePatches struct {
+	component string
+	templates []*corev1.PodTemplateSpec
+}
+
+// ReleasePostRenderer returns the helm post-renderer applying the shared volumes, the probes and podTemplate
+// overrides of every component and the login service settings
+func ReleasePostRenderer(release *slurmv1.SlurmDeployment, valuesSpec *slurmv1.ValuesSpec) (postrender.PostRenderer, error) {
+	renderer := &releasePostRenderer{
+		release: release,
+		volumes: valuesSpec.Persistence.Volumes,
+		sharedVolumes: map[string]string{
+			ComponentName(release, "slurmctld"):  SharedVolumeSlurmctld,
+			ComponentName(release, "slurmd-cpu"): SharedVolumeSlurmd,
+			ComponentName(release, "slurmd-gpu"): SharedVolumeSlurmd,
+			ComponentName(release, "login"):      SharedVolumeLogin,
+		},
+		workloads: map[string][][]byte{},
+		services:  map[string][]byte{},
+		topology:  valuesSpec.SlurmConfig.Topology.Enabled,
+	}
+	// gres.conf is mounted before the probes and podTemplate of slurmd so that an override can still replace it
+	var gresConfCPU, gresConfGPU *corev1.PodTemplateSpec
+	if gresConf := BuildGresConf(release, valuesSpec); gresConf != "" {
+		document, err := yaml.Marshal(gresConfMap(release, gresConf))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render gres.conf: %v", err)
+		}
+		renderer.documents = append(renderer.documents, document)
+		gresConfCPU, gresConfGPU = gresConfPodTemplate(release, gresConf), gresConfPodTemplate(release, gresConf)
+	}
+	for _, component := range []podTemplatePatches{
+		{"munged", []*corev1.PodTemplateSpec{
+			probesPodTemplate(mungedContainerName, &valuesSpec.Munged.Probes, mungedProbeChecks, valuesSpec.Munged.DiagnosticMode),
+			valuesSpec.Munged.PodTemplate,
+		}},
+		{"slurmctld", []*corev1.PodTemplateSpec{
+			probesPodTemplate(SlurmctldContainerName, &valuesSpec.Slurmctld.Probes, slurmctldProbeChecks, valuesSpec.Slurmctld.DiagnosticMode),
+			valuesSpec.Slurmctld.PodTemplate,
+		}},
+		{"slurmdbd", []*corev1.PodTemplateSpec{
+			probesPodTemplate(slurmdbdContainerName, &valuesSpec.Slurmdbd.Probes, slurmdbdProbeChecks, valuesSpec.Slurmdbd.DiagnosticMode),
+			valuesSpec.Slurmdbd.PodTemplate,
+		}},
+		{"login", []*corev1.PodTemplateSpec{valuesSpec.SlurmLogin.PodTemplate}},
+		{"slurmd-cpu", []*corev1.PodTemplateSpec{
+			gresConfCPU,
+			probesPodTemplate(slurmdContainerName, &valuesSpec.SlurmdCPU.Probes, slurmdProbeChecks, valuesSpec.SlurmdCPU.DiagnosticMode),
+			valuesSpec.SlurmdCPU.PodTemplate,
+		}},
+		{"slurmd-gpu", []*corev1.PodTemplateSpec{
+			gresConfGPU,
+			probesPodTemplate(slurmdContainerName, &valuesSpec.SlurmdGPU.Probes, slurmdProbeChecks, valuesSpec.SlurmdGPU.DiagnosticMode),
+			valuesSpec.SlurmdGPU.PodTemplate,
+		}},
+	} {
+		patches := [][]byte{}
+		for _, template := range component.templates {
+			patch, patchErr := podTemplatePatch(template)
+			if patchErr != nil {
+				return nil, fmt.Errorf("invalid %s podTemplate: %v", component.component, patchErr)
+			}
+			if patch != nil {
+				patches = append(patches, patch)
+			}
+		}
+		if len(patches) == 0 {
+			continue
+		}
+		if component.component == "munged" {
+			renderer.munged = patches
+		} else {
+			renderer.workloads[ComponentName(release, component.component)] = patches
+		}
+	}
+	loginService, err := mergePatch(loginServicePatch(&valuesSpec.SlurmLogin.Service), true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid login service: %v", err)
+	}
+	renderer.services[LoginServiceName(release)] = loginService
+	return renderer, nil
+}
+
+// Run patches the pod template of every matching workload and the matching Services, the other documents are passed
+// through untouched and the documents of the renderer are appended
+func (p *releasePostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	documents := strings.Split(renderedManifests.String(), "\n---")
+	for i, document := range documents {
+		patched, err := p.patchDocument(document)
+		if err != nil {
+			return nil, err
+		}
+		documents[i] = patched
+	}
+	for _, document := range p.documents {
+		documents = append(documents, "\n"+string(document))
+	}
+	return bytes.NewBufferString(strings.Join(documents, "\n---")), nil
+}
+
+// patchDocument merges the overrides onto one manifest document when it is a workload or Service of the release
+func (p *releasePostRenderer) patchDocument(document string) (string, error) {
+	body, separated := strings.CutPrefix(strings.TrimLeft(document, "\n"), "---")
+	object := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(body), &object); err != nil {
+		return "", fmt.Errorf("failed to parse manifest document: %v", err)
+	}
+	kind, _ := object["kind"].(string)
+	metadata, _ := object["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+
+	switch {
+	case kind == "Service" && p.services[name] != nil:
+		merged, err := strategicMerge(object, [][]byte{p.services[name]}, corev1.Service{})
+		if err != nil {
+			return "", fmt.Errorf("failed to merge the service settings onto Service %s: %v", name, err)
+		}
+		object = merged
+	case CheckIfExistInArray(podTemplateWorkloadKinds, kind):
+		spec, _ := object["spec"].(map[string]interface{})
+		template, _ := spec["template"].(map[string]interface{})
+		if template == nil {
+			return document, nil
+		}
+		patches := [][]byte{}
+		if component, found := p.sharedVolumes[name]; found {
+			// the shared volumes are mounted in every container but munged, whatever the chart names them
+			mounted := []string{}
+			for _, container := range containerNames(template) {
+				if container != mungedContainerName {
+					mounted = append(mounted, container)
+				}
+			}
+			volumes, err := podTemplatePatch(sharedVolumesPodTemplate(p.release, p.volumes, component, mounted))
+			if err != nil {
+				return "", fmt.Errorf("failed to render the shared volumes of %s %s: %v", kind, name, err)
+			}
+			if volumes != nil {
+				patches = append(patches, volumes)
+			}
+		}
+		if p.topology && name == ComponentName(p.release, "slurmctld") {
+			conf, err := p.slurmctldConfPatch(template)
+			if err != nil {
+				return "", fmt.Errorf("failed to mount topology.conf into %s %s: %v", kind, name, err)
+			}
+			if conf != nil {
+				patches = append(patches, conf)
+			}
+		}
+		if CheckIfExistInArray(containerNames(template), mungedContainerName) {
+			patches = append(patches, p.munged...)
+		}
+		patches = append(patches, p.workloads[name]...)
+		if len(patches) == 0 {
+			return document, nil
+		}
+		merged, err := strategicMerge(template, patches, corev1.PodTemplateSpec{})
+		if err != nil {
+			return "", fmt.Errorf("failed to merge podTemplate onto %s %s: %v", kind, name, err)
+		}
+		spec["template"] = merged
+	default:
+		return document, nil
+	}
+
+	out, err := yaml.Marshal(object)
+	if err != nil {
+		return "", err
+	}
+	if separated {
+		return "---\n" + string(out), nil
+	}
+	return "\n" + string(out), nil
+}
+
+// slurmctldConfPatch renders the patch mounting the config directory of slurmctld from its rendered pod template
+func (p *releasePostRenderer) slurmctldConfPatch(template map[string]interface{}) ([]byte, error) {
+	raw, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+	rendered := &corev1.PodTemplateSpec{}
+	if err := json.Unmarshal(raw, rendered); err != nil {
+		return nil, err
+	}
+	return podTemplatePatch(slurmctldConfPodTemplate(p.release, rendered))
+}
+
+// strategicMerge applies the patches in order onto a decoded object of the type of schema
+func strategicMerge(object map[string]interface{}, patches [][]byte, schema interface{}) (map[string]interface{}, error) {
+	merged, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	for _, patch := range patches {
+		if merged, err = strategicpatch.StrategicMergePatch(merged, patch, schema); err != nil {
+			return nil, err
+		}
+	}
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(merged, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// containerNames lists the containers of a rendered pod template
+func containerNames(template map[string]interface{}) []string {
+	podSpec, _ := template["spec"].(map[string]interface{})
+	containers, _ := podSpec["containers"].([]interface{})
+	names := []string{}
+	for _, container := range containers {
+		if fields, ok := container.(map[string]interface{}); ok {
+			if name, ok := fields["name"].(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// probeFields are the probes of a container, a patched probe replaces the rendered one since a probe with the
+// handlers of both would be invalid
+var probeFields = []string{"livenessProbe", "readinessProbe", "startupProbe"}
+
+// podTemplatePatch turns an override into a strategic merge patch
+func podTemplatePatch(override *corev1.PodTemplateSpec) ([]byte, error) {
+	if override == nil {
+		return nil, nil
+	}
+	patch, err := mergePatch(override, false)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(patch, &fields); err != nil {
+		return nil, err
+	}
+	podSpec, _ := fields["spec"].(map[string]interface{})
+	for _, list := range []string{"initContainers", "containers"} {
+		containers, _ := podSpec[list].([]interface{})
+		for _, container := range containers {
+			containerFields, _ := container.(map[string]interface{})
+			for _, field := range probeFields {
+				if probe, ok := containerFields[field].(map[string]interface{}); ok {
+					probe["$patch"] = "replace"
+				}
+			}
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// mergePatch marshals a partial object into a strategic merge patch. Unset fields such as containers marshal as
+// null, which would delete them from the rendered object, so they are dropped together with the status.
+func mergePatch(partial interface{}, dropStatus bool) ([]byte, error) {
+	raw, err := json.Marshal(partial)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	if dropStatus {
+		delete(fields, "status")
+	}
+	return json.Marshal(pruneNulls(fields))
+}
+
+// pruneNulls drops null fields recursively
+func pruneNulls(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, field := range typed {
+			if field == nil {
+				delete(typed, key)
+				continue
+			}
+			typed[key] = pruneNulls(field)
+		}
+		return typed
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = pruneNulls(item)
+		}
+		return typed
+	default:
+		return value
+	}
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+// updateGolden rewrites the golden files from the current output, run with `go test ./internal/utils/ -args -update`
+var updateGolden = flag.Bool("update", false, "rewrite the golden files under testdata")
+
+var _ = Describe("ReleasePostRenderer", func() {
+	var (
+		release    *slurmv1.SlurmDeployment
+		valuesSpec *slurmv1.ValuesSpec
+		rendered   []byte
+	)
+
+	enabled := slurmv1.ProbeSpec{Enabled: true, InitialDelaySeconds: 30, TimeoutSeconds: 5, PeriodSeconds: 10, SuccessThreshold: 1, FailureThreshold: 6}
+
+	BeforeEach(func() {
+		release = &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Spec:       slurmv1.SlurmDeploymentSpec{Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"}},
+		}
+		valuesSpec = &slurmv1.ValuesSpec{}
+		valuesSpec.Persistence.Volumes = []slurmv1.SharedVolumeSpec{{Name: "home", MountPath: "/home"}}
+		valuesSpec.Munged.Probes.Liveness = enabled
+		valuesSpec.Munged.PodTemplate = &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Tolerations: []corev1.Toleration{{Key: "slurm", Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{Name: mungedContainerName, Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+			}}},
+		}}
+		// the podTemplate readiness probe of slurmctld replaces the probe rendered from the probe settings
+		valuesSpec.Slurmctld.Probes.Readiness = enabled
+		valuesSpec.Slurmctld.Probes.Liveness = enabled
+		valuesSpec.Slurmctld.PodTemplate = &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: SlurmctldContainerName, ReadinessProbe: &corev1.Probe{
+				ProbeHandler:  corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(slurmctldPort)}},
+				PeriodSeconds: 3,
+			}}},
+		}}
+		valuesSpec.SlurmdCPU.Probes.Startup = enabled
+		valuesSpec.SlurmdCPU.PodTemplate = &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{"node-role.kubernetes.io/compute": ""},
+		}}
+		valuesSpec.SlurmLogin.Service = slurmv1.LoginServiceSpec{
+			Type:                  corev1.ServiceTypeNodePort,
+			Annotations:           map[string]string{"metallb.universe.tf/address-pool": "login"},
+			NodePort:              30022,
+			ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+		}
+
+		var err error
+		rendered, err = os.ReadFile(filepath.Join("testdata", "post_render", "rendered.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	run := func() string {
+		renderer, err := ReleasePostRenderer(release, valuesSpec)
+		Expect(err).NotTo(HaveOccurred())
+		out, err := renderer.Run(bytes.NewBuffer(rendered))
+		Expect(err).NotTo(HaveOccurred())
+		return out.String()
+	}
+
+	It("should patch the rendered manifests as in the golden file", func() {
+		out := run()
+		golden := filepath.Join("testdata", "post_render", "patched.yaml")
+		if *updateGolden {
+			Expect(os.WriteFile(golden, []byte(out), 0o644)).To(Succeed())
+		}
+		expected, err := os.ReadFile(golden)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal(string(expected)))
+	})
+
+	It("should pass the documents it does not patch through untouched", func() {
+		out := run()
+		documents := strings.Split(string(rendered), "\n---")
+		for _, index := range []int{0, 2, 7} {
+			Expect(out).To(ContainSubstring(documents[index]))
+		}
+	})
+
+	It("should pass every document through untouched when there is nothing to patch", func() {
+		valuesSpec = &slurmv1.ValuesSpec{}
+		valuesSpec.SlurmLogin.Service.Type = corev1.ServiceTypeClusterIP
+		out := run()
+		documents := strings.Split(string(rendered), "\n---")
+		for index, document := range documents {
+			if index != 1 {
+				Expect(out).To(ContainSubstring(document))
+			}
+		}
+	})
+})
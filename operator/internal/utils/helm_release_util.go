@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+const (
+	defaultHelmTimeout    = 5 * time.Minute
+	defaultHelmMaxHistory = 10
+)
+
+// HelmTimeout returns the timeout of an upgrade policy, falling back to 5 minutes
+func HelmTimeout(policy slurmv1.UpgradePolicySpec) time.Duration {
+	if policy.Timeout != nil && policy.Timeout.Duration > 0 {
+		return policy.Timeout.Duration
+	}
+	return defaultHelmTimeout
+}
+
+// ApplyUpgradePolicy copies an upgrade policy onto a helm upgrade action
+func ApplyUpgradePolicy(upgrade *action.Upgrade, policy slurmv1.UpgradePolicySpec) {
+	upgrade.Atomic = policy.Atomic
+	upgrade.Wait = policy.Wait || policy.Atomic
+	upgrade.Timeout = HelmTimeout(policy)
+	upgrade.CleanupOnFail = policy.CleanupOnFail
+	upgrade.MaxHistory = defaultHelmMaxHistory
+	if policy.MaxHistory > 0 {
+		upgrade.MaxHistory = int(policy.MaxHistory)
+	}
+}
+
+// ApplyInstallPolicy copies the parts of an upgrade policy that apply to a first install
+func ApplyInstallPolicy(install *action.Install, policy slurmv1.UpgradePolicySpec) {
+	install.Atomic = policy.Atomic
+	install.Wait = policy.Wait || policy.Atomic
+	install.Timeout = HelmTimeout(policy)
+}
+
+// ReleaseRevisions returns the latest revision of a helm release and the latest one that deployed successfully
+func ReleaseRevisions(actionConfig *action.Configuration, name string) (int, int, error) {
+	history, err := action.NewHistory(actionConfig).Run(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	current, lastSuccessful := 0, 0
+	for _, revision := range history {
+		if revision.Version > current {
+			current = revision.Version
+		}
+		if revision.Info == nil {
+			continue
+		}
+		if status := revision.Info.Status; status == release.StatusDeployed || status == release.StatusSuperseded {
+			if revision.Version > lastSuccessful {
+				lastSuccessful = revision.Version
+			}
+		}
+	}
+	return current, lastSuccessful, nil
+}
+
+// DeployedRevision returns the revision of a helm history that is currently deployed, the one an atomic upgrade rolls
+// back to when it fails, or 0 when none is
+func DeployedRevision(history []*release.Release) int {
+	deployed := 0
+	for _, revision := range history {
+		if revision.Info != nil && revision.Info.Status == release.StatusDeployed && revision.Version > deployed {
+			deployed = revision.Version
+		}
+	}
+	return deployed
+}
+
+// ReleaseValuesHash identifies what an upgrade would apply: the chart, the rendered chart values and the values the
+// post-renderer patches the workloads with
+func ReleaseValuesHash(chartSpec slurmv1.ChartSpec, chartValues map[string]interface{}, valuesSpec *slurmv1.ValuesSpec) (string, error) {
+	data, err := json.Marshal(struct {
+		Chart       slurmv1.ChartSpec      `json:"chart"`
+		ChartValues map[string]interface{} `json:"chartValues"`
+		Values      *slurmv1.ValuesSpec    `json:"values"`
+	}{chartSpec, chartValues, valuesSpec})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+var _ = Describe("helm release", func() {
+	revision := func(version int, status release.Status) *release.Release {
+		return &release.Release{Version: version, Info: &release.Info{Status: status}}
+	}
+
+	DescribeTable("DeployedRevision",
+		func(history []*release.Release, expected int) {
+			Expect(DeployedRevision(history)).To(Equal(expected))
+		},
+		Entry("no history", nil, 0),
+		Entry("first install failed", []*release.Release{revision(1, release.StatusFailed)}, 0),
+		Entry("upgrades in order", []*release.Release{
+			revision(1, release.StatusSuperseded), revision(2, release.StatusSuperseded), revision(3, release.StatusDeployed),
+		}, 3),
+		// after rolling 4 back to 2, helm deploys 2 again as revision 5
+		Entry("earlier rollback", []*release.Release{
+			revision(2, release.StatusSuperseded), revision(4, release.StatusFailed), revision(5, release.StatusDeployed),
+		}, 5),
+		Entry("pending upgrade", []*release.Release{revision(3, release.StatusDeployed), revision(4, release.StatusPendingUpgrade)}, 3),
+		Entry("revision without info", []*release.Release{{Version: 7}, revision(6, release.StatusDeployed)}, 6),
+	)
+})
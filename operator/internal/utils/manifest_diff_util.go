@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+)
+
+// ManifestDiff summarizes the difference between two rendered helm manifests, keyed by Kind/name
+type ManifestDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+	// Unified holds a unified diff of every added, removed or changed resource
+	Unified string
+}
+
+// Summary renders the counts of a diff in one line, e.g. "1 added, 0 removed, 2 changed"
+func (d ManifestDiff) Summary() string {
+	return fmt.Sprintf("%d added, %d removed, %d changed", len(d.Added), len(d.Removed), len(d.Changed))
+}
+
+// Empty tells whether both manifests render the same resources
+func (d ManifestDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// redactedValue replaces the values of Secrets in a diff, like kubectl diff does
+const redactedValue = "***"
+
+// DiffManifests compares the live manifest of a release against the manifest of a dry-run. The data and stringData
+// values of Secrets are redacted on both sides, a changed value only shows as changed.
+func DiffManifests(live, desired string) (ManifestDiff, error) {
+	liveResources, err := splitManifest(live)
+	if err != nil {
+		return ManifestDiff{}, err
+	}
+	desiredResources, err := splitManifest(desired)
+	if err != nil {
+		return ManifestDiff{}, err
+	}
+
+	keys := []string{}
+	for key := range liveResources {
+		keys = append(keys, key)
+	}
+	for key := range desiredResources {
+		if _, found := liveResources[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	diff := ManifestDiff{}
+	unified := strings.Builder{}
+	for _, key := range keys {
+		before, inLive := liveResources[key]
+		after, inDesired := desiredResources[key]
+		switch {
+		case !inLive:
+			diff.Added = append(diff.Added, key)
+		case !inDesired:
+			diff.Removed = append(diff.Removed, key)
+		case before != after:
+			diff.Changed = append(diff.Changed, key)
+		default:
+			continue
+		}
+		if strings.HasPrefix(key, "Secret/") {
+			var redactErr error
+			if before, after, redactErr = redactSecrets(before, after); redactErr != nil {
+				return diff, redactErr
+			}
+		}
+		text, diffErr := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(before),
+			B:        difflib.SplitLines(after),
+			FromFile: "live/" + key,
+			ToFile:   "desired/" + key,
+			Context:  3,
+		})
+		if diffErr != nil {
+			return diff, diffErr
+		}
+		unified.WriteString(text)
+	}
+	diff.Unified = unified.String()
+	return diff, nil
+}
+
+// splitManifest splits a multi-document manifest into documents keyed by Kind/name
+func splitManifest(manifest string) (map[string]string, error) {
+	resources := map[string]string{}
+	for _, document := range strings.Split(manifest, "\n---") {
+		document = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(document), "---"))
+		if document == "" {
+			continue
+		}
+		header := struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}{}
+		if err := yaml.Unmarshal([]byte(document), &header); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest document: %v", err)
+		}
+		if header.Kind == "" {
+			// only comments, e.g. a template that rendered nothing
+			continue
+		}
+		resources[fmt.Sprintf("%s/%s", header.Kind, header.Metadata.Name)] = document + "\n"
+	}
+	return resources, nil
+}
+
+// redactSecrets masks the data and stringData values of a Secret in its live and desired documents, either of which
+// may be empty. A value that differs between both is marked as the before and the after value.
+func redactSecrets(live, desired string) (string, string, error) {
+	liveSecret, desiredSecret := map[string]interface{}{}, map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(live), &liveSecret); err != nil {
+		return "", "", fmt.Errorf("failed to parse secret: %v", err)
+	}
+	if err := yaml.Unmarshal([]byte(desired), &desiredSecret); err != nil {
+		return "", "", fmt.Errorf("failed to parse secret: %v", err)
+	}
+	for _, field := range []string{"data", "stringData"} {
+		liveValues, _ := liveSecret[field].(map[string]interface{})
+		desiredValues, _ := desiredSecret[field].(map[string]interface{})
+		for key, value := range liveValues {
+			desiredValue, found := desiredValues[key]
+			switch {
+			case !found:
+				liveValues[key] = redactedValue
+			case reflect.DeepEqual(value, desiredValue):
+				liveValues[key], desiredValues[key] = redactedValue, redactedValue
+			default:
+				liveValues[key], desiredValues[key] = redactedValue+" (before)", redactedValue+" (after)"
+			}
+		}
+		for key := range desiredValues {
+			if _, found := liveValues[key]; !found {
+				desiredValues[key] = redactedValue
+			}
+		}
+	}
+
+	redacted := make([]string, 2)
+	for i, document := range []map[string]interface{}{liveSecret, desiredSecret} {
+		if len(document) == 0 {
+			continue
+		}
+		data, err := yaml.Marshal(document)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to render secret: %v", err)
+		}
+		redacted[i] = string(data)
+	}
+	return redacted[0], redacted[1], nil
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("manifest diff", func() {
+	const (
+		service = `---
+# Source: slurm/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: slurmctld
+spec:
+  ports:
+  - port: 6817
+`
+		configMap = `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: slurm-conf
+data:
+  slurm.conf: |
+    ClusterName=lab
+`
+		statefulSet = `---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: slurmd
+spec:
+  replicas: 2
+`
+		empty = `---
+# Source: slurm/templates/disabled.yaml
+`
+	)
+
+	DescribeTable("DiffManifests",
+		func(live, desired string, added, removed, changed []string) {
+			diff, err := DiffManifests(live, desired)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diff.Added).To(Equal(added))
+			Expect(diff.Removed).To(Equal(removed))
+			Expect(diff.Changed).To(Equal(changed))
+			Expect(diff.Empty()).To(Equal(added == nil && removed == nil && changed == nil))
+		},
+		Entry("same resources in another order", service+configMap+empty, configMap+service, nil, nil, nil),
+		Entry("added and removed resources", service+configMap, service+statefulSet,
+			[]string{"StatefulSet/slurmd"}, []string{"ConfigMap/slurm-conf"}, nil),
+		Entry("changed resource", configMap+statefulSet,
+			configMap+`---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: slurmd
+spec:
+  replicas: 3
+`, nil, nil, []string{"StatefulSet/slurmd"}),
+	)
+
+	It("should render a unified diff of the changed resources", func() {
+		diff, err := DiffManifests(configMap, `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: slurm-conf
+data:
+  slurm.conf: |
+    ClusterName=lab2
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff.Summary()).To(Equal("0 added, 0 removed, 1 changed"))
+		Expect(diff.Unified).To(ContainSubstring("--- live/ConfigMap/slurm-conf"))
+		Expect(diff.Unified).To(ContainSubstring("+++ desired/ConfigMap/slurm-conf"))
+		Expect(diff.Unified).To(ContainSubstring("-    ClusterName=lab\n"))
+		Expect(diff.Unified).To(ContainSubstring("+    ClusterName=lab2\n"))
+	})
+
+	It("should redact the values of secrets on both sides", func() {
+		secret := func(password, key string) string {
+			return `---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: mariadb
+data:
+  mariadb-password: ` + password + `
+stringData:
+  munge.key: ` + key + `
+`
+		}
+		diff, err := DiffManifests(configMap+secret("bGl2ZQ==", "live-key"), configMap+secret("ZGVzaXJlZA==", "live-key"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff.Changed).To(Equal([]string{"Secret/mariadb"}))
+		Expect(diff.Unified).NotTo(ContainSubstring("bGl2ZQ=="))
+		Expect(diff.Unified).NotTo(ContainSubstring("ZGVzaXJlZA=="))
+		Expect(diff.Unified).NotTo(ContainSubstring("live-key"))
+		Expect(diff.Unified).To(ContainSubstring("-  mariadb-password: '*** (before)'\n"))
+		Expect(diff.Unified).To(ContainSubstring("+  mariadb-password: '*** (after)'\n"))
+
+		diff, err = DiffManifests(configMap+secret("bGl2ZQ==", "live-key"), configMap)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff.Removed).To(Equal([]string{"Secret/mariadb"}))
+		Expect(diff.Unified).NotTo(ContainSubstring("bGl2ZQ=="))
+		Expect(diff.Unified).NotTo(ContainSubstring("live-key"))
+
+		diff, err = DiffManifests(configMap, configMap+secret("ZGVzaXJlZA==", "desired-key"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff.Added).To(Equal([]string{"Secret/mariadb"}))
+		Expect(diff.Unified).NotTo(ContainSubstring("ZGVzaXJlZA=="))
+		Expect(diff.Unified).NotTo(ContainSubstring("desired-key"))
+	})
+
+	It("should fail on a document that is no YAML", func() {
+		_, err := DiffManifests("---\nkind: [", "")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// PodExecutor runs a command inside a container of a running pod and returns its stdout
+type PodExecutor interface {
+	Exec(ctx context.Context, namespace, podName, container string, command []string) (string, error)
+}
+
+type remotePodExecutor struct {
+	config    *rest.Config
+	clientset kubernetes.Interface
+}
+
+// NewPodExecutor creates a PodExecutor backed by the pods/exec subresource of the API server
+func NewPodExecutor(config *rest.Config) (PodExecutor, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %v", err)
+	}
+	return &remotePodExecutor{config: config, clientset: clientset}, nil
+}
+
+func (e *remotePodExecutor) Exec(ctx context.Context, namespace, podName, container string, command []string) (string, error) {
+	req := e.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(e.config, "POST", req.URL())
+	if err != nil {
+		return "", fmt.Errorf("failed to create executor for pod %s/%s: %v", namespace, podName, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if streamErr := executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}); streamErr != nil {
+		log.Printf("Command [%s] failed in pod %s/%s: %v, stderr: %s", strings.Join(command, " "), namespace, podName, streamErr, stderr.String())
+		return stdout.String(), fmt.Errorf("%v: %s", streamErr, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,327 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+// AssociationFields are the sacctmgr format fields read back when checking an association for drift
+var AssociationFields = []string{"Account", "User", "ParentName", "Share", "MaxJobs", "MaxSubmit", "MaxWall", "MaxTRES", "GrpTRES", "DefaultQOS", "QOS"}
+
+// associationSetKeys maps a sacctmgr format field to the key accepted by `sacctmgr modify ... set`
+var associationSetKeys = map[string]string{
+	"Descr":      "Description",
+	"Org":        "Organization",
+	"ParentName": "Parent",
+	"Share":      "Fairshare",
+	"MaxJobs":    "MaxJobs",
+	"MaxSubmit":  "MaxSubmitJobs",
+	"MaxWall":    "MaxWall",
+	"MaxTRES":    "MaxTRES",
+	"GrpTRES":    "GrpTRES",
+	"DefaultQOS": "DefaultQOS",
+	"QOS":        "QOS",
+}
+
+// QOSFields are the sacctmgr format fields read back when checking a QoS for drift
+var QOSFields = []string{"Name", "Descr", "Priority", "PreemptMode", "Preempt", "MaxTRESPU", "MaxWall", "GrpTRES", "UsageFactor", "Flags"}
+
+// qosSetKeys maps a `sacctmgr show qos` format field to the key accepted by `sacctmgr modify qos ... set`
+var qosSetKeys = map[string]string{
+	"Descr":       "Description",
+	"Priority":    "Priority",
+	"PreemptMode": "PreemptMode",
+	"Preempt":     "Preempt",
+	"MaxTRESPU":   "MaxTRESPerUser",
+	"MaxWall":     "MaxWall",
+	"GrpTRES":     "GrpTRES",
+	"UsageFactor": "UsageFactor",
+	"Flags":       "Flags",
+}
+
+// RunSacctmgr runs sacctmgr non-interactively inside the slurmctld pod of a SlurmDeployment
+func RunSacctmgr(ctx context.Context, executor PodExecutor, release *slurmv1.SlurmDeployment, args ...string) (string, error) {
+	command := append([]string{"sacctmgr", "--immediate", "--noheader", "--parsable2"}, args...)
+	return RunSlurmctldCommand(ctx, executor, release, command...)
+}
+
+// SacctmgrCluster scopes an association to the cluster of a release, a slurmdbd may serve several clusters and
+// sacctmgr applies to all of them without it. It is empty until the release got its ClusterName.
+func SacctmgrCluster(release *slurmv1.SlurmDeployment) string {
+	if release.Status.ClusterName == "" {
+		return ""
+	}
+	return "cluster=" + release.Status.ClusterName
+}
+
+// QuerySacctmgr runs `sacctmgr show <entity> <where...> format=<fields>` and returns one map per row keyed by field
+func QuerySacctmgr(ctx context.Context, executor PodExecutor, release *slurmv1.SlurmDeployment, entity string, where []string, fields []string) ([]map[string]string, error) {
+	args := append([]string{"show", entity}, where...)
+	args = append(args, "format="+strings.Join(fields, ","))
+	output, err := RunSacctmgr(ctx, executor, release, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSacctmgrRows(output, fields), nil
+}
+
+// ParseSacctmgrRows parses parsable2 output into rows keyed by the requested fields
+func ParseSacctmgrRows(output string, fields []string) []map[string]string {
+	rows := []map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		columns := strings.Split(line, "|")
+		row := map[string]string{}
+		for i, field := range fields {
+			if i < len(columns) {
+				row[field] = strings.TrimSpace(columns[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// DesiredAssociation converts the association settings of a spec into sacctmgr format fields.
+// Only fields that are set in the spec are returned, unset fields are left to slurm.
+func DesiredAssociation(parent string, fairshare int32, limits slurmv1.SlurmAssociationLimitsSpec, defaultQOS string, qos []string) map[string]string {
+	desired := map[string]string{}
+	if parent != "" {
+		desired["ParentName"] = parent
+	}
+	if fairshare > 0 {
+		desired["Share"] = fmt.Sprintf("%d", fairshare)
+	}
+	if limits.MaxJobs != nil {
+		desired["MaxJobs"] = fmt.Sprintf("%d", *limits.MaxJobs)
+	}
+	if limits.MaxSubmitJobs != nil {
+		desired["MaxSubmit"] = fmt.Sprintf("%d", *limits.MaxSubmitJobs)
+	}
+	if limits.MaxWall != "" {
+		desired["MaxWall"] = limits.MaxWall
+	}
+	if limits.MaxTRES != "" {
+		desired["MaxTRES"] = limits.MaxTRES
+	}
+	if limits.GrpTRES != "" {
+		desired["GrpTRES"] = limits.GrpTRES
+	}
+	if defaultQOS != "" {
+		desired["DefaultQOS"] = defaultQOS
+	}
+	if len(qos) > 0 {
+		desired["QOS"] = normalizeList(strings.Join(qos, ","))
+	}
+	return desired
+}
+
+// AssociationSetArgs renders desired fields as `key=value` arguments for `sacctmgr add` or `sacctmgr modify ... set`
+func AssociationSetArgs(desired map[string]string) []string {
+	return sacctmgrSetArgs(desired, associationSetKeys)
+}
+
+// DesiredQOS converts a SlurmQOS spec into sacctmgr format fields, leaving unset fields to slurm
+func DesiredQOS(spec *slurmv1.SlurmQOSSpec) map[string]string {
+	desired := map[string]string{}
+	if spec.Description != "" {
+		desired["Descr"] = spec.Description
+	}
+	if spec.Priority != nil {
+		desired["Priority"] = fmt.Sprintf("%d", *spec.Priority)
+	}
+	if spec.PreemptMode != "" {
+		desired["PreemptMode"] = spec.PreemptMode
+	}
+	if len(spec.Preempt) > 0 {
+		desired["Preempt"] = normalizeList(strings.Join(spec.Preempt, ","))
+	}
+	if spec.MaxTRESPerUser != "" {
+		desired["MaxTRESPU"] = spec.MaxTRESPerUser
+	}
+	if spec.MaxWall != "" {
+		desired["MaxWall"] = spec.MaxWall
+	}
+	if spec.GrpTRES != "" {
+		desired["GrpTRES"] = spec.GrpTRES
+	}
+	if spec.UsageFactor != "" {
+		desired["UsageFactor"] = spec.UsageFactor
+	}
+	if len(spec.Flags) > 0 {
+		desired["Flags"] = normalizeList(strings.Join(spec.Flags, ","))
+	}
+	return desired
+}
+
+// QOSSetArgs renders desired fields as `key=value` arguments for `sacctmgr add qos` or `sacctmgr modify qos ... set`
+func QOSSetArgs(desired map[string]string) []string {
+	return sacctmgrSetArgs(desired, qosSetKeys)
+}
+
+// DiffSacctmgrFields compares desired fields against a row read from sacctmgr and describes every difference
+func DiffSacctmgrFields(desired map[string]string, observed map[string]string) []string {
+	drift := []string{}
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !sacctmgrValuesEqual(key, desired[key], observed[key]) {
+			drift = append(drift, fmt.Sprintf("%s: want %q, got %q", key, desired[key], observed[key]))
+		}
+	}
+	return drift
+}
+
+func sacctmgrValuesEqual(key, desired, actual string) bool {
+	switch key {
+	case "QOS", "Preempt", "Flags":
+		actual = normalizeList(actual)
+	case "UsageFactor":
+		desiredFactor, desiredErr := strconv.ParseFloat(desired, 64)
+		actualFactor, actualErr := strconv.ParseFloat(actual, 64)
+		if desiredErr == nil && actualErr == nil {
+			return desiredFactor == actualFactor
+		}
+	case "MaxWall":
+		desiredMinutes, desiredOK := wallMinutes(desired)
+		actualMinutes, actualOK := wallMinutes(actual)
+		if desiredOK && actualOK {
+			return desiredMinutes == actualMinutes
+		}
+	case "MaxTRES", "MaxTRESPU", "GrpTRES":
+		desiredTRES, desiredOK := tresQuantities(desired)
+		actualTRES, actualOK := tresQuantities(actual)
+		if desiredOK && actualOK {
+			return reflect.DeepEqual(desiredTRES, actualTRES)
+		}
+	}
+	return strings.EqualFold(desired, actual)
+}
+
+// wallMinutes parses a slurm time limit, e.g. "2880", "48:00:00" or "2-00:00:00", into minutes. Seconds are rounded
+// up to a minute like slurm does.
+func wallMinutes(value string) (int64, bool) {
+	value = strings.TrimSpace(value)
+	days, hasDays := int64(0), false
+	if dayPart, rest, found := strings.Cut(value, "-"); found {
+		parsed, err := strconv.ParseInt(dayPart, 10, 64)
+		if err != nil || parsed < 0 {
+			return 0, false
+		}
+		days, hasDays, value = parsed, true, rest
+	}
+	parts := strings.Split(value, ":")
+	numbers := make([]int64, len(parts))
+	for i, part := range parts {
+		number, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || number < 0 {
+			return 0, false
+		}
+		numbers[i] = number
+	}
+	var hours, minutes, seconds int64
+	switch {
+	case len(numbers) == 3:
+		hours, minutes, seconds = numbers[0], numbers[1], numbers[2]
+	case hasDays && len(numbers) == 2:
+		hours, minutes = numbers[0], numbers[1]
+	case hasDays && len(numbers) == 1:
+		hours = numbers[0]
+	case len(numbers) == 2:
+		minutes, seconds = numbers[0], numbers[1]
+	case len(numbers) == 1:
+		minutes = numbers[0]
+	default:
+		return 0, false
+	}
+	total := (days*24+hours)*60 + minutes
+	if seconds > 0 {
+		total += (seconds + 59) / 60
+	}
+	return total, true
+}
+
+// tresUnits are the suffixes slurm scales TRES counts with, each one 1024 times the previous one
+const tresUnits = "KMGTP"
+
+// tresQuantities parses a TRES list, e.g. "cpu=8,mem=16G,gres/gpu=2", into quantities keyed by the lower cased TRES
+// name. Memory without a suffix is in megabytes, so "mem=16G" and "mem=16384M" are the same quantity.
+func tresQuantities(value string) (map[string]float64, bool) {
+	quantities := map[string]float64{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		name, amount, found := strings.Cut(item, "=")
+		if !found {
+			return nil, false
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		amount = strings.TrimSpace(amount)
+		scale := 1.0
+		if name == "mem" {
+			scale = 1024 * 1024
+		}
+		if amount != "" {
+			if unit := strings.IndexByte(tresUnits, strings.ToUpper(amount)[len(amount)-1]); unit >= 0 {
+				scale = math.Pow(1024, float64(unit+1))
+				amount = amount[:len(amount)-1]
+			}
+		}
+		number, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			return nil, false
+		}
+		quantities[name] = number * scale
+	}
+	return quantities, true
+}
+
+func sacctmgrSetArgs(desired map[string]string, setKeys map[string]string) []string {
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	args := make([]string, 0, len(keys))
+	for _, key := range keys {
+		args = append(args, fmt.Sprintf("%s=%s", setKeys[key], desired[key]))
+	}
+	return args
+}
+
+// IsSacctmgrNothingChanged tells whether a sacctmgr error only means there was nothing to add, modify or delete
+func IsSacctmgrNothingChanged(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "nothing deleted") ||
+		strings.Contains(message, "nothing modified") ||
+		strings.Contains(message, "nothing new added") ||
+		strings.Contains(message, "already exists")
+}
+
+func normalizeList(value string) string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+var _ = Describe("sacctmgr", func() {
+	maxJobs, maxSubmit := int32(10), int32(0)
+
+	DescribeTable("DesiredAssociation",
+		func(parent string, fairshare int32, limits slurmv1.SlurmAssociationLimitsSpec, defaultQOS string, qos []string,
+			expected map[string]string) {
+			Expect(DesiredAssociation(parent, fairshare, limits, defaultQOS, qos)).To(Equal(expected))
+		},
+		Entry("nothing set", "", int32(0), slurmv1.SlurmAssociationLimitsSpec{}, "", nil, map[string]string{}),
+		Entry("every field", "root", int32(5), slurmv1.SlurmAssociationLimitsSpec{
+			MaxJobs: &maxJobs, MaxSubmitJobs: &maxSubmit, MaxWall: "1-00:00:00", MaxTRES: "cpu=8", GrpTRES: "cpu=64",
+		}, "normal", []string{"normal", " high", "debug"}, map[string]string{
+			"ParentName": "root",
+			"Share":      "5",
+			"MaxJobs":    "10",
+			"MaxSubmit":  "0",
+			"MaxWall":    "1-00:00:00",
+			"MaxTRES":    "cpu=8",
+			"GrpTRES":    "cpu=64",
+			"DefaultQOS": "normal",
+			"QOS":        "debug,high,normal",
+		}),
+	)
+
+	It("should render the association and QoS fields as sacctmgr set keys", func() {
+		Expect(AssociationSetArgs(map[string]string{"Share": "5", "ParentName": "root", "QOS": "high,normal"})).To(
+			Equal([]string{"Parent=root", "QOS=high,normal", "Fairshare=5"}))
+		Expect(QOSSetArgs(map[string]string{"MaxTRESPU": "cpu=8", "Descr": "high"})).To(
+			Equal([]string{"Description=high", "MaxTRESPerUser=cpu=8"}))
+	})
+
+	DescribeTable("DiffSacctmgrFields",
+		func(desired, observed map[string]string, expected []string) {
+			Expect(DiffSacctmgrFields(desired, observed)).To(Equal(expected))
+		},
+		Entry("in sync", map[string]string{"Share": "5", "MaxWall": "1-00:00:00"},
+			map[string]string{"Share": "5", "MaxWall": "1-00:00:00", "GrpTRES": "cpu=64"}, []string{}),
+		Entry("case of the values", map[string]string{"PreemptMode": "requeue", "DefaultQOS": "Normal"},
+			map[string]string{"PreemptMode": "REQUEUE", "DefaultQOS": "normal"}, []string{}),
+		Entry("order of the lists", map[string]string{"QOS": "high,normal", "Flags": "DenyOnLimit,NoDecay"},
+			map[string]string{"QOS": "normal, high", "Flags": "NoDecay,DenyOnLimit"}, []string{}),
+		Entry("usage factor precision", map[string]string{"UsageFactor": "2"},
+			map[string]string{"UsageFactor": "2.000000"}, []string{}),
+		Entry("wall time formats", map[string]string{"MaxWall": "48:00:00"},
+			map[string]string{"MaxWall": "2-00:00:00"}, []string{}),
+		Entry("wall time in minutes", map[string]string{"MaxWall": "2880"},
+			map[string]string{"MaxWall": "2-00:00:00"}, []string{}),
+		Entry("wall time seconds rounded up", map[string]string{"MaxWall": "90:30"},
+			map[string]string{"MaxWall": "01:31:00"}, []string{}),
+		Entry("drifted wall time", map[string]string{"MaxWall": "1-12"},
+			map[string]string{"MaxWall": "2-00:00:00"}, []string{`MaxWall: want "1-12", got "2-00:00:00"`}),
+		Entry("order and memory units of TRES", map[string]string{"MaxTRES": "mem=16G,cpu=8", "GrpTRES": "gres/gpu=2,mem=1T"},
+			map[string]string{"MaxTRES": "cpu=8,mem=16384M", "GrpTRES": "mem=1048576,gres/gpu=2"}, []string{}),
+		Entry("QoS limits", map[string]string{"MaxWall": "48:00:00", "MaxTRESPU": "mem=16G,cpu=8", "GrpTRES": "gres/gpu=8"},
+			map[string]string{"MaxWall": "2-00:00:00", "MaxTRESPU": "cpu=8,mem=16384M", "GrpTRES": "gres/gpu=8"}, []string{}),
+		Entry("drifted TRES", map[string]string{"MaxTRES": "cpu=8,mem=16G", "GrpTRES": "cpu=64"},
+			map[string]string{"MaxTRES": "cpu=8,mem=8G", "GrpTRES": "cpu=64,gres/gpu=4"}, []string{
+				`GrpTRES: want "cpu=64", got "cpu=64,gres/gpu=4"`,
+				`MaxTRES: want "cpu=8,mem=16G", got "cpu=8,mem=8G"`,
+			}),
+		Entry("drifted and missing fields, sorted", map[string]string{"Share": "5", "MaxJobs": "10", "QOS": "high"},
+			map[string]string{"Share": "1", "QOS": "high,normal"}, []string{
+				`MaxJobs: want "10", got ""`,
+				`QOS: want "high", got "high,normal"`,
+				`Share: want "5", got "1"`,
+			}),
+	)
+
+	DescribeTable("ParseSacctmgrRows",
+		func(output string, fields []string, expected []map[string]string) {
+			Expect(ParseSacctmgrRows(output, fields)).To(Equal(expected))
+		},
+		Entry("no rows", "\n", []string{"Account"}, []map[string]string{}),
+		Entry("parsable2 rows", "physics||root|5\nphysics|alice|physics|1\n", []string{"Account", "User", "ParentName", "Share"},
+			[]map[string]string{
+				{"Account": "physics", "User": "", "ParentName": "root", "Share": "5"},
+				{"Account": "physics", "User": "alice", "ParentName": "physics", "Share": "1"},
+			}),
+		Entry("fewer columns than fields", "normal|10\n", []string{"Name", "Priority", "Flags"},
+			[]map[string]string{{"Name": "normal", "Priority": "10"}}),
+		Entry("surrounding whitespace", "  high | 100 \n", []string{"Name", "Priority"},
+			[]map[string]string{{"Name": "high", "Priority": "100"}}),
+	)
+
+	DescribeTable("IsSacctmgrNothingChanged",
+		func(err error, expected bool) {
+			Expect(IsSacctmgrNothingChanged(err)).To(Equal(expected))
+		},
+		Entry("no error", nil, false),
+		Entry("nothing deleted", errors.New(" Nothing deleted"), true),
+		Entry("nothing modified", errors.New("command terminated: Nothing modified"), true),
+		Entry("nothing new added", errors.New(" Nothing new added."), true),
+		Entry("already exists", errors.New("This account already exists"), true),
+		Entry("other failure", errors.New("sacctmgr: error: Problem talking to the database: Connection refused"), false),
+	)
+})
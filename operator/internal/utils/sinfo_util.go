@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+// RunSlurmctldCommand runs a slurm client command inside the slurmctld pod of a SlurmDeployment
+func RunSlurmctldCommand(ctx context.Context, executor PodExecutor, release *slurmv1.SlurmDeployment, command ...string) (string, error) {
+	if executor == nil {
+		return "", fmt.Errorf("no pod executor configured")
+	}
+	return executor.Exec(ctx, release.Spec.Chart.Namespace, SlurmctldPodName(release), SlurmctldContainerName, command)
+}
+
+// QuerySlurmNodeStates counts the slurm nodes of a SlurmDeployment by their base state
+func QuerySlurmNodeStates(ctx context.Context, executor PodExecutor, release *slurmv1.SlurmDeployment) (map[string]int, error) {
+	output, err := RunSlurmctldCommand(ctx, executor, release, "sinfo", "--noheader", "--Node", "--format=%N|%T")
+	if err != nil {
+		return nil, err
+	}
+	return ParseSinfoNodeStates(output), nil
+}
+
+// ParseSinfoNodeStates parses `sinfo -N -o %N|%T` output, counting every node once even if it
+// is listed in several partitions and dropping state suffixes such as `*` or `~`
+func ParseSinfoNodeStates(output string) map[string]int {
+	counts := map[string]int{}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) < 2 || seen[parts[0]] {
+			continue
+		}
+		seen[parts[0]] = true
+		state := strings.ToLower(strings.TrimRight(parts[1], "*~#!%$@^-"))
+		counts[state]++
+	}
+	return counts
+}
+
+// QuerySlurmPartitionStates returns the state of every partition of a SlurmDeployment, in the form scontrol
+// accepts it back, e.g. UP or DRAIN
+func QuerySlurmPartitionStates(ctx context.Context, executor PodExecutor, release *slurmv1.SlurmDeployment) (map[string]string, error) {
+	output, err := RunSlurmctldCommand(ctx, executor, release, "sinfo", "--noheader", "--format=%R|%a")
+	if err != nil {
+		return nil, err
+	}
+	return ParseSinfoPartitionStates(output), nil
+}
+
+// ParseSinfoPartitionStates parses `sinfo -o %R|%a` output, sinfo prints a partition once per group of node
+// states and abbreviates INACTIVE as inact
+func ParseSinfoPartitionStates(output string) map[string]string {
+	states := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) < 2 || parts[0] == "" {
+			continue
+		}
+		state := strings.ToUpper(strings.TrimSpace(parts[1]))
+		if state == "INACT" {
+			state = "INACTIVE"
+		}
+		states[parts[0]] = state
+	}
+	return states
+}
+
+// SetSlurmPartitionState changes the state of a partition, e.g. to UP, DOWN or DRAIN
+func SetSlurmPartitionState(ctx context.Context, executor PodExecutor, release *slurmv1.SlurmDeployment, partition, state string) error {
+	_, err := RunSlurmctldCommand(ctx, executor, release, "scontrol", "update", "PartitionName="+partition, "State="+state)
+	return err
+}
+
+// CountSlurmJobs counts the jobs of a SlurmDeployment in the given squeue states, e.g. "RUNNING,COMPLETING"
+func CountSlurmJobs(ctx context.Context, executor PodExecutor, release *slurmv1.SlurmDeployment, states string) (int32, error) {
+	output, err := RunSlurmctldCommand(ctx, executor, release, "squeue", "--noheader", "--states="+states, "--format=%i")
+	if err != nil {
+		return 0, err
+	}
+	count := int32(0)
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// QuerySlurmNodeFeatures returns the available features of every slurm node of a SlurmDeployment
+func QuerySlurmNodeFeatures(ctx context.Context, executor PodExecutor, release *slurmv1.SlurmDeployment) (map[string][]string, error) {
+	output, err := RunSlurmctldCommand(ctx, executor, release, "sinfo", "--noheader", "--Node", "--format=%N|%f")
+	if err != nil {
+		return nil, err
+	}
+	return ParseSinfoNodeFeatures(output), nil
+}
+
+// ParseSinfoNodeFeatures parses `sinfo -N -o %N|%f` output, a node without features reports "(null)"
+func ParseSinfoNodeFeatures(output string) map[string][]string {
+	features := map[string][]string{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) < 2 {
+			continue
+		}
+		if _, seen := features[parts[0]]; seen {
+			continue
+		}
+		features[parts[0]] = []string{}
+		if parts[1] == "(null)" || parts[1] == "" {
+			continue
+		}
+		features[parts[0]] = strings.Split(parts[1], ",")
+	}
+	return features
+}
+
+// SetSlurmNodeFeatures replaces the available and active features of a slurm node
+func SetSlurmNodeFeatures(ctx context.Context, executor PodExecutor, release *slurmv1.SlurmDeployment, node string, features []string) error {
+	joined := strings.Join(features, ",")
+	_, err := RunSlurmctldCommand(ctx, executor, release, "scontrol", "update", "NodeName="+node,
+		"AvailableFeatures="+joined, "ActiveFeatures="+joined)
+	return err
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("sinfo", func() {
+	DescribeTable("ParseSinfoNodeStates",
+		func(output string, expected map[string]int) {
+			Expect(ParseSinfoNodeStates(output)).To(Equal(expected))
+		},
+		Entry("no nodes", "", map[string]int{}),
+		Entry("states per node", "cpu-0|idle\ncpu-1|allocated\ncpu-2|idle\n", map[string]int{"idle": 2, "allocated": 1}),
+		Entry("node in several partitions", "cpu-0|mixed\ncpu-0|mixed\ngpu-0|idle\n", map[string]int{"mixed": 1, "idle": 1}),
+		Entry("state suffixes", "cpu-0|idle~\ncpu-1|down*\ncpu-2|IDLE#\ncpu-3|drained$\n",
+			map[string]int{"idle": 2, "down": 1, "drained": 1}),
+		Entry("malformed lines", "cpu-0\n\ncpu-1|idle\n", map[string]int{"idle": 1}),
+	)
+
+	DescribeTable("ParseSinfoPartitionStates",
+		func(output string, expected map[string]string) {
+			Expect(ParseSinfoPartitionStates(output)).To(Equal(expected))
+		},
+		Entry("partitions listed per node state", "compute|up\ncompute|up\ndebug|drain\nold|inact\n",
+			map[string]string{"compute": "UP", "debug": "DRAIN", "old": "INACTIVE"}),
+	)
+})
@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+const (
+	// SlurmctldStateMountPath is the StateSaveLocation set in slurm.conf
+	SlurmctldStateMountPath = "/var/spool/slurmctld"
+
+	defaultBackupDatabaseImage = "docker.io/bitnami/mariadb:11.4"
+	defaultBackupUploadImage   = "docker.io/minio/mc:latest"
+
+	// BackupIDFormat names every backup run after its start time, e.g. "20250102-030000"
+	BackupIDFormat = "20060102-150405"
+	// backupIDPattern matches the directories named with BackupIDFormat, retention only removes those so a target
+	// shared with other data keeps it
+	backupIDPattern = "^[0-9]{8}-[0-9]{6}$"
+
+	backupMountPath = "/backup"
+	stateMountPath  = "/state"
+)
+
+// BackupJobOptions describes a backup or restore job of one SlurmDeployment
+type BackupJobOptions struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	BackupID  string
+	// Directory is the sub path of the target PVC or the prefix inside the bucket
+	Directory string
+	Retention int32
+	Target    slurmv1.BackupTargetSpec
+	Images    slurmv1.BackupImagesSpec
+	// StateClaim is the PVC holding the slurmctld StateSaveLocation
+	StateClaim string
+	// NodeName pins the job next to slurmctld so a ReadWriteOnce state claim can be mounted
+	NodeName     string
+	DatabaseHost string
+	DatabasePort int32
+	DatabaseName string
+	// DatabaseSecret holds the MariaDB root password under DatabaseSecretKey
+	DatabaseSecret    string
+	DatabaseSecretKey string
+	// SkipDatabase leaves the accounting database out, for a release whose slurmdbd is run by another release or
+	// outside of the operator
+	SkipDatabase bool
+}
+
+const backupScript = `set -euo pipefail
+target="` + backupMountPath + `/${BACKUP_DIR}/${BACKUP_ID}"
+mkdir -p "${target}"
+tar -czf "${target}/slurmctld-state.tar.gz" -C ` + stateMountPath + ` .
+`
+
+const dumpScript = `mysqldump -h "${DB_HOST}" -P "${DB_PORT}" -u root --single-transaction --routines --databases "${DB_NAME}" | gzip > "${target}/accounting.sql.gz"
+`
+
+const pvcRetentionScript = `ls -1 "` + backupMountPath + `/${BACKUP_DIR}" | grep -E '` + backupIDPattern + `' | sort -r | tail -n +$((RETENTION + 1)) | while read -r old; do
+  rm -rf "` + backupMountPath + `/${BACKUP_DIR}/${old}"
+done
+`
+
+const uploadScript = `set -euo pipefail
+mc alias set target "${S3_ENDPOINT}" "${S3_ACCESS_KEY}" "${S3_SECRET_KEY}"
+mc cp --recursive "` + backupMountPath + `/${BACKUP_DIR}/${BACKUP_ID}/" "target/${S3_BUCKET}/${BACKUP_DIR}/${BACKUP_ID}/"
+mc ls "target/${S3_BUCKET}/${BACKUP_DIR}/" | while read -r line; do
+  name="${line##* }"
+  echo "${name%/}"
+done | grep -E '` + backupIDPattern + `' | sort -r | tail -n +$((RETENTION + 1)) | while read -r old; do
+  mc rm --recursive --force "target/${S3_BUCKET}/${BACKUP_DIR}/${old}"
+done
+`
+
+const downloadScript = `set -euo pipefail
+mc alias set target "${S3_ENDPOINT}" "${S3_ACCESS_KEY}" "${S3_SECRET_KEY}"
+mc cp --recursive "target/${S3_BUCKET}/${BACKUP_DIR}/${BACKUP_ID}/" "` + backupMountPath + `/${BACKUP_DIR}/${BACKUP_ID}/"
+`
+
+const restoreScript = `set -euo pipefail
+source="` + backupMountPath + `/${BACKUP_DIR}/${BACKUP_ID}"
+test -f "${source}/slurmctld-state.tar.gz"
+`
+
+const restoreCheckDumpScript = `test -f "${source}/accounting.sql.gz"
+`
+
+const restoreStateScript = `find ` + stateMountPath + ` -mindepth 1 -delete
+tar -xzf "${source}/slurmctld-state.tar.gz" -C ` + stateMountPath + `
+`
+
+const loadDumpScript = `gunzip -c "${source}/accounting.sql.gz" | mysql -h "${DB_HOST}" -P "${DB_PORT}" -u root
+`
+
+// BuildBackupJob returns a job that archives the slurmctld state and dumps the accounting database into the target
+func BuildBackupJob(options BackupJobOptions) *batchv1.Job {
+	script := backupScript
+	if !options.SkipDatabase {
+		script += dumpScript
+	}
+	dump := options.databaseContainer("dump", script, true)
+	if options.Target.S3 == nil {
+		dump.Args[0] += pvcRetentionScript
+		return options.job(nil, []corev1.Container{dump})
+	}
+	upload := options.s3Container("upload", uploadScript)
+	return options.job([]corev1.Container{dump}, []corev1.Container{upload})
+}
+
+// BuildRestoreJob returns a job that writes a backup back into the slurmctld state claim and the accounting database
+func BuildRestoreJob(options BackupJobOptions) *batchv1.Job {
+	script := restoreScript + restoreStateScript
+	if !options.SkipDatabase {
+		script = restoreScript + restoreCheckDumpScript + restoreStateScript + loadDumpScript
+	}
+	restore := options.databaseContainer("restore", script, false)
+	if options.Target.S3 == nil {
+		return options.job(nil, []corev1.Container{restore})
+	}
+	download := options.s3Container("download", downloadScript)
+	return options.job([]corev1.Container{download}, []corev1.Container{restore})
+}
+
+func (o BackupJobOptions) job(initContainers, containers []corev1.Container) *batchv1.Job {
+	backoffLimit := int32(1)
+	rootUser := int64(0)
+	workVolume := corev1.Volume{Name: "backup", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}
+	if o.Target.PVC != nil {
+		workVolume.VolumeSource = corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: o.Target.PVC.ClaimName,
+		}}
+	}
+	podSpec := corev1.PodSpec{
+		RestartPolicy:   corev1.RestartPolicyNever,
+		SecurityContext: &corev1.PodSecurityContext{RunAsUser: &rootUser},
+		InitContainers:  initContainers,
+		Containers:      containers,
+		Volumes: []corev1.Volume{
+			workVolume,
+			{Name: "slurmctld-state", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: o.StateClaim,
+			}}},
+		},
+	}
+	if o.NodeName != "" {
+		podSpec.NodeSelector = map[string]string{corev1.LabelHostname: o.NodeName}
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: o.Name, Namespace: o.Namespace, Labels: o.Labels},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: o.Labels},
+				Spec:       podSpec,
+			},
+		},
+	}
+}
+
+func (o BackupJobOptions) databaseContainer(name, script string, readOnlyState bool) corev1.Container {
+	image := o.Images.Database
+	if image == "" {
+		image = defaultBackupDatabaseImage
+	}
+	env := o.commonEnv()
+	if !o.SkipDatabase {
+		env = append(env,
+			corev1.EnvVar{Name: "DB_HOST", Value: o.DatabaseHost},
+			corev1.EnvVar{Name: "DB_PORT", Value: fmt.Sprintf("%d", o.DatabasePort)},
+			corev1.EnvVar{Name: "DB_NAME", Value: o.DatabaseName},
+			// read by mysql and mysqldump, unlike -p it does not show up in the process list
+			corev1.EnvVar{Name: "MYSQL_PWD", ValueFrom: secretKeyRef(o.DatabaseSecret, o.DatabaseSecretKey)},
+		)
+	}
+	return corev1.Container{
+		Name:    name,
+		Image:   image,
+		Command: []string{"/bin/bash", "-c"},
+		Args:    []string{script},
+		Env:     env,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "backup", MountPath: backupMountPath},
+			{Name: "slurmctld-state", MountPath: stateMountPath, ReadOnly: readOnlyState},
+		},
+	}
+}
+
+func (o BackupJobOptions) s3Container(name, script string) corev1.Container {
+	image := o.Images.Upload
+	if image == "" {
+		image = defaultBackupUploadImage
+	}
+	return corev1.Container{
+		Name:    name,
+		Image:   image,
+		Command: []string{"/bin/bash", "-c"},
+		Args:    []string{script},
+		Env: append(o.commonEnv(),
+			corev1.EnvVar{Name: "MC_CONFIG_DIR", Value: "/tmp/.mc"},
+			corev1.EnvVar{Name: "S3_ENDPOINT", Value: o.Target.S3.Endpoint},
+			corev1.EnvVar{Name: "S3_BUCKET", Value: o.Target.S3.Bucket},
+			corev1.EnvVar{Name: "S3_ACCESS_KEY", ValueFrom: secretKeyRef(o.Target.S3.CredentialsSecret, "accessKey")},
+			corev1.EnvVar{Name: "S3_SECRET_KEY", ValueFrom: secretKeyRef(o.Target.S3.CredentialsSecret, "secretKey")},
+		),
+		VolumeMounts: []corev1.VolumeMount{{Name: "backup", MountPath: backupMountPath}},
+	}
+}
+
+func (o BackupJobOptions) commonEnv() []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "BACKUP_DIR", Value: o.Directory},
+		{Name: "BACKUP_ID", Value: o.BackupID},
+		{Name: "RETENTION", Value: fmt.Sprintf("%d", o.Retention)},
+	}
+}
+
+// BackupDirectory returns the sub path or bucket prefix the backups of a SlurmBackup are stored under
+func BackupDirectory(backup *slurmv1.SlurmBackup) string {
+	if backup.Spec.Target.PVC != nil && backup.Spec.Target.PVC.SubPath != "" {
+		return path.Clean(backup.Spec.Target.PVC.SubPath)
+	}
+	if backup.Spec.Target.S3 != nil && backup.Spec.Target.S3.Prefix != "" {
+		return path.Clean(backup.Spec.Target.S3.Prefix)
+	}
+	return backup.Name
+}
+
+func secretKeyRef(name, key string) *corev1.EnvVarSource {
+	return &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: name},
+		Key:                  key,
+	}}
+}
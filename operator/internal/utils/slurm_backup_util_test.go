@@ -0,0 +1,129 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+var _ = Describe("Backup jobs", func() {
+	var options BackupJobOptions
+
+	envOf := func(container corev1.Container) map[string]string {
+		env := map[string]string{}
+		for _, variable := range container.Env {
+			env[variable.Name] = variable.Value
+			if variable.ValueFrom != nil && variable.ValueFrom.SecretKeyRef != nil {
+				env[variable.Name] = variable.ValueFrom.SecretKeyRef.Name + "/" + variable.ValueFrom.SecretKeyRef.Key
+			}
+		}
+		return env
+	}
+
+	BeforeEach(func() {
+		options = BackupJobOptions{
+			Name:              "nightly-backup-20250102-030000",
+			Namespace:         "slurm",
+			BackupID:          "20250102-030000",
+			Directory:         "nightly",
+			Retention:         7,
+			Target:            slurmv1.BackupTargetSpec{PVC: &slurmv1.BackupPVCTargetSpec{ClaimName: "backups"}},
+			StateClaim:        "state-lab-slurm-slurmctld-0",
+			NodeName:          "node-a",
+			DatabaseHost:      "lab-mariadb",
+			DatabasePort:      3306,
+			DatabaseName:      "slurm_acct_db",
+			DatabaseSecret:    "lab-mariadb",
+			DatabaseSecretKey: "mariadb-root-password",
+		}
+	})
+
+	It("dumps into the PVC and prunes only backup runs there", func() {
+		job := BuildBackupJob(options)
+		spec := job.Spec.Template.Spec
+		Expect(spec.InitContainers).To(BeEmpty())
+		Expect(spec.Containers).To(HaveLen(1))
+		Expect(spec.NodeSelector).To(HaveKeyWithValue(corev1.LabelHostname, "node-a"))
+		Expect(spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("backups"))
+		Expect(spec.Volumes[1].PersistentVolumeClaim.ClaimName).To(Equal("state-lab-slurm-slurmctld-0"))
+
+		dump := spec.Containers[0]
+		Expect(dump.Name).To(Equal("dump"))
+		Expect(dump.Image).To(Equal(defaultBackupDatabaseImage))
+		Expect(dump.VolumeMounts[1].ReadOnly).To(BeTrue())
+		Expect(envOf(dump)).To(Equal(map[string]string{
+			"BACKUP_DIR": "nightly",
+			"BACKUP_ID":  "20250102-030000",
+			"RETENTION":  "7",
+			"DB_HOST":    "lab-mariadb",
+			"DB_PORT":    "3306",
+			"DB_NAME":    "slurm_acct_db",
+			"MYSQL_PWD":  "lab-mariadb/mariadb-root-password",
+		}))
+		Expect(dump.Args[0]).To(ContainSubstring("mysqldump"))
+		Expect(dump.Args[0]).NotTo(ContainSubstring("DB_PASSWORD"))
+		Expect(dump.Args[0]).To(ContainSubstring("grep -E '" + backupIDPattern + "'"))
+	})
+
+	It("dumps into a scratch volume and uploads to S3", func() {
+		options.Target = slurmv1.BackupTargetSpec{S3: &slurmv1.BackupS3TargetSpec{
+			Endpoint: "http://minio.minio.svc:9000", Bucket: "slurm", CredentialsSecret: "minio",
+		}}
+		options.Images.Upload = "registry.example.com/mc:1"
+		job := BuildBackupJob(options)
+		spec := job.Spec.Template.Spec
+		Expect(spec.Volumes[0].EmptyDir).NotTo(BeNil())
+		Expect(spec.InitContainers).To(HaveLen(1))
+		Expect(spec.InitContainers[0].Name).To(Equal("dump"))
+		Expect(spec.InitContainers[0].Args[0]).NotTo(ContainSubstring("rm -rf"), "the scratch volume needs no retention")
+
+		upload := spec.Containers[0]
+		Expect(upload.Name).To(Equal("upload"))
+		Expect(upload.Image).To(Equal("registry.example.com/mc:1"))
+		Expect(upload.VolumeMounts).To(HaveLen(1))
+		Expect(envOf(upload)).To(SatisfyAll(
+			HaveKeyWithValue("S3_ENDPOINT", "http://minio.minio.svc:9000"),
+			HaveKeyWithValue("S3_BUCKET", "slurm"),
+			HaveKeyWithValue("S3_ACCESS_KEY", "minio/accessKey"),
+			HaveKeyWithValue("S3_SECRET_KEY", "minio/secretKey"),
+			HaveKeyWithValue("BACKUP_DIR", "nightly"),
+			Not(HaveKey("MYSQL_PWD")),
+		))
+		Expect(upload.Args[0]).To(ContainSubstring("grep -E '" + backupIDPattern + "'"))
+	})
+
+	It("restores the state and the database from the PVC", func() {
+		job := BuildRestoreJob(options)
+		spec := job.Spec.Template.Spec
+		Expect(spec.InitContainers).To(BeEmpty())
+		restore := spec.Containers[0]
+		Expect(restore.Name).To(Equal("restore"))
+		Expect(restore.VolumeMounts[1].ReadOnly).To(BeFalse())
+		Expect(envOf(restore)).To(HaveKeyWithValue("MYSQL_PWD", "lab-mariadb/mariadb-root-password"))
+		Expect(restore.Args[0]).To(ContainSubstring("tar -xzf"))
+		Expect(restore.Args[0]).To(ContainSubstring("| mysql"))
+	})
+
+	It("downloads from S3 before restoring", func() {
+		options.Target = slurmv1.BackupTargetSpec{S3: &slurmv1.BackupS3TargetSpec{
+			Endpoint: "http://minio.minio.svc:9000", Bucket: "slurm", CredentialsSecret: "minio",
+		}}
+		job := BuildRestoreJob(options)
+		spec := job.Spec.Template.Spec
+		Expect(spec.InitContainers).To(HaveLen(1))
+		Expect(spec.InitContainers[0].Name).To(Equal("download"))
+		Expect(spec.InitContainers[0].Image).To(Equal(defaultBackupUploadImage))
+		Expect(spec.Containers[0].Name).To(Equal("restore"))
+	})
+
+	It("leaves the database alone when it is skipped", func() {
+		options.SkipDatabase = true
+		restore := BuildRestoreJob(options).Spec.Template.Spec.Containers[0]
+		Expect(restore.Args[0]).NotTo(ContainSubstring("mysql"))
+		Expect(envOf(restore)).NotTo(HaveKey("MYSQL_PWD"))
+		dump := BuildBackupJob(options).Spec.Template.Spec.Containers[0]
+		Expect(dump.Args[0]).NotTo(ContainSubstring("mysqldump"))
+	})
+})
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"fmt"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+const (
+	// SlurmExporterComponent is the component name used for the exporter workload and its labels
+	SlurmExporterComponent = "exporter"
+
+	defaultExporterRegistry   = "ghcr.io"
+	defaultExporterRepository = "rivosinc/prometheus-slurm-exporter"
+	defaultExporterTag        = "v1.6.3" // pinned so that a rescheduled exporter never pulls another release
+	defaultExporterPort       = 9092
+	defaultScrapeInterval     = "30s"
+)
+
+// DefaultSlurmExporterSpec fills the unset image, port and scrape interval of an exporter spec
+func DefaultSlurmExporterSpec(exporter *slurmv1.SlurmExporterSpec) {
+	if exporter.Image.Repository == "" {
+		exporter.Image.Registry = defaultExporterRegistry
+		exporter.Image.Repository = defaultExporterRepository
+	}
+	if exporter.Image.Tag == "" {
+		exporter.Image.Tag = defaultExporterTag
+	}
+	if exporter.Image.PullPolicy == "" {
+		exporter.Image.PullPolicy = "IfNotPresent"
+	}
+	if exporter.Port == 0 {
+		exporter.Port = defaultExporterPort
+	}
+	if exporter.ScrapeInterval == "" {
+		exporter.ScrapeInterval = defaultScrapeInterval
+	}
+}
+
+// ImageReference joins registry, repository and tag of an image spec
+func ImageReference(image slurmv1.ImageSpec) string {
+	if image.Registry == "" {
+		return fmt.Sprintf("%s:%s", image.Repository, image.Tag)
+	}
+	return fmt.Sprintf("%s/%s:%s", image.Registry, image.Repository, image.Tag)
+}
+
+// BuildExporterSlurmConf renders the client side slurm.conf used by the exporter. It only points the
+// slurm commands at slurmctld and slurmdbd through their headless services, so it does not depend on
+// the configmap rendered by the chart and keeps working across slurmctld restarts.
+func BuildExporterSlurmConf(release *slurmv1.SlurmDeployment) string {
+	slurmctld := ComponentName(release, "slurmctld")
+	accounting := release.Spec.Values.SlurmConfig.Accounting
+	slurmdbdHost := fmt.Sprintf("%s-0.%s-headless", ComponentName(release, "slurmdbd"), ComponentName(release, "slurmdbd"))
+	slurmdbdPort := int32(6819)
+	if accounting.StorageHost != "" {
+		slurmdbdHost = accounting.StorageHost
+		if accounting.StoragePort != 0 {
+			slurmdbdPort = accounting.StoragePort
+		}
+	}
+	return fmt.Sprintf(`ClusterName=%s
+SlurmctldHost=%s-0(%s-0.%s-headless)
+SlurmctldPort=6817
+AuthType=auth/munge
+AccountingStorageType=accounting_storage/slurmdbd
+AccountingStorageHost=%s
+AccountingStoragePort=%d
+`, SlurmClusterName(&release.Spec.Values.SlurmConfig), slurmctld, slurmctld, slurmctld, slurmdbdHost, slurmdbdPort)
+}
@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+const (
+	hugePagesResourcePrefix = "hugepages-"
+
+	// gres.conf is rendered into a ConfigMap of the helm release and mounted next to slurm.conf in slurmd
+	gresConfKey        = "gres.conf"
+	gresConfVolumeName = "gres-conf"
+	// GresConfChecksumAnnotation rolls the slurmd pods when gres.conf changes, slurmd only reads it on start
+	GresConfChecksumAnnotation = "slurm.ay.dev/gres-conf-checksum"
+)
+
+// buildSlurmdResources renders the requests and limits of a slurmd group, extended resources are set on both
+func buildSlurmdResources(resources *slurmv1.SlurmdResourceSpec) map[string]interface{} {
+	requests := map[string]string{
+		"cpu":               fmt.Sprintf("%dm", resources.Requests.Socket*resources.Requests.CorePerSocket*resources.Requests.ThreadPerCore*1000),
+		"memory":            resources.Requests.Memory,
+		"ephemeral-storage": resources.Requests.EphemeralStorage,
+	}
+	limits := map[string]string{
+		"cpu":               fmt.Sprintf("%dm", resources.Limits.Socket*resources.Limits.CorePerSocket*resources.Limits.ThreadPerCore*1000),
+		"memory":            resources.Limits.Memory,
+		"ephemeral-storage": resources.Limits.EphemeralStorage,
+	}
+	for _, extended := range resources.Extended {
+		requests[extended.Name] = extended.Quantity.String()
+		limits[extended.Name] = extended.Quantity.String()
+	}
+	return map[string]interface{}{
+		"requests": requests,
+		"limits":   limits,
+	}
+}
+
+// hugePagesSizes returns the hugepages sizes requested by a slurmd group, e.g. ["1Gi", "2Mi"]
+func hugePagesSizes(resources *slurmv1.SlurmdResourceSpec) []string {
+	sizes := []string{}
+	for _, extended := range resources.Extended {
+		if size, found := strings.CutPrefix(extended.Name, hugePagesResourcePrefix); found && !CheckIfExistInArray(sizes, size) {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Strings(sizes)
+	return sizes
+}
+
+// hugePagesVolumeName is the name of the volume backing one hugepages size, or every size when size is empty
+func hugePagesVolumeName(size string) string {
+	if size == "" {
+		return "hugepages"
+	}
+	return "hugepages-" + strings.ToLower(size)
+}
+
+// buildSlurmdExtraVolumes appends the hugepages volumes of a slurmd group to its extra volumes. A single size is
+// mounted at /dev/hugepages, several sizes each get their own medium.
+func buildSlurmdExtraVolumes(volumes []corev1.Volume, resources *slurmv1.SlurmdResourceSpec) []corev1.Volume {
+	sizes := hugePagesSizes(resources)
+	if len(sizes) == 0 {
+		return volumes
+	}
+	merged := append([]corev1.Volume{}, volumes...)
+	if len(sizes) == 1 {
+		return append(merged, corev1.Volume{
+			Name:         hugePagesVolumeName(""),
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumHugePages}},
+		})
+	}
+	for _, size := range sizes {
+		merged = append(merged, corev1.Volume{
+			Name: hugePagesVolumeName(size),
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{
+				Medium: corev1.StorageMedium(string(corev1.StorageMediumHugePagesPrefix) + size),
+			}},
+		})
+	}
+	return merged
+}
+
+// buildSlurmdExtraVolumeMounts appends the mounts of the volumes added by buildSlurmdExtraVolumes
+func buildSlurmdExtraVolumeMounts(mounts []slurmv1.ExtraVolumeMountsSpec, resources *slurmv1.SlurmdResourceSpec) []slurmv1.ExtraVolumeMountsSpec {
+	sizes := hugePagesSizes(resources)
+	if len(sizes) == 0 {
+		return mounts
+	}
+	merged := append([]slurmv1.ExtraVolumeMountsSpec{}, mounts...)
+	if len(sizes) == 1 {
+		return append(merged, slurmv1.ExtraVolumeMountsSpec{Name: hugePagesVolumeName(""), MountPath: "/dev/hugepages"})
+	}
+	for _, size := range sizes {
+		merged = append(merged, slurmv1.ExtraVolumeMountsSpec{Name: hugePagesVolumeName(size), MountPath: "/dev/hugepages-" + size})
+	}
+	return merged
+}
+
+// SlurmdGroupFeatures returns the static features of a slurmd group followed by the features of its extended resources
+func SlurmdGroupFeatures(features []string, resources *slurmv1.SlurmdResourceSpec) []string {
+	merged := append([]string{}, features...)
+	for _, extended := range resources.Extended {
+		if extended.Feature != "" && !CheckIfExistInArray(merged, extended.Feature) {
+			merged = append(merged, extended.Feature)
+		}
+	}
+	return merged
+}
+
+// slurmdGroupGres renders the GRES of a slurmd group, e.g. ["rdma:1", "gpu:a100:4"]
+func slurmdGroupGres(resources *slurmv1.SlurmdResourceSpec) []string {
+	gres := []string{}
+	for _, extended := range resources.Extended {
+		if extended.Gres == "" {
+			continue
+		}
+		gres = append(gres, fmt.Sprintf("%s:%d", extended.Gres, gresCount(&extended)))
+	}
+	return gres
+}
+
+// gresCount is the count a GRES is published with, the quantity of the resource unless overridden
+func gresCount(extended *slurmv1.SlurmdExtendedResourceSpec) int64 {
+	if extended.GresCount > 0 {
+		return extended.GresCount
+	}
+	return extended.Quantity.Value()
+}
+
+// GresConfMapName is the ConfigMap holding the gres.conf of a release
+func GresConfMapName(release *slurmv1.SlurmDeployment) string {
+	return ComponentName(release, "gres-conf")
+}
+
+// BuildGresConf renders the gres.conf of both slurmd groups, empty when no GRES is published. GPUs are found by
+// slurmd through NVML, every other GRES only has a count since there is no device file behind it.
+func BuildGresConf(release *slurmv1.SlurmDeployment, valuesSpec *slurmv1.ValuesSpec) string {
+	lines := []string{}
+	for _, group := range []struct {
+		component string
+		replicas  int32
+		resources *slurmv1.SlurmdResourceSpec
+	}{
+		{"slurmd-cpu", valuesSpec.SlurmdCPU.ReplicaCount, &valuesSpec.SlurmdCPU.Resources},
+		{"slurmd-gpu", valuesSpec.SlurmdGPU.ReplicaCount, &valuesSpec.SlurmdGPU.Resources},
+	} {
+		// the same node range as the NodeName line of the group in slurm.conf
+		nodes := fmt.Sprintf("%s-[0-%d]", ComponentName(release, group.component), group.replicas+10)
+		autoDetect := false
+		for _, extended := range group.resources.Extended {
+			if extended.Gres == "" {
+				continue
+			}
+			name, gresType, typed := strings.Cut(extended.Gres, ":")
+			if name == "gpu" {
+				if !autoDetect {
+					lines = append(lines, fmt.Sprintf("NodeName=%s AutoDetect=nvml", nodes))
+					autoDetect = true
+				}
+				continue
+			}
+			line := fmt.Sprintf("NodeName=%s Name=%s", nodes, name)
+			if typed {
+				line += " Type=" + gresType
+			}
+			lines = append(lines, fmt.Sprintf("%s Count=%d Flags=CountOnly", line, gresCount(&extended)))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// gresConfPodTemplate mounts gres.conf next to slurm.conf in the slurmd container
+func gresConfPodTemplate(release *slurmv1.SlurmDeployment, gresConf string) *corev1.PodTemplateSpec {
+	return &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			GresConfChecksumAnnotation: fmt.Sprintf("%x", sha256.Sum256([]byte(gresConf))),
+		}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: slurmdContainerName,
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      gresConfVolumeName,
+					MountPath: "/etc/slurm/" + gresConfKey,
+					SubPath:   gresConfKey,
+					ReadOnly:  true,
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: gresConfVolumeName,
+				VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: GresConfMapName(release)},
+				}},
+			}},
+		},
+	}
+}
+
+// gresConfMap is the ConfigMap document added to the helm release for gres.conf
+func gresConfMap(release *slurmv1.SlurmDeployment, gresConf string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GresConfMapName(release),
+			Namespace: release.Spec.Chart.Namespace,
+			Labels:    map[string]string{"app.kubernetes.io/instance": release.Name, "app.kubernetes.io/managed-by": "Helm"},
+		},
+		Data: map[string]string{gresConfKey: gresConf},
+	}
+}
+
+// buildGresTypesConf declares every GRES type used by a slurmd group in slurm.conf
+func buildGresTypesConf(groups ...*slurmv1.SlurmdResourceSpec) string {
+	types := []string{}
+	for _, resources := range groups {
+		for _, extended := range resources.Extended {
+			gresType := strings.SplitN(extended.Gres, ":", 2)[0]
+			if gresType != "" && !CheckIfExistInArray(types, gresType) {
+				types = append(types, gresType)
+			}
+		}
+	}
+	if len(types) == 0 {
+		return ""
+	}
+	sort.Strings(types)
+	return "GresTypes=" + strings.Join(types, ",") + "\n"
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+var _ = Describe("gres.conf", func() {
+	var (
+		release    *slurmv1.SlurmDeployment
+		valuesSpec *slurmv1.ValuesSpec
+	)
+
+	BeforeEach(func() {
+		release = &slurmv1.SlurmDeployment{ObjectMeta: metav1.ObjectMeta{Name: "lab"}}
+		release.Spec.Chart.Name = "slurm"
+		release.Spec.Chart.Namespace = "hpc"
+		valuesSpec = &slurmv1.ValuesSpec{}
+		valuesSpec.SlurmdCPU.ReplicaCount = 2
+		valuesSpec.SlurmdCPU.Resources.Extended = []slurmv1.SlurmdExtendedResourceSpec{
+			{Name: "rdma/hca", Quantity: resource.MustParse("1"), Gres: "rdma"},
+			{Name: "hugepages-2Mi", Quantity: resource.MustParse("1Gi"), Gres: "hugepages:2m", GresCount: 512},
+			{Name: "example.com/fpga", Quantity: resource.MustParse("1"), Feature: "fpga"},
+		}
+		valuesSpec.SlurmdGPU.ReplicaCount = 1
+		valuesSpec.SlurmdGPU.Resources.Extended = []slurmv1.SlurmdExtendedResourceSpec{
+			{Name: "nvidia.com/gpu", Quantity: resource.MustParse("2"), Gres: "gpu:a100"},
+			{Name: "nvidia.com/mig-1g.5gb", Quantity: resource.MustParse("4"), Gres: "gpu:1g.5gb"},
+		}
+	})
+
+	It("should count every GRES and detect the GPUs", func() {
+		Expect(BuildGresConf(release, valuesSpec)).To(Equal(
+			"NodeName=lab-slurm-slurmd-cpu-[0-12] Name=rdma Count=1 Flags=CountOnly\n" +
+				"NodeName=lab-slurm-slurmd-cpu-[0-12] Name=hugepages Type=2m Count=512 Flags=CountOnly\n" +
+				"NodeName=lab-slurm-slurmd-gpu-[0-11] AutoDetect=nvml\n"))
+		Expect(slurmdGroupGres(&valuesSpec.SlurmdCPU.Resources)).To(ConsistOf("rdma:1", "hugepages:2m:512"))
+	})
+
+	It("should be empty without GRES", func() {
+		valuesSpec.SlurmdCPU.Resources.Extended = nil
+		valuesSpec.SlurmdGPU.Resources.Extended = nil
+		Expect(BuildGresConf(release, valuesSpec)).To(BeEmpty())
+	})
+
+	It("should mount gres.conf into slurmd and add its ConfigMap to the release", func() {
+		renderer, err := ReleasePostRenderer(release, valuesSpec)
+		Expect(err).NotTo(HaveOccurred())
+		rendered, err := renderer.Run(bytes.NewBufferString(`apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: lab-slurm-slurmd-cpu
+spec:
+  template:
+    spec:
+      containers:
+      - name: slurmd
+        image: slurmd
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rendered.String()).To(ContainSubstring("slurm.ay.dev/gres-conf-checksum"))
+		Expect(rendered.String()).To(ContainSubstring("mountPath: /etc/slurm/gres.conf"))
+		Expect(rendered.String()).To(ContainSubstring("name: lab-slurm-gres-conf"))
+		Expect(rendered.String()).To(ContainSubstring("kind: ConfigMap"))
+		Expect(rendered.String()).To(ContainSubstring("AutoDetect=nvml"))
+	})
+})
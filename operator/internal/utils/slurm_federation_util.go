@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"strings"
+)
+
+// FederationSibling is one cluster line of `scontrol show federation`
+type FederationSibling struct {
+	ClusterName string
+	FedState    string
+	// Self marks the cluster the command ran on
+	Self      bool
+	Connected bool
+	Synced    bool
+}
+
+// ParseFederationStatus parses the output of `scontrol show federation`, e.g.
+//
+//	Federation: fed
+//	Self:       east:10.0.0.1:6817 ID:1 FedState:ACTIVE Features:
+//	Sibling:    west:10.0.0.2:6817 ID:2 FedState:ACTIVE Features: PersistConnSend/Recv:Yes/Yes Synced:Yes
+//
+// and returns the clusters keyed by name
+func ParseFederationStatus(output string) map[string]FederationSibling {
+	siblings := map[string]FederationSibling{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		self := strings.HasPrefix(line, "Self:")
+		if !self && !strings.HasPrefix(line, "Sibling:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		sibling := FederationSibling{
+			ClusterName: strings.SplitN(fields[1], ":", 2)[0],
+			Self:        self,
+			// the cluster reporting is always connected to itself
+			Connected: self,
+			Synced:    self,
+		}
+		for _, field := range fields[2:] {
+			key, value, found := strings.Cut(field, ":")
+			if !found {
+				continue
+			}
+			switch key {
+			case "FedState":
+				sibling.FedState = value
+			case "PersistConnSend/Recv":
+				sibling.Connected = value == "Yes/Yes"
+			case "Synced":
+				sibling.Synced = value == "Yes"
+			}
+		}
+		siblings[sibling.ClusterName] = sibling
+	}
+	return siblings
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("federation", func() {
+	DescribeTable("ParseFederationStatus",
+		func(output string, expected map[string]FederationSibling) {
+			Expect(ParseFederationStatus(output)).To(Equal(expected))
+		},
+		Entry("not federated", "Not part of any federation\n", map[string]FederationSibling{}),
+		Entry("self and siblings", `Federation: fed
+Self:       east:10.0.0.1:6817 ID:1 FedState:ACTIVE Features:
+Sibling:    west:10.0.0.2:6817 ID:2 FedState:ACTIVE Features: PersistConnSend/Recv:Yes/Yes Synced:Yes
+Sibling:    north:10.0.0.3:6817 ID:3 FedState:DRAIN Features: PersistConnSend/Recv:Yes/No Synced:No
+`, map[string]FederationSibling{
+			"east":  {ClusterName: "east", Self: true, FedState: "ACTIVE", Connected: true, Synced: true},
+			"west":  {ClusterName: "west", FedState: "ACTIVE", Connected: true, Synced: true},
+			"north": {ClusterName: "north", FedState: "DRAIN"},
+		}),
+		Entry("sibling that never connected", "Sibling:    south:0.0.0.0:0 ID:4 FedState:ACTIVE\n", map[string]FederationSibling{
+			"south": {ClusterName: "south", FedState: "ACTIVE"},
+		}),
+	)
+})
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+// LoginSSHPort is the port of the SSH service of the login nodes
+const LoginSSHPort = 22
+
+// LoginServiceName returns the name of the Service in front of the SSH port of the login nodes
+func LoginServiceName(release *slurmv1.SlurmDeployment) string {
+	return ComponentName(release, "login")
+}
+
+// loginServiceType returns the type of the login Service, ClusterIP unless set
+func loginServiceType(service *slurmv1.LoginServiceSpec) corev1.ServiceType {
+	if service.Type == "" {
+		return corev1.ServiceTypeClusterIP
+	}
+	return service.Type
+}
+
+// loginServicePatch renders the login service settings as a partial Service. The node port and the external
+// traffic policy are only valid on NodePort and LoadBalancer Services.
+func loginServicePatch(service *slurmv1.LoginServiceSpec) *corev1.Service {
+	patch := &corev1.Service{}
+	patch.Annotations = service.Annotations
+	patch.Spec.Type = loginServiceType(service)
+	if patch.Spec.Type != corev1.ServiceTypeClusterIP {
+		patch.Spec.ExternalTrafficPolicy = service.ExternalTrafficPolicy
+		if service.NodePort != 0 {
+			patch.Spec.Ports = []corev1.ServicePort{{
+				Port:       LoginSSHPort,
+				TargetPort: intstr.FromInt(LoginSSHPort),
+				NodePort:   service.NodePort,
+			}}
+		}
+	}
+	patch.Spec.SessionAffinity = service.SessionAffinity
+	if service.SessionAffinity == corev1.ServiceAffinityClientIP && service.SessionAffinityTimeoutSeconds != 0 {
+		timeout := service.SessionAffinityTimeoutSeconds
+		patch.Spec.SessionAffinityConfig = &corev1.SessionAffinityConfig{
+			ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: &timeout},
+		}
+	}
+	return patch
+}
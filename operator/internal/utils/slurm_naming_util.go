@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"fmt"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+const (
+	// SlurmctldContainerName is the name of the slurmctld container inside the slurmctld pod
+	SlurmctldContainerName = "slurmctld"
+)
+
+// ComponentName returns the workload name of a chart component, following <release>-<chart>-<component>
+func ComponentName(release *slurmv1.SlurmDeployment, component string) string {
+	return fmt.Sprintf("%s-%s-%s", release.Name, release.Spec.Chart.Name, component)
+}
+
+// SlurmctldPodName returns the name of the first (and only) slurmctld pod of a release
+func SlurmctldPodName(release *slurmv1.SlurmDeployment) string {
+	return fmt.Sprintf("%s-0", ComponentName(release, "slurmctld"))
+}
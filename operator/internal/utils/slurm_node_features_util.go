@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var invalidFeatureChars = regexp.MustCompile(`[^A-Za-z0-9_.]+`)
+
+// SlurmNodeFeatures merges the static features of a node group with the values of the given labels of the
+// kubernetes node a slurmd pod runs on. Labels missing on the node are skipped, the result is sorted.
+func SlurmNodeFeatures(static []string, fromLabels []string, nodeLabels map[string]string) []string {
+	features := []string{}
+	for _, feature := range static {
+		if feature != "" && !CheckIfExistInArray(features, feature) {
+			features = append(features, feature)
+		}
+	}
+	for _, label := range fromLabels {
+		value, found := nodeLabels[label]
+		if !found {
+			continue
+		}
+		feature := SlurmFeatureName(value)
+		if feature != "" && !CheckIfExistInArray(features, feature) {
+			features = append(features, feature)
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
+// SlurmFeatureName turns a label value into a feature name usable in --constraint expressions
+func SlurmFeatureName(value string) string {
+	return strings.Trim(invalidFeatureChars.ReplaceAllString(value, "_"), "_")
+}
+
+// SameFeatures tells whether two feature lists hold the same features in any order
+func SameFeatures(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	return strings.Join(sortedA, ",") == strings.Join(sortedB, ",")
+}
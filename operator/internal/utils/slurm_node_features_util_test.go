@@ -0,0 +1,42 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Slurm node features", func() {
+	DescribeTable("SlurmFeatureName",
+		func(value, expected string) {
+			Expect(SlurmFeatureName(value)).To(Equal(expected))
+		},
+		Entry("plain value", "m5.xlarge", "m5.xlarge"),
+		Entry("invalid characters", "NVIDIA-A100-SXM4-80GB", "NVIDIA_A100_SXM4_80GB"),
+		Entry("runs of invalid characters", "zone a / rack 1", "zone_a_rack_1"),
+		Entry("leading and trailing invalid characters", "-east-", "east"),
+		Entry("nothing usable", "--", ""),
+	)
+
+	DescribeTable("SlurmNodeFeatures",
+		func(static, fromLabels []string, nodeLabels map[string]string, expected []string) {
+			Expect(SlurmNodeFeatures(static, fromLabels, nodeLabels)).To(Equal(expected))
+		},
+		Entry("static features only", []string{"cpu", "", "avx512", "cpu"}, nil, nil, []string{"avx512", "cpu"}),
+		Entry("label values merged and sorted", []string{"cpu"},
+			[]string{"node.kubernetes.io/instance-type", "topology.kubernetes.io/zone"},
+			map[string]string{"node.kubernetes.io/instance-type": "m5.xlarge", "topology.kubernetes.io/zone": "eu-west-1a"},
+			[]string{"cpu", "eu_west_1a", "m5.xlarge"}),
+		Entry("labels missing on the node skipped", []string{"cpu"}, []string{"nvidia.com/gpu.product"},
+			map[string]string{"kubernetes.io/hostname": "node-a"}, []string{"cpu"}),
+		Entry("label values equal to a static feature kept once", []string{"gpu"}, []string{"accelerator"},
+			map[string]string{"accelerator": "gpu"}, []string{"gpu"}),
+		Entry("label values without a usable feature name skipped", nil, []string{"rack"},
+			map[string]string{"rack": "/"}, []string{}),
+	)
+
+	It("compares feature lists in any order", func() {
+		Expect(SameFeatures([]string{"cpu", "m5.xlarge"}, []string{"m5.xlarge", "cpu"})).To(BeTrue())
+		Expect(SameFeatures([]string{"cpu"}, []string{"cpu", "m5.xlarge"})).To(BeFalse())
+		Expect(SameFeatures([]string{}, nil)).To(BeTrue())
+	})
+})
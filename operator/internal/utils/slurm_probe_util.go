@@ -0,0 +1,93 @@
+package utils
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+const (
+	slurmctldPort = 6817
+	slurmdPort    = 6818
+	slurmdbdPort  = 6819
+)
+
+// probeChecks are the handlers of the liveness, readiness and startup probes of a container
+type probeChecks struct {
+	liveness  corev1.ProbeHandler
+	readiness corev1.ProbeHandler
+	startup   corev1.ProbeHandler
+}
+
+func tcpProbe(port int) corev1.ProbeHandler {
+	return corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(port)}}
+}
+
+func execProbe(script string) corev1.ProbeHandler {
+	return corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"sh", "-c", script}}}
+}
+
+var (
+	// slurmctld is alive while it listens, and ready once it answers RPCs
+	slurmctldProbeChecks = probeChecks{
+		liveness:  tcpProbe(slurmctldPort),
+		readiness: execProbe("scontrol ping | grep -q 'is UP'"),
+		startup:   tcpProbe(slurmctldPort),
+	}
+	// slurmd is alive while it listens, and ready once the local daemon answers. The check never asks slurmctld,
+	// otherwise a slurmctld restart would turn every slurmd unready at once.
+	slurmdProbeChecks = probeChecks{
+		liveness:  tcpProbe(slurmdPort),
+		readiness: execProbe("scontrol show slurmd > /dev/null"),
+		startup:   tcpProbe(slurmdPort),
+	}
+	slurmdbdProbeChecks = probeChecks{
+		liveness:  tcpProbe(slurmdbdPort),
+		readiness: tcpProbe(slurmdbdPort),
+		startup:   tcpProbe(slurmdbdPort),
+	}
+	// munged is checked with a credential round trip through its socket
+	mungedProbeChecks = probeChecks{
+		liveness:  execProbe("munge -n | unmunge > /dev/null"),
+		readiness: execProbe("munge -n | unmunge > /dev/null"),
+		startup:   execProbe("munge -n | unmunge > /dev/null"),
+	}
+)
+
+// buildProbe renders one probe, nil when it is disabled
+func buildProbe(spec slurmv1.ProbeSpec, handler corev1.ProbeHandler, readiness bool) *corev1.Probe {
+	if !spec.Enabled {
+		return nil
+	}
+	probe := &corev1.Probe{
+		ProbeHandler:        handler,
+		InitialDelaySeconds: spec.InitialDelaySeconds,
+		TimeoutSeconds:      spec.TimeoutSeconds,
+		PeriodSeconds:       spec.PeriodSeconds,
+		SuccessThreshold:    1,
+		FailureThreshold:    spec.FailureThreshold,
+	}
+	if readiness && spec.SuccessThreshold > 0 {
+		probe.SuccessThreshold = spec.SuccessThreshold
+	}
+	return probe
+}
+
+// probesPodTemplate renders the probes of a container as a partial pod template, nil when every probe is disabled
+// or the container runs in diagnostic mode, where it does not start the daemon
+func probesPodTemplate(container string, probes *slurmv1.ProbesSpec, checks probeChecks, diagnosticMode slurmv1.DiagnosticModeSpec) *corev1.PodTemplateSpec {
+	if diagnosticMode.Enabled {
+		return nil
+	}
+	probed := corev1.Container{
+		Name:           container,
+		LivenessProbe:  buildProbe(probes.Liveness, checks.liveness, false),
+		ReadinessProbe: buildProbe(probes.Readiness, checks.readiness, true),
+		StartupProbe:   buildProbe(probes.Startup, checks.startup, false),
+	}
+	if probed.LivenessProbe == nil && probed.ReadinessProbe == nil && probed.StartupProbe == nil {
+		return nil
+	}
+	return &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{probed}}}
+}
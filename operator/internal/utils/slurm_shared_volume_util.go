@@ -0,0 +1,81 @@
+package utils
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+// Components mounting the shared volumes
+const (
+	SharedVolumeSlurmctld = "slurmctld"
+	SharedVolumeSlurmd    = "slurmd"
+	SharedVolumeLogin     = "login"
+)
+
+// sharedVolumePrefix keeps the pod volumes of the shared volumes apart from the volumes of the chart
+const sharedVolumePrefix = "shared-"
+
+// SharedVolumeClaimName returns the PVC backing a shared volume, the existing claim or the one created by the operator
+func SharedVolumeClaimName(release *slurmv1.SlurmDeployment, volume *slurmv1.SharedVolumeSpec) string {
+	if volume.ExistingClaim != "" {
+		return volume.ExistingClaim
+	}
+	return ComponentName(release, sharedVolumePrefix+volume.Name)
+}
+
+// SharedVolumeAccessModes returns the access modes of a created PVC, ReadWriteMany unless set
+func SharedVolumeAccessModes(volume *slurmv1.SharedVolumeSpec) []corev1.PersistentVolumeAccessMode {
+	if len(volume.AccessModes) == 0 {
+		return []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+	}
+	return volume.AccessModes
+}
+
+// sharedVolumeMountPath returns where a component mounts a shared volume
+func sharedVolumeMountPath(volume *slurmv1.SharedVolumeSpec, component string) string {
+	override := ""
+	switch component {
+	case SharedVolumeSlurmctld:
+		override = volume.MountPaths.Slurmctld
+	case SharedVolumeSlurmd:
+		override = volume.MountPaths.Slurmd
+	case SharedVolumeLogin:
+		override = volume.MountPaths.Login
+	}
+	if override != "" {
+		return override
+	}
+	return volume.MountPath
+}
+
+// sharedVolumesPodTemplate renders the shared volumes of a component as a partial pod template mounting them in the
+// given containers, nil without shared volumes
+func sharedVolumesPodTemplate(release *slurmv1.SlurmDeployment, volumes []slurmv1.SharedVolumeSpec, component string,
+	containers []string) *corev1.PodTemplateSpec {
+	if len(volumes) == 0 || len(containers) == 0 {
+		return nil
+	}
+	template := &corev1.PodTemplateSpec{}
+	mounts := []corev1.VolumeMount{}
+	for i := range volumes {
+		volume := &volumes[i]
+		template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+			Name: sharedVolumePrefix + volume.Name,
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: SharedVolumeClaimName(release, volume),
+				ReadOnly:  volume.ReadOnly,
+			}},
+		})
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      sharedVolumePrefix + volume.Name,
+			MountPath: sharedVolumeMountPath(volume, component),
+			ReadOnly:  volume.ReadOnly,
+			SubPath:   volume.SubPath,
+		})
+	}
+	for _, container := range containers {
+		template.Spec.Containers = append(template.Spec.Containers, corev1.Container{Name: container, VolumeMounts: mounts})
+	}
+	return template
+}
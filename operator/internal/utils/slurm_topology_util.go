@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+const (
+	// DefaultTopologyZoneLabel is the well-known node label used when no zone label is configured
+	DefaultTopologyZoneLabel = "topology.kubernetes.io/zone"
+	// SlurmctldConfDir holds the config files of slurmctld together with topology.conf. Unlike the subPath mounts
+	// of the chart the directory follows the ConfigMaps, so a new topology.conf lands without restarting slurmctld.
+	SlurmctldConfDir = "/etc/slurm-conf"
+	// TopologyConfPath is where slurmctld reads topology.conf, next to slurm.conf
+	TopologyConfPath = SlurmctldConfDir + "/" + topologyConfKey
+
+	topologyConfKey        = "topology.conf"
+	slurmctldConfVolume    = "slurmctld-conf"
+	slurmConfMountPath     = "/etc/slurm/slurm.conf"
+	slurmctldConfMountRoot = "/etc/slurm/"
+
+	topologyRootSwitch    = "root"
+	topologyUnknownSwitch = "unknown"
+)
+
+var invalidSwitchNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// TopologyNode is a slurm node together with the topology labels of the kubernetes node it runs on
+type TopologyNode struct {
+	Name string
+	Zone string
+	Rack string
+}
+
+// TopologyZoneLabel returns the node label grouping nodes into zones
+func TopologyZoneLabel(topology *slurmv1.TopologySpec) string {
+	if topology.ZoneLabel == "" {
+		return DefaultTopologyZoneLabel
+	}
+	return topology.ZoneLabel
+}
+
+// BuildTopologyConf renders topology.conf for topology/tree with one leaf switch per rack, one switch per zone
+// and a root switch when there is more than one zone. It returns the file and the number of switches.
+func BuildTopologyConf(nodes []TopologyNode) (string, int) {
+	zones := map[string]map[string][]string{}
+	for _, node := range nodes {
+		zone := topologySwitchName(node.Zone)
+		if zones[zone] == nil {
+			zones[zone] = map[string][]string{}
+		}
+		zones[zone][node.Rack] = append(zones[zone][node.Rack], node.Name)
+	}
+
+	lines := []string{}
+	zoneNames := sortedKeys(zones)
+	for _, zone := range zoneNames {
+		racks := zones[zone]
+		rackNames := sortedKeys(racks)
+		if len(rackNames) == 1 && rackNames[0] == "" {
+			// no rack label, the zone switch holds the nodes directly
+			lines = append(lines, fmt.Sprintf("SwitchName=%s Nodes=%s", zone, joinSorted(racks[""])))
+			continue
+		}
+		rackSwitches := []string{}
+		for _, rack := range rackNames {
+			rackSwitch := zone + "-" + topologySwitchName(rack)
+			rackSwitches = append(rackSwitches, rackSwitch)
+			lines = append(lines, fmt.Sprintf("SwitchName=%s Nodes=%s", rackSwitch, joinSorted(racks[rack])))
+		}
+		lines = append(lines, fmt.Sprintf("SwitchName=%s Switches=%s", zone, strings.Join(rackSwitches, ",")))
+	}
+	if len(zoneNames) > 1 {
+		lines = append(lines, fmt.Sprintf("SwitchName=%s Switches=%s", topologyRootSwitch, strings.Join(zoneNames, ",")))
+	}
+	if len(lines) == 0 {
+		return "", 0
+	}
+	return strings.Join(lines, "\n") + "\n", len(lines)
+}
+
+// TopologyConfHash identifies a rendered topology.conf in the status
+func TopologyConfHash(content string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+}
+
+// TopologyConfMapName is the ConfigMap the operator keeps topology.conf in
+func TopologyConfMapName(release *slurmv1.SlurmDeployment) string {
+	return ComponentName(release, "topology")
+}
+
+// TopologyConfMap returns the ConfigMap holding topology.conf, labeled like the objects of the helm release
+func TopologyConfMap(release *slurmv1.SlurmDeployment, content string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TopologyConfMapName(release),
+			Namespace: release.Spec.Chart.Namespace,
+			Labels:    map[string]string{"app.kubernetes.io/instance": release.Name},
+		},
+		Data: map[string]string{topologyConfKey: content},
+	}
+}
+
+// ReadTopologyConf returns the topology.conf slurmctld sees, empty until the kubelet synced the ConfigMap
+func ReadTopologyConf(ctx context.Context, executor PodExecutor, release *slurmv1.SlurmDeployment) (string, error) {
+	return RunSlurmctldCommand(ctx, executor, release, "sh", "-c", "cat "+TopologyConfPath+" 2>/dev/null || true")
+}
+
+// slurmctldConfPodTemplate projects the config files the chart mounts with subPath under /etc/slurm together with
+// the topology ConfigMap into one directory, and points slurmctld at the slurm.conf in it. It returns nil when the
+// rendered slurmctld container does not mount slurm.conf from a ConfigMap.
+func slurmctldConfPodTemplate(release *slurmv1.SlurmDeployment, rendered *corev1.PodTemplateSpec) *corev1.PodTemplateSpec {
+	configMaps := map[string]*corev1.ConfigMapVolumeSource{}
+	for i := range rendered.Spec.Volumes {
+		if source := rendered.Spec.Volumes[i].ConfigMap; source != nil {
+			configMaps[rendered.Spec.Volumes[i].Name] = source
+		}
+	}
+	sources := []corev1.VolumeProjection{}
+	hasSlurmConf := false
+	for _, container := range rendered.Spec.Containers {
+		if container.Name != SlurmctldContainerName {
+			continue
+		}
+		for _, mount := range container.VolumeMounts {
+			source := configMaps[mount.Name]
+			if source == nil || mount.SubPath == "" || !strings.HasPrefix(mount.MountPath, slurmctldConfMountRoot) {
+				continue
+			}
+			// the subPath is a path of the volume, which maps back to a key through the items
+			key := mount.SubPath
+			for _, item := range source.Items {
+				if item.Path == mount.SubPath {
+					key = item.Key
+				}
+			}
+			sources = append(sources, corev1.VolumeProjection{ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: source.LocalObjectReference,
+				Items:                []corev1.KeyToPath{{Key: key, Path: path.Base(mount.MountPath)}},
+			}})
+			hasSlurmConf = hasSlurmConf || mount.MountPath == slurmConfMountPath
+		}
+	}
+	if !hasSlurmConf {
+		return nil
+	}
+	// optional since the operator only writes topology.conf once slurmd pods are scheduled
+	sources = append(sources, corev1.VolumeProjection{ConfigMap: &corev1.ConfigMapProjection{
+		LocalObjectReference: corev1.LocalObjectReference{Name: TopologyConfMapName(release)},
+		Items:                []corev1.KeyToPath{{Key: topologyConfKey, Path: topologyConfKey}},
+		Optional:             func(b bool) *bool { return &b }(true),
+	}})
+	return &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:         SlurmctldContainerName,
+				Env:          []corev1.EnvVar{{Name: "SLURM_CONF", Value: SlurmctldConfDir + "/slurm.conf"}},
+				VolumeMounts: []corev1.VolumeMount{{Name: slurmctldConfVolume, MountPath: SlurmctldConfDir, ReadOnly: true}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name:         slurmctldConfVolume,
+				VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: sources}},
+			}},
+		},
+	}
+}
+
+// topologySwitchName turns a label value into a valid SwitchName
+func topologySwitchName(value string) string {
+	name := strings.Trim(invalidSwitchNameChars.ReplaceAllString(value, "-"), "-")
+	if name == "" {
+		return topologyUnknownSwitch
+	}
+	return name
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinSorted(values []string) string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
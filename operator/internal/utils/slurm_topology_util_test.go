@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+var _ = Describe("topology.conf mount", func() {
+	const slurmctld = `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: lab-slurm-slurmctld
+spec:
+  template:
+    spec:
+      containers:
+      - name: slurmctld
+        volumeMounts:
+        - name: slurm-conf-file
+          mountPath: /etc/slurm/slurm.conf
+          subPath: slurm.conf
+        - name: cgroup-conf-file
+          mountPath: /etc/slurm/cgroup.conf
+          subPath: cgroup
+        - name: state
+          mountPath: /var/spool/slurmctld
+      volumes:
+      - name: slurm-conf-file
+        configMap:
+          name: lab-slurm-slurm-conf
+      - name: cgroup-conf-file
+        configMap:
+          name: lab-slurm-cgroup-conf
+          items:
+          - key: cgroup.conf
+            path: cgroup
+      - name: state
+        emptyDir: {}
+`
+	var release *slurmv1.SlurmDeployment
+
+	render := func(valuesSpec *slurmv1.ValuesSpec) *corev1.PodTemplateSpec {
+		renderer, err := ReleasePostRenderer(release, valuesSpec)
+		Expect(err).NotTo(HaveOccurred())
+		rendered, err := renderer.Run(bytes.NewBufferString(slurmctld))
+		Expect(err).NotTo(HaveOccurred())
+		sts := struct {
+			Spec struct {
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}{}
+		Expect(yaml.Unmarshal(rendered.Bytes(), &sts)).To(Succeed())
+		return &sts.Spec.Template
+	}
+
+	BeforeEach(func() {
+		release = &slurmv1.SlurmDeployment{ObjectMeta: metav1.ObjectMeta{Name: "lab"}}
+		release.Spec.Chart.Name = "slurm"
+	})
+
+	It("should project the config files and topology.conf into one directory", func() {
+		valuesSpec := &slurmv1.ValuesSpec{}
+		valuesSpec.SlurmConfig.Topology.Enabled = true
+		template := render(valuesSpec)
+
+		container := template.Spec.Containers[0]
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "SLURM_CONF", Value: "/etc/slurm-conf/slurm.conf"}))
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: "slurmctld-conf", MountPath: SlurmctldConfDir, ReadOnly: true,
+		}))
+		var projected *corev1.ProjectedVolumeSource
+		for _, volume := range template.Spec.Volumes {
+			if volume.Name == "slurmctld-conf" {
+				projected = volume.Projected
+			}
+		}
+		Expect(projected).NotTo(BeNil())
+		sources := map[string]corev1.KeyToPath{}
+		for _, source := range projected.Sources {
+			sources[source.ConfigMap.Name] = source.ConfigMap.Items[0]
+		}
+		Expect(sources).To(Equal(map[string]corev1.KeyToPath{
+			"lab-slurm-slurm-conf":  {Key: "slurm.conf", Path: "slurm.conf"},
+			"lab-slurm-cgroup-conf": {Key: "cgroup.conf", Path: "cgroup.conf"},
+			"lab-slurm-topology":    {Key: "topology.conf", Path: "topology.conf"},
+		}))
+	})
+
+	It("should leave slurmctld alone without topology", func() {
+		template := render(&slurmv1.ValuesSpec{})
+		Expect(template.Spec.Containers[0].Env).To(BeEmpty())
+		Expect(template.Spec.Volumes).To(HaveLen(3))
+	})
+})
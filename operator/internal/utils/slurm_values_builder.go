@@ -164,6 +164,19 @@ func BuildSlurmValues(valuesSpec *slurmv1.ValuesSpec) map[string]interface{} {
 		}
 	}
 
+	// SlurmDeployments written as v2 carry no defaults for single resource values
+	defaultResourceSpec(valuesSpec.Slurmctld.Resources)
+	defaultSlurmdResourceSpec(&valuesSpec.SlurmdCPU.Resources)
+	defaultSlurmdResourceSpec(&valuesSpec.SlurmdGPU.Resources)
+	defaultResourceSpec(&valuesSpec.SlurmLogin.Resources)
+
+	// a cluster sharing the slurmdbd of another release deploys neither slurmdbd nor MariaDB
+	sharedSlurmdbd := valuesSpec.SlurmConfig.Accounting.StorageHost != ""
+	slurmdbdReplicas := 1
+	if sharedSlurmdbd {
+		slurmdbdReplicas = 0
+	}
+
 	values := map[string]interface{}{
 		"nameOverride":      valuesSpec.NameOverride,
 		"fullnameOverride":  valuesSpec.FullnameOverride,
@@ -175,7 +188,7 @@ func BuildSlurmValues(valuesSpec *slurmv1.ValuesSpec) map[string]interface{} {
 			},
 		},
 		"mariadb": map[string]interface{}{
-			"enabled": valuesSpec.Mariadb.Enabled,
+			"enabled": valuesSpec.Mariadb.Enabled && !sharedSlurmdbd,
 			"port":    valuesSpec.Mariadb.Port,
 			"auth": map[string]interface{}{
 				"rootPassword": valuesSpec.Mariadb.Auth.RootPassword,
@@ -301,7 +314,7 @@ func BuildSlurmValues(valuesSpec *slurmv1.ValuesSpec) map[string]interface{} {
 					"ephemeral-storage": valuesSpec.Slurmctld.Resources.Limits.EphemeralStorage,
 				},
 			},
-			"extraVolumes":      valuesSpec.Slurmctld.ExtraVolumes,
+			"extraVolumes":      slurmctldExtraVolumes(valuesSpec.Slurmctld.ExtraVolumes),
 			"extraVolumeMounts": valuesSpec.Slurmctld.ExtraVolumeMounts,
 			"livenessProbe": map[string]interface{}{
 				"enabled":             false,
@@ -393,21 +406,10 @@ func BuildSlurmValues(valuesSpec *slurmv1.ValuesSpec) map[string]interface{} {
 				"type":          "RollingUpdate",
 				"rollingUpdate": map[string]string{},
 			},
-			"lifecycleHooks": map[string]string{},
-			"resources": map[string]interface{}{
-				"requests": map[string]string{
-					"cpu":               fmt.Sprintf("%dm", valuesSpec.SlurmdCPU.Resources.Requests.Socket*valuesSpec.SlurmdCPU.Resources.Requests.CorePerSocket*valuesSpec.SlurmdCPU.Resources.Requests.ThreadPerCore*1000),
-					"memory":            valuesSpec.SlurmdCPU.Resources.Requests.Memory,
-					"ephemeral-storage": valuesSpec.SlurmdCPU.Resources.Requests.EphemeralStorage,
-				},
-				"limits": map[string]string{
-					"cpu":               fmt.Sprintf("%dm", valuesSpec.SlurmdCPU.Resources.Limits.Socket*valuesSpec.SlurmdCPU.Resources.Limits.CorePerSocket*valuesSpec.SlurmdCPU.Resources.Limits.ThreadPerCore*1000),
-					"memory":            valuesSpec.SlurmdCPU.Resources.Limits.Memory,
-					"ephemeral-storage": valuesSpec.SlurmdCPU.Resources.Limits.EphemeralStorage,
-				},
-			},
-			"extraVolumes":      valuesSpec.SlurmdCPU.ExtraVolumes,
-			"extraVolumeMounts": valuesSpec.SlurmdCPU.ExtraVolumeMounts,
+			"lifecycleHooks":    map[string]string{},
+			"resources":         buildSlurmdResources(&valuesSpec.SlurmdCPU.Resources),
+			"extraVolumes":      buildSlurmdExtraVolumes(valuesSpec.SlurmdCPU.ExtraVolumes, &valuesSpec.SlurmdCPU.Resources),
+			"extraVolumeMounts": buildSlurmdExtraVolumeMounts(valuesSpec.SlurmdCPU.ExtraVolumeMounts, &valuesSpec.SlurmdCPU.Resources),
 			"livenessProbe": map[string]interface{}{
 				"enabled":             false,
 				"initialDelaySeconds": 30,
@@ -498,21 +500,10 @@ func BuildSlurmValues(valuesSpec *slurmv1.ValuesSpec) map[string]interface{} {
 				"type":          "RollingUpdate",
 				"rollingUpdate": map[string]string{},
 			},
-			"lifecycleHooks": map[string]string{},
-			"resources": map[string]interface{}{
-				"requests": map[string]string{
-					"cpu":               fmt.Sprintf("%dm", valuesSpec.SlurmdGPU.Resources.Requests.Socket*valuesSpec.SlurmdGPU.Resources.Requests.CorePerSocket*valuesSpec.SlurmdGPU.Resources.Requests.ThreadPerCore*1000),
-					"memory":            valuesSpec.SlurmdGPU.Resources.Requests.Memory,
-					"ephemeral-storage": valuesSpec.SlurmdGPU.Resources.Requests.EphemeralStorage,
-				},
-				"limits": map[string]string{
-					"cpu":               fmt.Sprintf("%dm", valuesSpec.SlurmdGPU.Resources.Limits.Socket*valuesSpec.SlurmdGPU.Resources.Limits.CorePerSocket*valuesSpec.SlurmdGPU.Resources.Limits.ThreadPerCore*1000),
-					"memory":            valuesSpec.SlurmdGPU.Resources.Limits.Memory,
-					"ephemeral-storage": valuesSpec.SlurmdGPU.Resources.Limits.EphemeralStorage,
-				},
-			},
-			"extraVolumes":      valuesSpec.SlurmdGPU.ExtraVolumes,
-			"extraVolumeMounts": valuesSpec.SlurmdGPU.ExtraVolumeMounts,
+			"lifecycleHooks":    map[string]string{},
+			"resources":         buildSlurmdResources(&valuesSpec.SlurmdGPU.Resources),
+			"extraVolumes":      buildSlurmdExtraVolumes(valuesSpec.SlurmdGPU.ExtraVolumes, &valuesSpec.SlurmdGPU.Resources),
+			"extraVolumeMounts": buildSlurmdExtraVolumeMounts(valuesSpec.SlurmdGPU.ExtraVolumeMounts, &valuesSpec.SlurmdGPU.Resources),
 			"livenessProbe": map[string]interface{}{
 				"enabled":             false,
 				"initialDelaySeconds": 30,
@@ -554,7 +545,7 @@ func BuildSlurmValues(valuesSpec *slurmv1.ValuesSpec) map[string]interface{} {
 		"slurmdbd": map[string]interface{}{
 			"name":         "slurmdbd",
 			"commonLabels": map[string]string{},
-			"replicaCount": 1,
+			"replicaCount": slurmdbdReplicas,
 			"image": map[string]interface{}{
 				"registry":    valuesSpec.Slurmdbd.Image.Registry,
 				"repository":  valuesSpec.Slurmdbd.Image.Repository,
@@ -647,7 +638,7 @@ func BuildSlurmValues(valuesSpec *slurmv1.ValuesSpec) map[string]interface{} {
 		"login": map[string]interface{}{
 			"name":         "login",
 			"commonLabels": map[string]string{},
-			"replicaCount": 1,
+			"replicaCount": valuesSpec.SlurmLogin.ReplicaCount,
 			"image": map[string]interface{}{
 				"registry":    valuesSpec.SlurmLogin.Image.Registry,
 				"repository":  valuesSpec.SlurmLogin.Image.Repository,
@@ -736,11 +727,13 @@ func BuildSlurmValues(valuesSpec *slurmv1.ValuesSpec) map[string]interface{} {
 				"failureThreshold":    6,
 			},
 			"service": map[string]interface{}{
-				"name": "login",
+				"name":        "login",
+				"annotations": valuesSpec.SlurmLogin.Service.Annotations,
 				"ssh": map[string]interface{}{
-					"type":       "ClusterIP",
-					"port":       22,
-					"targetPort": 22,
+					"type":       loginServiceType(&valuesSpec.SlurmLogin.Service),
+					"port":       LoginSSHPort,
+					"targetPort": LoginSSHPort,
+					"nodePort":   valuesSpec.SlurmLogin.Service.NodePort,
 				},
 			},
 		},
@@ -766,7 +759,7 @@ ConstrainDevices=yes
 ConstrainRAMSpace=yes
 ConstrainSwapSpace=no`,
 			},
-			"slurmConf": `ClusterName=slurm-cluster
+			"slurmConf": `ClusterName=` + SlurmClusterName(&valuesSpec.SlurmConfig) + `
 SlurmctldHost={{ include "slurm.fullname" . }}-{{ .Values.slurmctld.name }}-0
 MpiDefault=pmi2
 DebugFlags=cgroup
@@ -789,18 +782,16 @@ SlurmdTimeout=300
 Waittime=0
 SchedulerType=sched/backfill
 SelectType=select/cons_tres
-AccountingStorageHost={{ include "slurm.fullname" . }}-{{ .Values.slurmdbd.name }}-0
-AccountingStoragePort={{ .Values.slurmdbd.service.slurmdbd.port }}
-AccountingStorageType=accounting_storage/slurmdbd
+` + buildAccountingStorageConf(&valuesSpec.SlurmConfig.Accounting) + `AccountingStorageType=accounting_storage/slurmdbd
 AccountingStoreFlags=job_comment
 JobAcctGatherType=jobacct_gather/linux
 JobAcctGatherFrequency=30
 SlurmctldDebug=info
 SlurmctldLogFile=/var/log/slurm/slurmctld.log
 SlurmdLogFile=/var/log/slurm/slurmd.log
-NodeName={{ include "slurm.fullname" . }}-slurmd-cpu-[0-` + fmt.Sprintf("%d", valuesSpec.SlurmdCPU.ReplicaCount+10) + `] CPUs=` + fmt.Sprintf("%d", valuesSpec.SlurmdCPU.Resources.Requests.Socket*valuesSpec.SlurmdCPU.Resources.Requests.CorePerSocket*valuesSpec.SlurmdCPU.Resources.Requests.ThreadPerCore) + ` Sockets=` + fmt.Sprintf("%d", valuesSpec.SlurmdCPU.Resources.Requests.Socket) + ` CoresPerSocket=` + fmt.Sprintf("%d", valuesSpec.SlurmdCPU.Resources.Requests.CorePerSocket) + ` ThreadsPerCore=` + fmt.Sprintf("%d", valuesSpec.SlurmdCPU.Resources.Requests.ThreadPerCore) + ` RealMemory=` + fmt.Sprintf("%d", ParseRAMstr(valuesSpec.SlurmdCPU.Resources.Requests.Memory)) + ` State=UNKNOWN
-NodeName={{ include "slurm.fullname" . }}-slurmd-gpu-[0-` + fmt.Sprintf("%d", valuesSpec.SlurmdGPU.ReplicaCount+10) + `] CPUs=` + fmt.Sprintf("%d", valuesSpec.SlurmdGPU.Resources.Requests.Socket*valuesSpec.SlurmdGPU.Resources.Requests.CorePerSocket*valuesSpec.SlurmdGPU.Resources.Requests.ThreadPerCore) + ` Sockets=` + fmt.Sprintf("%d", valuesSpec.SlurmdGPU.Resources.Requests.Socket) + ` CoresPerSocket=` + fmt.Sprintf("%d", valuesSpec.SlurmdGPU.Resources.Requests.CorePerSocket) + ` ThreadsPerCore=` + fmt.Sprintf("%d", valuesSpec.SlurmdGPU.Resources.Requests.ThreadPerCore) + ` RealMemory=` + fmt.Sprintf("%d", ParseRAMstr(valuesSpec.SlurmdGPU.Resources.Requests.Memory)) + ` State=UNKNOWN
-PartitionName=compute Nodes=ALL Default=YES MaxTime=INFINITE State=UP`,
+` + buildSchedulingConf(&valuesSpec.SlurmConfig.Scheduling) + buildTopologyPluginConf(&valuesSpec.SlurmConfig.Topology) + buildGresTypesConf(&valuesSpec.SlurmdCPU.Resources, &valuesSpec.SlurmdGPU.Resources) + `NodeName={{ include "slurm.fullname" . }}-slurmd-cpu-[0-` + fmt.Sprintf("%d", valuesSpec.SlurmdCPU.ReplicaCount+10) + `] CPUs=` + fmt.Sprintf("%d", valuesSpec.SlurmdCPU.Resources.Requests.Socket*valuesSpec.SlurmdCPU.Resources.Requests.CorePerSocket*valuesSpec.SlurmdCPU.Resources.Requests.ThreadPerCore) + ` Sockets=` + fmt.Sprintf("%d", valuesSpec.SlurmdCPU.Resources.Requests.Socket) + ` CoresPerSocket=` + fmt.Sprintf("%d", valuesSpec.SlurmdCPU.Resources.Requests.CorePerSocket) + ` ThreadsPerCore=` + fmt.Sprintf("%d", valuesSpec.SlurmdCPU.Resources.Requests.ThreadPerCore) + ` RealMemory=` + fmt.Sprintf("%d", ParseRAMstr(valuesSpec.SlurmdCPU.Resources.Requests.Memory)) + buildNodeFeaturesConf(SlurmdGroupFeatures(valuesSpec.SlurmdCPU.Features, &valuesSpec.SlurmdCPU.Resources), slurmdGroupGres(&valuesSpec.SlurmdCPU.Resources), valuesSpec.SlurmdCPU.Weight) + ` State=UNKNOWN
+NodeName={{ include "slurm.fullname" . }}-slurmd-gpu-[0-` + fmt.Sprintf("%d", valuesSpec.SlurmdGPU.ReplicaCount+10) + `] CPUs=` + fmt.Sprintf("%d", valuesSpec.SlurmdGPU.Resources.Requests.Socket*valuesSpec.SlurmdGPU.Resources.Requests.CorePerSocket*valuesSpec.SlurmdGPU.Resources.Requests.ThreadPerCore) + ` Sockets=` + fmt.Sprintf("%d", valuesSpec.SlurmdGPU.Resources.Requests.Socket) + ` CoresPerSocket=` + fmt.Sprintf("%d", valuesSpec.SlurmdGPU.Resources.Requests.CorePerSocket) + ` ThreadsPerCore=` + fmt.Sprintf("%d", valuesSpec.SlurmdGPU.Resources.Requests.ThreadPerCore) + ` RealMemory=` + fmt.Sprintf("%d", ParseRAMstr(valuesSpec.SlurmdGPU.Resources.Requests.Memory)) + buildNodeFeaturesConf(SlurmdGroupFeatures(valuesSpec.SlurmdGPU.Features, &valuesSpec.SlurmdGPU.Resources), slurmdGroupGres(&valuesSpec.SlurmdGPU.Resources), valuesSpec.SlurmdGPU.Weight) + ` State=UNKNOWN
+PartitionName=compute Nodes=ALL Default=YES MaxTime=INFINITE State=UP` + buildPartitionQOSConf(&valuesSpec.SlurmConfig.Scheduling),
 			"slurmdbdConf": `AuthType=auth/munge
 AuthInfo=/var/run/munge/munge.socket.2
 SlurmUser=slurm
@@ -819,3 +810,143 @@ StorageLoc={{ .Values.mariadb.auth.database }}`,
 	}
 	return values
 }
+
+// DefaultSlurmClusterName is the ClusterName of releases installed before it could be configured
+const DefaultSlurmClusterName = "slurm-cluster"
+
+// SlurmClusterName returns the ClusterName rendered into slurm.conf
+func SlurmClusterName(config *slurmv1.SlurmConfigSpec) string {
+	if config.ClusterName == "" {
+		return DefaultSlurmClusterName
+	}
+	return config.ClusterName
+}
+
+// buildAccountingStorageConf points slurmctld at the slurmdbd of the release or at a shared one
+func buildAccountingStorageConf(accounting *slurmv1.AccountingSpec) string {
+	if accounting.StorageHost == "" {
+		return `AccountingStorageHost={{ include "slurm.fullname" . }}-{{ .Values.slurmdbd.name }}-0
+AccountingStoragePort={{ .Values.slurmdbd.service.slurmdbd.port }}
+`
+	}
+	port := accounting.StoragePort
+	if port == 0 {
+		port = 6819
+	}
+	return fmt.Sprintf("AccountingStorageHost=%s\nAccountingStoragePort=%d\n", accounting.StorageHost, port)
+}
+
+// buildSchedulingConf renders the priority, preemption and enforcement lines of slurm.conf
+func buildSchedulingConf(scheduling *slurmv1.SchedulingSpec) string {
+	lines := []string{}
+	if scheduling.PriorityType != "" {
+		lines = append(lines, "PriorityType="+scheduling.PriorityType)
+	}
+	if scheduling.PriorityWeightQOS > 0 {
+		lines = append(lines, fmt.Sprintf("PriorityWeightQOS=%d", scheduling.PriorityWeightQOS))
+	}
+	if scheduling.PriorityWeightFairshare > 0 {
+		lines = append(lines, fmt.Sprintf("PriorityWeightFairshare=%d", scheduling.PriorityWeightFairshare))
+	}
+	if scheduling.PriorityWeightAge > 0 {
+		lines = append(lines, fmt.Sprintf("PriorityWeightAge=%d", scheduling.PriorityWeightAge))
+	}
+	if scheduling.PriorityWeightJobSize > 0 {
+		lines = append(lines, fmt.Sprintf("PriorityWeightJobSize=%d", scheduling.PriorityWeightJobSize))
+	}
+	if scheduling.PreemptType != "" {
+		lines = append(lines, "PreemptType="+scheduling.PreemptType)
+	}
+	if scheduling.PreemptMode != "" {
+		lines = append(lines, "PreemptMode="+scheduling.PreemptMode)
+	}
+	if scheduling.AccountingStorageEnforce != "" {
+		lines = append(lines, "AccountingStorageEnforce="+scheduling.AccountingStorageEnforce)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// buildNodeFeaturesConf renders the static features, the GRES and the weight appended to the NodeName line of a node group
+func buildNodeFeaturesConf(features []string, gres []string, weight int32) string {
+	options := ""
+	if len(features) > 0 {
+		options += " Features=" + strings.Join(features, ",")
+	}
+	if len(gres) > 0 {
+		options += " Gres=" + strings.Join(gres, ",")
+	}
+	if weight > 0 {
+		options += fmt.Sprintf(" Weight=%d", weight)
+	}
+	return options
+}
+
+// slurmctldExtraVolumes renders the v1 slurmctld extraVolumes with their JSON volume sources decoded
+func slurmctldExtraVolumes(entries []map[string]string) []map[string]interface{} {
+	volumes := []map[string]interface{}{}
+	for _, entry := range entries {
+		volumes = append(volumes, slurmv1.ExtraVolumeValues(entry))
+	}
+	return volumes
+}
+
+// buildTopologyPluginConf enables topology/tree, topology.conf itself is kept in a ConfigMap by the operator
+func buildTopologyPluginConf(topology *slurmv1.TopologySpec) string {
+	if !topology.Enabled {
+		return ""
+	}
+	return "TopologyPlugin=topology/tree\n"
+}
+
+// buildPartitionQOSConf renders the QoS options appended to the compute partition line
+func buildPartitionQOSConf(scheduling *slurmv1.SchedulingSpec) string {
+	options := ""
+	if scheduling.PartitionQOS != "" {
+		options += " QOS=" + scheduling.PartitionQOS
+	}
+	if len(scheduling.AllowedQOS) > 0 {
+		options += " AllowQos=" + strings.Join(scheduling.AllowedQOS, ",")
+	}
+	return options
+}
+
+// defaultResourceSpec fills the requests and limits left unset with the defaults of the v1 API
+func defaultResourceSpec(resources *slurmv1.ResourceSpec) {
+	if resources.Requests == nil {
+		resources.Requests = &slurmv1.ResourceRequestSpec{}
+	}
+	defaultString(&resources.Requests.CPU, "500m")
+	defaultString(&resources.Requests.Memory, "1Gi")
+	defaultString(&resources.Requests.EphemeralStorage, "2Gi")
+	if resources.Limits == nil {
+		resources.Limits = &slurmv1.ResourceLimitSpec{}
+	}
+	defaultString(&resources.Limits.CPU, "3000m")
+	defaultString(&resources.Limits.Memory, "2Gi")
+	defaultString(&resources.Limits.EphemeralStorage, "8Gi")
+}
+
+// defaultSlurmdResourceSpec fills the values left unset in the requests and limits of a slurmd group with the
+// defaults of the v1 API
+func defaultSlurmdResourceSpec(resources *slurmv1.SlurmdResourceSpec) {
+	requests, limits := resources.Requests, resources.Limits
+	for _, count := range []*int32{&requests.Socket, &requests.CorePerSocket, &requests.ThreadPerCore,
+		&limits.Socket, &limits.CorePerSocket, &limits.ThreadPerCore} {
+		if *count == 0 {
+			*count = 1
+		}
+	}
+	defaultString(&requests.Memory, "1Gi")
+	defaultString(&requests.EphemeralStorage, "2Gi")
+	defaultString(&limits.Memory, "2Gi")
+	defaultString(&limits.EphemeralStorage, "8Gi")
+}
+
+func defaultString(value *string, defaultValue string) {
+	if *value == "" {
+		*value = defaultValue
+	}
+}
@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+// Slurm components in the order a version upgrade rolls them out
+const (
+	SlurmComponentSlurmdbd  = "slurmdbd"
+	SlurmComponentSlurmctld = "slurmctld"
+	SlurmComponentSlurmdCPU = "slurmdCPU"
+	SlurmComponentSlurmdGPU = "slurmdGPU"
+	SlurmComponentLogin     = "login"
+)
+
+// slurmVersionPattern matches tags such as "24.05.3", "slurm-24-05-3-1" or "23.11"
+var slurmVersionPattern = regexp.MustCompile(`(\d{2})[.-](\d{2})(?:[.-](\d+))?`)
+
+// slurmReleases are the major releases before Slurm moved to a release every six months with 24.05
+var slurmReleases = []string{"20.02", "20.11", "21.08", "22.05", "23.02", "23.11", "24.05"}
+
+// SlurmVersion is a Slurm release such as 24.05 plus its maintenance version
+type SlurmVersion struct {
+	Year  int
+	Month int
+	Patch int
+}
+
+// ParseSlurmVersion extracts the Slurm version from an image tag, tags without a version such as "latest" are not
+// parsed. A version right after "slurm" wins over other versions of the tag, e.g. of the base image in
+// "ubuntu-22.04-slurm-23.11"; otherwise only a known Slurm release is taken, which leaves out dates such as
+// "2024-11-05".
+func ParseSlurmVersion(tag string) (SlurmVersion, bool) {
+	var fallback *SlurmVersion
+	for offset := 0; offset < len(tag); {
+		match := slurmVersionPattern.FindStringSubmatchIndex(tag[offset:])
+		if match == nil {
+			break
+		}
+		start, end := offset+match[0], offset+match[1]
+		// the numbers must not be part of longer ones, "2024" is no year of a release
+		if (start > 0 && isDigit(tag[start-1])) || (end < len(tag) && isDigit(tag[end])) {
+			offset = start + 1
+			continue
+		}
+		year, _ := strconv.Atoi(tag[offset+match[2] : offset+match[3]])
+		month, _ := strconv.Atoi(tag[offset+match[4] : offset+match[5]])
+		patch := 0
+		if match[6] >= 0 {
+			patch, _ = strconv.Atoi(tag[offset+match[6] : offset+match[7]])
+		}
+		version := SlurmVersion{Year: year, Month: month, Patch: patch}
+		if strings.HasSuffix(strings.ToLower(strings.TrimRight(tag[:start], "-_.:")), "slurm") {
+			return version, true
+		}
+		if _, known := version.releaseIndex(); known && fallback == nil {
+			fallback = &version
+		}
+		offset = end
+	}
+	if fallback == nil {
+		return SlurmVersion{}, false
+	}
+	return *fallback, true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// Release returns the major release, e.g. "24.05"
+func (v SlurmVersion) Release() string {
+	return fmt.Sprintf("%02d.%02d", v.Year, v.Month)
+}
+
+func (v SlurmVersion) String() string {
+	return fmt.Sprintf("%s.%d", v.Release(), v.Patch)
+}
+
+// releaseIndex numbers the major releases so the distance of an upgrade can be counted
+func (v SlurmVersion) releaseIndex() (int, bool) {
+	for i, release := range slurmReleases {
+		if release == v.Release() {
+			return i, true
+		}
+	}
+	if v.Year >= 24 && (v.Month == 5 || v.Month == 11) {
+		// releases after 24.05 follow in May and November
+		return len(slurmReleases) - 1 + (v.Year-24)*2 + (v.Month-5)/6, true
+	}
+	return 0, false
+}
+
+// CheckSlurmUpgrade refuses version changes Slurm does not support: downgrades of the major release and upgrades
+// skipping more releases than slurmdbd and the state files can be converted from
+func CheckSlurmUpgrade(from, to SlurmVersion) error {
+	if from.Release() == to.Release() {
+		return nil
+	}
+	fromIndex, fromKnown := from.releaseIndex()
+	toIndex, toKnown := to.releaseIndex()
+	if !fromKnown || !toKnown {
+		return fmt.Errorf("unknown Slurm release in upgrade from %s to %s", from.Release(), to.Release())
+	}
+	if toIndex < fromIndex {
+		return fmt.Errorf("downgrading Slurm from %s to %s is not supported", from.Release(), to.Release())
+	}
+	// since 24.11 the three previous releases can be upgraded from, before that the two previous ones
+	window := 2
+	if to.Year > 24 || (to.Year == 24 && to.Month >= 11) {
+		window = 3
+	}
+	if toIndex-fromIndex > window {
+		return fmt.Errorf("Slurm %s can only be upgraded to from the %d previous releases, %s is %d releases behind",
+			to.Release(), window, from.Release(), toIndex-fromIndex)
+	}
+	return nil
+}
+
+// SlurmComponentImages returns the images of the components running Slurm daemons or clients
+func SlurmComponentImages(values *slurmv1.ValuesSpec) map[string]slurmv1.ImageSpec {
+	return map[string]slurmv1.ImageSpec{
+		SlurmComponentSlurmdbd:  values.Slurmdbd.Image,
+		SlurmComponentSlurmctld: values.Slurmctld.Image,
+		SlurmComponentSlurmdCPU: values.SlurmdCPU.Image,
+		SlurmComponentSlurmdGPU: values.SlurmdGPU.Image,
+		SlurmComponentLogin:     values.SlurmLogin.Image,
+	}
+}
+
+// SetSlurmComponentImage replaces the image of one component returned by SlurmComponentImages
+func SetSlurmComponentImage(values *slurmv1.ValuesSpec, component string, image slurmv1.ImageSpec) {
+	switch component {
+	case SlurmComponentSlurmdbd:
+		values.Slurmdbd.Image = image
+	case SlurmComponentSlurmctld:
+		values.Slurmctld.Image = image
+	case SlurmComponentSlurmdCPU:
+		values.SlurmdCPU.Image = image
+	case SlurmComponentSlurmdGPU:
+		values.SlurmdGPU.Image = image
+	case SlurmComponentLogin:
+		values.SlurmLogin.Image = image
+	}
+}
+
+// SlurmImagesVersion returns the Slurm version shared by the component images. Images without a version in their tag
+// are ignored, found is false when no image carries one.
+func SlurmImagesVersion(images map[string]slurmv1.ImageSpec) (version SlurmVersion, found bool, err error) {
+	for _, component := range []string{SlurmComponentSlurmdbd, SlurmComponentSlurmctld, SlurmComponentSlurmdCPU,
+		SlurmComponentSlurmdGPU, SlurmComponentLogin} {
+		parsed, ok := ParseSlurmVersion(images[component].Tag)
+		if !ok {
+			continue
+		}
+		if found && parsed.Release() != version.Release() {
+			return SlurmVersion{}, false, fmt.Errorf("components use different Slurm releases: %s and %s", version.Release(), parsed.Release())
+		}
+		if !found || parsed.Patch > version.Patch {
+			version = parsed
+		}
+		found = true
+	}
+	return version, found, nil
+}
+
+// SameImage compares the parts of two images that select what is pulled
+func SameImage(a, b slurmv1.ImageSpec) bool {
+	return a.Registry == b.Registry && a.Repository == b.Repository && a.Tag == b.Tag
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+var _ = Describe("Slurm versions", func() {
+	DescribeTable("ParseSlurmVersion",
+		func(tag string, expected string, found bool) {
+			version, ok := ParseSlurmVersion(tag)
+			Expect(ok).To(Equal(found))
+			if found {
+				Expect(version.String()).To(Equal(expected))
+			}
+		},
+		Entry("release with patch", "24.05.3", "24.05.3", true),
+		Entry("release only", "23.11", "23.11.0", true),
+		Entry("upstream dash tag", "slurm-24-05-3-1", "24.05.3", true),
+		Entry("distribution suffix", "24.11.1-ubuntu22.04", "24.11.1", true),
+		Entry("base image before slurm", "ubuntu-22.04-slurm-23.11", "23.11.0", true),
+		Entry("slurm prefix without separator", "rocky9-slurm25.05.2", "25.05.2", true),
+		Entry("date", "2024-11-05", "", false),
+		Entry("dated build", "build-20241105", "", false),
+		Entry("base image only", "ubuntu-22.04", "", false),
+		Entry("latest", "latest", "", false),
+		Entry("semantic version", "v1.2.3", "", false),
+		Entry("empty", "", "", false),
+	)
+
+	version := func(tag string) SlurmVersion {
+		parsed, ok := ParseSlurmVersion(tag)
+		Expect(ok).To(BeTrue())
+		return parsed
+	}
+
+	DescribeTable("CheckSlurmUpgrade",
+		func(from, to string, failure string) {
+			err := CheckSlurmUpgrade(version(from), version(to))
+			if failure == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring(failure)))
+			}
+		},
+		Entry("maintenance release", "23.11.1", "23.11.10", ""),
+		Entry("maintenance downgrade", "24.05.3", "24.05.1", ""),
+		Entry("next release", "23.11.4", "24.05.0", ""),
+		Entry("24.05 to 24.11", "24.05.3", "24.11.0", ""),
+		Entry("two releases before 24.11", "23.02.7", "24.05.1", ""),
+		Entry("three releases before 24.11", "22.05.8", "24.05.1", "2 previous releases"),
+		Entry("three releases since 24.11", "23.02.7", "24.11.0", ""),
+		Entry("four releases since 24.11", "22.05.8", "24.11.0", "3 previous releases"),
+		Entry("releases after 24.11", "24.11.1", "25.05.0", ""),
+		Entry("release downgrade", "24.05.1", "23.11.4", "downgrading"),
+		Entry("downgrade across years", "25.05.0", "24.11.3", "downgrading"),
+		Entry("unknown release", "slurm-19.05.8", "20.02.1", "unknown Slurm release"),
+	)
+
+	images := func(tags map[string]string) map[string]slurmv1.ImageSpec {
+		result := map[string]slurmv1.ImageSpec{}
+		for component, tag := range tags {
+			result[component] = slurmv1.ImageSpec{Repository: "slurm", Tag: tag}
+		}
+		return result
+	}
+
+	DescribeTable("SlurmImagesVersion",
+		func(tags map[string]string, expected string, found bool, failure string) {
+			version, ok, err := SlurmImagesVersion(images(tags))
+			if failure != "" {
+				Expect(err).To(MatchError(ContainSubstring(failure)))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(Equal(found))
+			if found {
+				Expect(version.String()).To(Equal(expected))
+			}
+		},
+		Entry("shared release, highest patch", map[string]string{
+			SlurmComponentSlurmdbd: "24.05.2", SlurmComponentSlurmctld: "24.05.4", SlurmComponentLogin: "24.05.3",
+		}, "24.05.4", true, ""),
+		Entry("tags without a version are ignored", map[string]string{
+			SlurmComponentSlurmctld: "23.11.6", SlurmComponentSlurmdCPU: "latest", SlurmComponentLogin: "2024-11-05",
+		}, "23.11.6", true, ""),
+		Entry("no version at all", map[string]string{
+			SlurmComponentSlurmctld: "latest", SlurmComponentSlurmdCPU: "nightly",
+		}, "", false, ""),
+		Entry("mixed releases", map[string]string{
+			SlurmComponentSlurmdbd: "24.11.1", SlurmComponentSlurmctld: "24.05.4",
+		}, "", false, "different Slurm releases"),
+	)
+})
@@ -0,0 +1,31 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	slurmv2 "github.com/AaronYang0628/slurm-on-k8s/api/v2"
+)
+
+// SetupSlurmDeploymentWebhookWithManager serves the conversion of SlurmDeployments between v1 and the v2 storage
+// version on /convert of the webhook server. Both versions have to be registered in the scheme of the manager.
+func SetupSlurmDeploymentWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&slurmv2.SlurmDeployment{}).
+		Complete()
+}
@@ -0,0 +1,210 @@
+package webterminal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+
+const (
+	AuthModeTokenReview = "TokenReview"
+	AuthModeOIDC        = "OIDC"
+
+	// DefaultTokenAudience is the audience of TokenReview tokens when the SlurmDeployment names none, a token issued
+	// for the API server is never accepted so that a credential leaked to the terminal cannot be replayed elsewhere
+	DefaultTokenAudience = "slurm.ay.dev/web-terminal"
+
+	// oidcDiscoveryRetry keeps sessions from hammering a provider whose discovery failed
+	oidcDiscoveryRetry = time.Minute
+	// oidcMaxResponseBytes caps the discovery document and the key set read from the provider
+	oidcMaxResponseBytes = 1 << 20
+)
+
+// UserInfo is the identity behind the bearer token of a session
+type UserInfo struct {
+	Username string
+	Groups   []string
+}
+
+// Authenticator resolves the identity behind a bearer token
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*UserInfo, error)
+}
+
+// AuthenticatorResolver returns the Authenticator configured for the sessions of a SlurmDeployment
+type AuthenticatorResolver func(auth *slurmv1.WebTerminalAuthSpec) (Authenticator, error)
+
+// NewAuthenticatorResolver checks tokens with TokenReview through c, and keeps one OIDC verifier per provider
+func NewAuthenticatorResolver(c client.Client) AuthenticatorResolver {
+	var mu sync.Mutex
+	verifiers := map[slurmv1.OIDCSpec]*oidcAuthenticator{}
+	return func(auth *slurmv1.WebTerminalAuthSpec) (Authenticator, error) {
+		switch auth.Mode {
+		case "", AuthModeTokenReview:
+			audience := auth.Audience
+			if audience == "" {
+				audience = DefaultTokenAudience
+			}
+			return &tokenReviewAuthenticator{client: c, audience: audience}, nil
+		case AuthModeOIDC:
+			if auth.OIDC == nil {
+				return nil, errors.New("authentication mode OIDC needs oidc settings")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			verifier, found := verifiers[*auth.OIDC]
+			if !found {
+				verifier = newOIDCAuthenticator(auth.OIDC)
+				verifiers[*auth.OIDC] = verifier
+			}
+			return verifier, nil
+		default:
+			return nil, fmt.Errorf("unknown authentication mode %s", auth.Mode)
+		}
+	}
+}
+
+// tokenReviewAuthenticator asks the API server who a token belongs to, which covers service account tokens and
+// every authenticator the API server is configured with. Only tokens issued for the audience are accepted.
+type tokenReviewAuthenticator struct {
+	client   client.Client
+	audience string
+}
+
+func (a *tokenReviewAuthenticator) Authenticate(ctx context.Context, token string) (*UserInfo, error) {
+	review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{
+		Token:     token,
+		Audiences: []string{a.audience},
+	}}
+	if err := a.client.Create(ctx, review); err != nil {
+		return nil, fmt.Errorf("token review failed: %v", err)
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return nil, fmt.Errorf("token rejected: %s", review.Status.Error)
+		}
+		return nil, errors.New("token rejected")
+	}
+	// an authenticator that ignores audiences answers without them, which must not pass for the audience
+	if !slices.Contains(review.Status.Audiences, a.audience) {
+		return nil, fmt.Errorf("token not issued for audience %s", a.audience)
+	}
+	return &UserInfo{Username: review.Status.User.Username, Groups: review.Status.User.Groups}, nil
+}
+
+// oidcAuthenticator verifies RS256 and ES256 ID tokens against the signing keys published by the provider. Like
+// kube-apiserver, it prefixes every username claim but email with the issuer, so that a name picked by a user at the
+// provider never collides with a login user by accident.
+type oidcAuthenticator struct {
+	spec       slurmv1.OIDCSpec
+	httpClient *http.Client
+
+	mu           sync.Mutex
+	verifier     *oidc.IDTokenVerifier
+	discoveredAt time.Time
+}
+
+func newOIDCAuthenticator(spec *slurmv1.OIDCSpec) *oidcAuthenticator {
+	verifier := &oidcAuthenticator{spec: *spec, httpClient: &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: limitedTransport{base: http.DefaultTransport, limit: oidcMaxResponseBytes},
+	}}
+	if verifier.spec.UsernameClaim == "" {
+		verifier.spec.UsernameClaim = "sub"
+	}
+	if verifier.spec.GroupsClaim == "" {
+		verifier.spec.GroupsClaim = "groups"
+	}
+	return verifier
+}
+
+func (a *oidcAuthenticator) Authenticate(ctx context.Context, token string) (*UserInfo, error) {
+	verifier, err := a.idTokenVerifier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	claims := map[string]interface{}{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("malformed ID token claims: %v", err)
+	}
+
+	username, _ := claims[a.spec.UsernameClaim].(string)
+	if username == "" {
+		return nil, fmt.Errorf("ID token has no %s claim", a.spec.UsernameClaim)
+	}
+	if a.spec.UsernameClaim == "email" {
+		// an address the provider did not verify may belong to somebody else
+		if verified, _ := claims["email_verified"].(bool); !verified && claims["email_verified"] != "true" {
+			return nil, fmt.Errorf("email %s is not verified", username)
+		}
+	} else {
+		username = a.spec.IssuerURL + "#" + username
+	}
+	user := &UserInfo{Username: username}
+	if groups, ok := claims[a.spec.GroupsClaim].([]interface{}); ok {
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				user.Groups = append(user.Groups, name)
+			}
+		}
+	}
+	return user, nil
+}
+
+// idTokenVerifier discovers the provider on first use, its key set is fetched again whenever a token is signed
+// with an unknown key
+func (a *oidcAuthenticator) idTokenVerifier(ctx context.Context) (*oidc.IDTokenVerifier, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.verifier != nil {
+		return a.verifier, nil
+	}
+	if time.Since(a.discoveredAt) < oidcDiscoveryRetry {
+		return nil, fmt.Errorf("OIDC provider %s is not discovered yet", a.spec.IssuerURL)
+	}
+	a.discoveredAt = time.Now()
+	provider, err := oidc.NewProvider(oidc.ClientContext(ctx, a.httpClient), a.spec.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider %s: %v", a.spec.IssuerURL, err)
+	}
+	a.verifier = provider.Verifier(&oidc.Config{
+		ClientID:             a.spec.ClientID,
+		SupportedSigningAlgs: []string{oidc.RS256, oidc.ES256},
+	})
+	return a.verifier, nil
+}
+
+// limitedTransport caps the response bodies read from the provider
+type limitedTransport struct {
+	base  http.RoundTripper
+	limit int64
+}
+
+func (t limitedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := t.base.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(response.Body, t.limit), response.Body}
+	return response, nil
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webterminal
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+var _ = Describe("TokenReview authentication", func() {
+	var (
+		reviewed  []string
+		audiences []string
+		resolve   AuthenticatorResolver
+	)
+
+	BeforeEach(func() {
+		reviewed = nil
+		// the API server answers with the requested audiences the token was issued for
+		audiences = []string{DefaultTokenAudience}
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+				review := obj.(*authenticationv1.TokenReview)
+				reviewed = append(reviewed, review.Spec.Audiences...)
+				review.Status = authenticationv1.TokenReviewStatus{
+					Authenticated: review.Spec.Token == "valid-token",
+					User:          authenticationv1.UserInfo{Username: "alice", Groups: []string{"hpc-users"}},
+					Audiences:     audiences,
+				}
+				return nil
+			},
+		}).Build()
+		resolve = NewAuthenticatorResolver(c)
+	})
+
+	It("should review tokens for the default audience", func() {
+		authenticator, err := resolve(&slurmv1.WebTerminalAuthSpec{Mode: AuthModeTokenReview})
+		Expect(err).NotTo(HaveOccurred())
+		user, err := authenticator.Authenticate(context.Background(), "valid-token")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user).To(Equal(&UserInfo{Username: "alice", Groups: []string{"hpc-users"}}))
+		Expect(reviewed).To(Equal([]string{DefaultTokenAudience}))
+	})
+
+	It("should review tokens for the configured audience", func() {
+		audiences = []string{"terminal.example.com"}
+		authenticator, err := resolve(&slurmv1.WebTerminalAuthSpec{Audience: "terminal.example.com"})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = authenticator.Authenticate(context.Background(), "valid-token")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reviewed).To(Equal([]string{"terminal.example.com"}))
+	})
+
+	It("should reject tokens not issued for the audience", func() {
+		authenticator, err := resolve(&slurmv1.WebTerminalAuthSpec{})
+		Expect(err).NotTo(HaveOccurred())
+
+		audiences = nil
+		_, err = authenticator.Authenticate(context.Background(), "valid-token")
+		Expect(err).To(MatchError(ContainSubstring("audience")))
+
+		audiences = []string{"https://kubernetes.default.svc"}
+		_, err = authenticator.Authenticate(context.Background(), "valid-token")
+		Expect(err).To(MatchError(ContainSubstring("audience")))
+	})
+
+	It("should reject unauthenticated tokens", func() {
+		authenticator, err := resolve(&slurmv1.WebTerminalAuthSpec{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = authenticator.Authenticate(context.Background(), "forged-token")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+// signToken encodes the header and claims of a JWS and signs them with the key
+func signToken(header, claims map[string]interface{}, key crypto.Signer) string {
+	encode := func(value interface{}) string {
+		data, err := json.Marshal(value)
+		Expect(err).NotTo(HaveOccurred())
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signingInput := encode(header) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	var signature []byte
+	switch typed := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, typed, crypto.SHA256, digest[:])
+		Expect(err).NotTo(HaveOccurred())
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, typed, digest[:])
+		Expect(err).NotTo(HaveOccurred())
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+var _ = Describe("OIDC authentication", func() {
+	var (
+		rsaKey        *rsa.PrivateKey
+		ecKey         *ecdsa.PrivateKey
+		provider      *httptest.Server
+		authenticator *oidcAuthenticator
+		claims        map[string]interface{}
+	)
+
+	encodeInt := func(value *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(value.Bytes())
+	}
+
+	BeforeEach(func() {
+		var err error
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		mux := http.NewServeMux()
+		provider = httptest.NewServer(mux)
+		mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]string{"issuer": provider.URL, "jwks_uri": provider.URL + "/keys"})
+		})
+		mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+				{"kty": "RSA", "kid": "rsa", "n": encodeInt(rsaKey.N), "e": encodeInt(big.NewInt(int64(rsaKey.E)))},
+				{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encodeInt(ecKey.X), "y": encodeInt(ecKey.Y)},
+			}})
+		})
+
+		authenticator = newOIDCAuthenticator(&slurmv1.OIDCSpec{IssuerURL: provider.URL, ClientID: "slurm-terminal"})
+		claims = map[string]interface{}{
+			"iss":                provider.URL,
+			"aud":                "slurm-terminal",
+			"exp":                time.Now().Add(time.Hour).Unix(),
+			"sub":                "alice",
+			"preferred_username": "alice",
+			"groups":             []string{"hpc-users"},
+		}
+	})
+
+	AfterEach(func() {
+		provider.Close()
+	})
+
+	It("should accept RS256 and ES256 tokens of the provider", func() {
+		user, err := authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, rsaKey))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user).To(Equal(&UserInfo{Username: provider.URL + "#alice", Groups: []string{"hpc-users"}}))
+
+		claims["aud"] = []string{"other-client", "slurm-terminal"}
+		user, err = authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "ES256", "kid": "ec"}, claims, ecKey))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user.Username).To(Equal(provider.URL + "#alice"))
+	})
+
+	It("should prefix every username claim but email with the issuer", func() {
+		authenticator = newOIDCAuthenticator(&slurmv1.OIDCSpec{
+			IssuerURL: provider.URL, ClientID: "slurm-terminal", UsernameClaim: "preferred_username",
+		})
+		claims["preferred_username"] = "root"
+		user, err := authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, rsaKey))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user.Username).To(Equal(provider.URL + "#root"))
+	})
+
+	It("should only accept a verified email", func() {
+		authenticator = newOIDCAuthenticator(&slurmv1.OIDCSpec{
+			IssuerURL: provider.URL, ClientID: "slurm-terminal", UsernameClaim: "email",
+		})
+		claims["email"] = "alice@example.com"
+		_, err := authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, rsaKey))
+		Expect(err).To(MatchError(ContainSubstring("not verified")))
+
+		claims["email_verified"] = false
+		_, err = authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, rsaKey))
+		Expect(err).To(MatchError(ContainSubstring("not verified")))
+
+		claims["email_verified"] = true
+		user, err := authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, rsaKey))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user.Username).To(Equal("alice@example.com"))
+	})
+
+	It("should reject a bad signature", func() {
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, otherKey))
+		Expect(err).To(MatchError(ContainSubstring("signature")))
+
+		token := signToken(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, rsaKey)
+		parts := strings.Split(token, ".")
+		claims["sub"] = "root"
+		forged := strings.Split(signToken(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, rsaKey), ".")
+		_, err = authenticator.Authenticate(context.Background(), parts[0]+"."+forged[1]+"."+parts[2])
+		Expect(err).To(MatchError(ContainSubstring("signature")))
+	})
+
+	It("should reject algorithm confusion", func() {
+		// alg none carries no signature at all
+		unsigned := strings.Split(signToken(map[string]interface{}{"alg": "none", "kid": "rsa"}, claims, rsaKey), ".")
+		_, err := authenticator.Authenticate(context.Background(), unsigned[0]+"."+unsigned[1]+".")
+		Expect(err).To(HaveOccurred())
+
+		// HS256 keyed with the public RSA key, which anybody can fetch from the provider
+		header := strings.Split(signToken(map[string]interface{}{"alg": "HS256", "kid": "rsa"}, claims, rsaKey), ".")
+		mac := hmac.New(sha256.New, rsaKey.N.Bytes())
+		mac.Write([]byte(header[0] + "." + header[1]))
+		_, err = authenticator.Authenticate(context.Background(),
+			header[0]+"."+header[1]+"."+base64.RawURLEncoding.EncodeToString(mac.Sum(nil)))
+		Expect(err).To(HaveOccurred())
+
+		// ES256 claimed for the RSA key and RS256 claimed for the EC key
+		_, err = authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "ES256", "kid": "rsa"}, claims, ecKey))
+		Expect(err).To(HaveOccurred())
+		_, err = authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "RS256", "kid": "ec"}, claims, rsaKey))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject an expired token", func() {
+		claims["exp"] = time.Now().Add(-time.Minute).Unix()
+		_, err := authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, rsaKey))
+		Expect(err).To(MatchError(ContainSubstring("expired")))
+
+		delete(claims, "exp")
+		_, err = authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, rsaKey))
+		Expect(err).To(MatchError(ContainSubstring("expired")))
+	})
+
+	It("should reject a token for another audience or issuer", func() {
+		claims["aud"] = "other-client"
+		_, err := authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, rsaKey))
+		Expect(err).To(MatchError(ContainSubstring("audience")))
+
+		claims["aud"] = "slurm-terminal"
+		claims["iss"] = "https://issuer.example.com"
+		_, err = authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, rsaKey))
+		Expect(err).To(MatchError(ContainSubstring("different provider")))
+	})
+
+	It("should not read more than a capped discovery document", func() {
+		huge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"issuer": "` + strings.Repeat("x", 2*oidcMaxResponseBytes) + `"}`))
+		}))
+		defer huge.Close()
+		authenticator = newOIDCAuthenticator(&slurmv1.OIDCSpec{IssuerURL: huge.URL, ClientID: "slurm-terminal"})
+		_, err := authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, rsaKey))
+		Expect(err).To(MatchError(ContainSubstring("failed to discover")))
+	})
+
+	It("should reject an unknown key id", func() {
+		_, err := authenticator.Authenticate(context.Background(),
+			signToken(map[string]interface{}{"alg": "RS256", "kid": "rotated"}, claims, rsaKey))
+		Expect(err).To(MatchError(ContainSubstring("signature")))
+	})
+})
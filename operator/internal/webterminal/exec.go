@@ -0,0 +1,83 @@
+package webterminal
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// TerminalSize is the size of the browser terminal in characters
+type TerminalSize struct {
+	Cols uint16 `json:"cols"`
+	Rows uint16 `json:"rows"`
+}
+
+// TerminalStreams connect a command running in a pod to a browser session
+type TerminalStreams struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	// Resize delivers the new size whenever the browser terminal is resized, it is closed with the session
+	Resize <-chan TerminalSize
+}
+
+// TerminalExecutor runs an interactive command on a TTY inside a container of a running pod
+type TerminalExecutor interface {
+	Stream(ctx context.Context, namespace, podName, container string, command []string, streams TerminalStreams) error
+}
+
+type remoteTerminalExecutor struct {
+	config    *rest.Config
+	clientset kubernetes.Interface
+}
+
+// NewTerminalExecutor creates a TerminalExecutor backed by the pods/exec subresource of the API server
+func NewTerminalExecutor(config *rest.Config) (TerminalExecutor, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %v", err)
+	}
+	return &remoteTerminalExecutor{config: config, clientset: clientset}, nil
+}
+
+func (e *remoteTerminalExecutor) Stream(ctx context.Context, namespace, podName, container string, command []string, streams TerminalStreams) error {
+	req := e.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     true,
+			Stdout:    true,
+			TTY:       true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(e.config, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create executor for pod %s/%s: %v", namespace, podName, err)
+	}
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:             streams.Stdin,
+		Stdout:            streams.Stdout,
+		Tty:               true,
+		TerminalSizeQueue: sizeQueue(streams.Resize),
+	})
+}
+
+// sizeQueue feeds the browser resizes to the TTY of the command
+type sizeQueue <-chan TerminalSize
+
+func (q sizeQueue) Next() *remotecommand.TerminalSize {
+	size, ok := <-q
+	if !ok {
+		return nil
+	}
+	return &remotecommand.TerminalSize{Width: size.Cols, Height: size.Rows}
+}
@@ -0,0 +1,348 @@
+package webterminal
+
+import (
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+	utils "github.com/AaronYang0628/slurm-on-k8s/internal/utils"
+)
+
+const (
+	// PathPrefix is where the terminal of a SlurmDeployment is served, followed by its namespace and name
+	PathPrefix = "/terminal/"
+
+	// Subprotocol is selected on every websocket, browsers cannot set headers on websockets so the bearer token is
+	// offered as a second subprotocol like the API server accepts it
+	Subprotocol          = "slurm.ay.dev.terminal"
+	bearerProtocolPrefix = "base64url.bearer.authorization.k8s.io."
+
+	// every client message starts with its type
+	messageStdin  = '0'
+	messageResize = '1'
+
+	loginContainer  = "login"
+	mungedContainer = "munged"
+
+	// minLoginUID is the first UID of regular accounts, the accounts below belong to the system
+	minLoginUID = 1000
+)
+
+//go:embed terminal.html
+var terminalPage []byte
+
+// unixUsername is what the login shell accepts as a user, which also keeps the name safe on the command line
+var unixUsername = regexp.MustCompile(`^[a-z_][a-z0-9_.-]*$`)
+
+// Server serves the web terminals of every SlurmDeployment with an enabled web terminal. It runs in the operator
+// so that sessions never need credentials of their own for pods/exec.
+type Server struct {
+	// Addr is the address the terminal listens on, e.g. ":8090"
+	Addr           string
+	Client         client.Client
+	Executor       TerminalExecutor
+	Authenticators AuthenticatorResolver
+
+	upgrader websocket.Upgrader
+}
+
+// NewServer creates a Server authenticating sessions with the API server or the configured OIDC provider
+func NewServer(addr string, c client.Client, executor TerminalExecutor) *Server {
+	return &Server{Addr: addr, Client: c, Executor: executor, Authenticators: NewAuthenticatorResolver(c)}
+}
+
+// Handler routes the terminal page and its websocket
+func (s *Server) Handler() http.Handler {
+	s.upgrader = websocket.Upgrader{
+		Subprotocols: []string{Subprotocol},
+		// the token is not a cookie, so a cross origin page cannot open a session on behalf of the user
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+PathPrefix+"{namespace}/{name}/{$}", s.servePage)
+	mux.HandleFunc("GET "+PathPrefix+"{namespace}/{name}/ws", s.serveTerminal)
+	return mux
+}
+
+// Start serves until the context is cancelled, every replica serves so the terminal needs no leader election
+func (s *Server) Start(ctx context.Context) error {
+	server := &http.Server{Addr: s.Addr, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", s.Addr, err)
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	log.Printf("Serving web terminals on %s", s.Addr)
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection lets the manager start the terminal on every replica
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *Server) servePage(w http.ResponseWriter, r *http.Request) {
+	if _, status, err := s.terminalRelease(r.Context(), r.PathValue("namespace"), r.PathValue("name")); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(terminalPage)
+}
+
+func (s *Server) serveTerminal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	release, status, err := s.terminalRelease(ctx, r.PathValue("namespace"), r.PathValue("name"))
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	terminal := &release.Spec.Values.SlurmLogin.WebTerminal
+
+	token := bearerToken(r)
+	if token == "" {
+		http.Error(w, "missing bearer token", http.StatusUnauthorized)
+		return
+	}
+	authenticator, err := s.Authenticators(&terminal.Authentication)
+	if err != nil {
+		log.Printf("Web terminal of %s/%s is misconfigured: %v", release.Namespace, release.Name, err)
+		http.Error(w, "web terminal authentication is misconfigured", http.StatusInternalServerError)
+		return
+	}
+	user, err := authenticator.Authenticate(ctx, token)
+	if err != nil {
+		log.Printf("Rejected web terminal session to %s/%s: %v", release.Namespace, release.Name, err)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	username, err := loginUsername(terminal, user)
+	if err != nil {
+		log.Printf("Refused web terminal session of %s to %s/%s: %v", user.Username, release.Namespace, release.Name, err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	pod, container, err := s.loginPod(ctx, release, username)
+	if err != nil {
+		log.Printf("No login pod for the web terminal of %s/%s: %v", release.Namespace, release.Name, err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader already answered the request
+		return
+	}
+	defer conn.Close()
+	log.Printf("Opened web terminal of %s to %s/%s in pod %s", username, release.Namespace, release.Name, pod.Name)
+	streamErr := s.stream(ctx, conn, pod, container, username)
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "session ended")
+	if streamErr != nil {
+		log.Printf("Web terminal of %s to %s/%s ended: %v", username, release.Namespace, release.Name, streamErr)
+		closeMessage = websocket.FormatCloseMessage(websocket.CloseInternalServerErr, truncate(streamErr.Error(), 120))
+	}
+	_ = conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(time.Second))
+}
+
+// stream pipes the websocket to a login shell of the user until either side ends
+func (s *Server) stream(ctx context.Context, conn *websocket.Conn, pod *corev1.Pod, container, username string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stdin, stdinWriter := io.Pipe()
+	resize := make(chan TerminalSize, 1)
+
+	go func() {
+		defer cancel()
+		defer close(resize)
+		defer stdinWriter.Close()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil || len(message) == 0 {
+				return
+			}
+			switch message[0] {
+			case messageStdin:
+				if _, err := stdinWriter.Write(message[1:]); err != nil {
+					return
+				}
+			case messageResize:
+				size := TerminalSize{}
+				if json.Unmarshal(message[1:], &size) != nil || size.Cols == 0 || size.Rows == 0 {
+					continue
+				}
+				// only the latest size matters
+				select {
+				case <-resize:
+				default:
+				}
+				resize <- size
+			}
+		}
+	}()
+
+	return s.Executor.Stream(ctx, pod.Namespace, pod.Name, container, loginCommand(username), TerminalStreams{
+		Stdin:  stdin,
+		Stdout: &websocketWriter{conn: conn},
+		Resize: resize,
+	})
+}
+
+// terminalRelease returns the SlurmDeployment behind a terminal path, with the HTTP status to answer otherwise
+func (s *Server) terminalRelease(ctx context.Context, namespace, name string) (*slurmv1.SlurmDeployment, int, error) {
+	release := &slurmv1.SlurmDeployment{}
+	if err := s.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, release); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, http.StatusNotFound, errors.New("web terminal not found")
+		}
+		log.Printf("Failed to get SlurmDeployment %s/%s: %v", namespace, name, err)
+		return nil, http.StatusInternalServerError, errors.New("failed to look up the web terminal")
+	}
+	if !release.Spec.Values.SlurmLogin.WebTerminal.Enabled || !release.DeletionTimestamp.IsZero() {
+		return nil, http.StatusNotFound, errors.New("web terminal not found")
+	}
+	return release, http.StatusOK, nil
+}
+
+// loginPod picks a running login pod for the user, the same user lands on the same pod while the pods do not change
+func (s *Server) loginPod(ctx context.Context, release *slurmv1.SlurmDeployment, username string) (*corev1.Pod, string, error) {
+	service := &corev1.Service{}
+	if err := s.Client.Get(ctx, types.NamespacedName{Namespace: release.Spec.Chart.Namespace, Name: utils.LoginServiceName(release)}, service); err != nil {
+		return nil, "", fmt.Errorf("failed to get the login Service: %v", err)
+	}
+	if len(service.Spec.Selector) == 0 {
+		return nil, "", errors.New("the login Service selects no pods")
+	}
+	pods := &corev1.PodList{}
+	if err := s.Client.List(ctx, pods, client.InNamespace(release.Spec.Chart.Namespace),
+		client.MatchingLabels(service.Spec.Selector)); err != nil {
+		return nil, "", fmt.Errorf("failed to list the login pods: %v", err)
+	}
+	running := []corev1.Pod{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp.IsZero() {
+			running = append(running, pod)
+		}
+	}
+	if len(running) == 0 {
+		return nil, "", errors.New("no login pod is running")
+	}
+	sort.Slice(running, func(i, j int) bool { return running[i].Name < running[j].Name })
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(username))
+	pod := &running[hash.Sum32()%uint32(len(running))]
+	return pod, loginPodContainer(pod), nil
+}
+
+// loginPodContainer returns the container running sshd, the munged sidecar is never a login shell
+func loginPodContainer(pod *corev1.Pod) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == loginContainer {
+			return container.Name
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name != mungedContainer {
+			return container.Name
+		}
+	}
+	return ""
+}
+
+// loginUsername maps an authenticated user to the user of its login shell, a user is admitted through the users
+// mapping or as a member of an allowed group, and nobody is admitted when neither is configured
+func loginUsername(terminal *slurmv1.WebTerminalSpec, user *UserInfo) (string, error) {
+	username, mapped := terminal.Users[user.Username]
+	if !mapped {
+		if !slices.ContainsFunc(user.Groups, func(group string) bool {
+			return slices.Contains(terminal.AllowedGroups, group)
+		}) {
+			return "", errors.New("not a member of an allowed group")
+		}
+		prefix := terminal.Authentication.UsernamePrefix
+		if prefix != "" && !strings.HasPrefix(user.Username, prefix) {
+			return "", fmt.Errorf("username does not start with %q", prefix)
+		}
+		username = strings.TrimPrefix(user.Username, prefix)
+	}
+	if !unixUsername.MatchString(username) || username == "root" {
+		return "", fmt.Errorf("%q is not a login user", username)
+	}
+	return username, nil
+}
+
+// loginCommand starts a login shell of the user through su, after checking with id that the user is a regular
+// account so that a name like slurm or daemon never gets a shell of a system account
+func loginCommand(username string) []string {
+	script := fmt.Sprintf(`uid=$(id -u -- "$1" 2>/dev/null) || { echo "unknown user $1"; exit 1; }
+[ "$uid" -ge %d ] || { echo "$1 is a system account"; exit 1; }
+exec su - "$1"`, minLoginUID)
+	return []string{"sh", "-c", script, "sh", username}
+}
+
+// bearerToken reads the token of a session from the Authorization header or the bearer subprotocol
+func bearerToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+	for _, protocol := range websocket.Subprotocols(r) {
+		if encoded, found := strings.CutPrefix(protocol, bearerProtocolPrefix); found {
+			if token, err := base64.RawURLEncoding.DecodeString(encoded); err == nil {
+				return string(token)
+			}
+		}
+	}
+	return ""
+}
+
+// websocketWriter sends the output of the shell as binary messages
+type websocketWriter struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (w *websocketWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.conn.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+	return value[:length]
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webterminal
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	slurmv1 "github.com/AaronYang0628/slurm-on-k8s/api/v1"
+)
+
+// fakeAuthenticator knows a fixed set of tokens
+type fakeAuthenticator map[string]*UserInfo
+
+func (a fakeAuthenticator) Authenticate(_ context.Context, token string) (*UserInfo, error) {
+	if user, found := a[token]; found {
+		return user, nil
+	}
+	return nil, errors.New("unknown token")
+}
+
+// fakeExecutor echoes stdin back to stdout and records what it was asked to run
+type fakeExecutor struct {
+	mu        sync.Mutex
+	pod       string
+	container string
+	command   []string
+	sizes     []TerminalSize
+}
+
+func (e *fakeExecutor) Stream(_ context.Context, _, podName, container string, command []string, streams TerminalStreams) error {
+	e.mu.Lock()
+	e.pod, e.container, e.command = podName, container, command
+	e.mu.Unlock()
+	go func() {
+		for size := range streams.Resize {
+			e.mu.Lock()
+			e.sizes = append(e.sizes, size)
+			e.mu.Unlock()
+		}
+	}()
+	_, err := io.Copy(streams.Stdout, streams.Stdin)
+	return err
+}
+
+func (e *fakeExecutor) recorded() (string, string, []string, []TerminalSize) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.pod, e.container, e.command, append([]TerminalSize{}, e.sizes...)
+}
+
+var _ = Describe("Web terminal server", func() {
+	var (
+		executor *fakeExecutor
+		server   *httptest.Server
+		release  *slurmv1.SlurmDeployment
+		endpoint string
+	)
+
+	newLoginPod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "slurm", Labels: map[string]string{"app": "login"}},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "munged", Image: "munged"},
+				{Name: "login", Image: "login"},
+			}},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	start := func(objects ...runtime.Object) {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(slurmv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build()
+
+		executor = &fakeExecutor{}
+		terminal := NewServer(":0", c, executor)
+		terminal.Authenticators = func(auth *slurmv1.WebTerminalAuthSpec) (Authenticator, error) {
+			return fakeAuthenticator{
+				"alice-token": {Username: "oidc:alice", Groups: []string{"hpc-users"}},
+				"bob-token":   {Username: "oidc:bob", Groups: []string{"guests"}},
+				"root-token":  {Username: "oidc:root", Groups: []string{"hpc-users"}},
+			}, nil
+		}
+		server = httptest.NewServer(terminal.Handler())
+		endpoint = "ws" + strings.TrimPrefix(server.URL, "http") + PathPrefix + "default/cluster/ws"
+	}
+
+	dial := func(token string) (*websocket.Conn, *http.Response, error) {
+		protocols := []string{Subprotocol}
+		if token != "" {
+			protocols = append(protocols, bearerProtocolPrefix+base64.RawURLEncoding.EncodeToString([]byte(token)))
+		}
+		dialer := websocket.Dialer{Subprotocols: protocols}
+		return dialer.Dial(endpoint, nil)
+	}
+
+	BeforeEach(func() {
+		release = &slurmv1.SlurmDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Spec: slurmv1.SlurmDeploymentSpec{
+				Chart: slurmv1.ChartSpec{Name: "slurm", Namespace: "slurm"},
+			},
+		}
+		release.Spec.Values.SlurmLogin.WebTerminal = slurmv1.WebTerminalSpec{
+			Enabled:        true,
+			Authentication: slurmv1.WebTerminalAuthSpec{Mode: AuthModeOIDC, UsernamePrefix: "oidc:"},
+			AllowedGroups:  []string{"hpc-users"},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("with a running login pod", func() {
+		BeforeEach(func() {
+			loginService := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-slurm-login", Namespace: "slurm"},
+				Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "login"}},
+			}
+			start(release, loginService, newLoginPod("cluster-slurm-login-0", corev1.PodRunning),
+				newLoginPod("cluster-slurm-login-1", corev1.PodPending))
+		})
+
+		It("should serve the terminal page", func() {
+			response, err := http.Get(server.URL + PathPrefix + "default/cluster/")
+			Expect(err).NotTo(HaveOccurred())
+			defer response.Body.Close()
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			body, err := io.ReadAll(response.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(ContainSubstring(Subprotocol))
+		})
+
+		It("should run a login shell of the user in the login container and stream it", func() {
+			conn, response, err := dial("alice-token")
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+			Expect(response.Header.Get("Sec-Websocket-Protocol")).To(Equal(Subprotocol))
+
+			Expect(conn.WriteMessage(websocket.TextMessage, []byte(`1{"cols":120,"rows":40}`))).To(Succeed())
+			Expect(conn.WriteMessage(websocket.BinaryMessage, []byte("0squeue\n"))).To(Succeed())
+			messageType, output, err := conn.ReadMessage()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messageType).To(Equal(websocket.BinaryMessage))
+			Expect(string(output)).To(Equal("squeue\n"))
+
+			pod, container, command, _ := executor.recorded()
+			Expect(pod).To(Equal("cluster-slurm-login-0"))
+			Expect(container).To(Equal("login"))
+			Expect(command).To(Equal(loginCommand("alice")))
+			Eventually(func() []TerminalSize {
+				_, _, _, sizes := executor.recorded()
+				return sizes
+			}).Should(ContainElement(TerminalSize{Cols: 120, Rows: 40}))
+		})
+
+		It("should accept the token in the Authorization header", func() {
+			dialer := websocket.Dialer{Subprotocols: []string{Subprotocol}}
+			conn, _, err := dialer.Dial(endpoint, http.Header{"Authorization": []string{"Bearer alice-token"}})
+			Expect(err).NotTo(HaveOccurred())
+			conn.Close()
+		})
+
+		It("should reject sessions without a valid token", func() {
+			_, response, err := dial("")
+			Expect(err).To(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusUnauthorized))
+
+			_, response, err = dial("forged-token")
+			Expect(err).To(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should refuse users outside the allowed groups and root", func() {
+			_, response, err := dial("bob-token")
+			Expect(err).To(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusForbidden))
+
+			_, response, err = dial("root-token")
+			Expect(err).To(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Describe("login users", func() {
+		It("should admit only allowed groups and mapped users", func() {
+			terminal := &release.Spec.Values.SlurmLogin.WebTerminal
+			terminal.Users = map[string]string{"carol@example.com": "carol"}
+
+			username, err := loginUsername(terminal, &UserInfo{Username: "oidc:alice", Groups: []string{"hpc-users"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(username).To(Equal("alice"))
+			username, err = loginUsername(terminal, &UserInfo{Username: "carol@example.com"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(username).To(Equal("carol"))
+			_, err = loginUsername(terminal, &UserInfo{Username: "oidc:bob", Groups: []string{"guests"}})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should admit nobody without allowed groups or users", func() {
+			terminal := &release.Spec.Values.SlurmLogin.WebTerminal
+			terminal.AllowedGroups = nil
+			_, err := loginUsername(terminal, &UserInfo{Username: "oidc:alice", Groups: []string{"hpc-users"}})
+			Expect(err).To(HaveOccurred())
+			_, err = loginUsername(terminal, &UserInfo{Username: "oidc:alice"})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should refuse mappings to root or invalid names", func() {
+			terminal := &release.Spec.Values.SlurmLogin.WebTerminal
+			terminal.Users = map[string]string{"admin": "root", "mallory": "x; rm -rf /"}
+			_, err := loginUsername(terminal, &UserInfo{Username: "admin"})
+			Expect(err).To(HaveOccurred())
+			_, err = loginUsername(terminal, &UserInfo{Username: "mallory"})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should check the UID of the user before su", func() {
+			command := loginCommand("alice")
+			Expect(command[:2]).To(Equal([]string{"sh", "-c"}))
+			Expect(command[2]).To(ContainSubstring(`id -u -- "$1"`))
+			Expect(command[2]).To(ContainSubstring(`-ge 1000`))
+			Expect(command[2]).To(HaveSuffix(`exec su - "$1"`))
+			Expect(command[3:]).To(Equal([]string{"sh", "alice"}))
+		})
+	})
+
+	It("should answer not found while the web terminal is disabled", func() {
+		release.Spec.Values.SlurmLogin.WebTerminal.Enabled = false
+		start(release)
+		_, response, err := dial("alice-token")
+		Expect(err).To(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("should answer service unavailable without a running login pod", func() {
+		start(release, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-slurm-login", Namespace: "slurm"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "login"}},
+		})
+		_, response, err := dial("alice-token")
+		Expect(err).To(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+	})
+})